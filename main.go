@@ -1,41 +1,59 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-logr/zapr"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	"github.com/rmb938/franz-schema-registry/pkg/database/migrations"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/auth"
+	srMiddleware "github.com/rmb938/franz-schema-registry/pkg/http/middleware"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers/acls"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers/config"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers/contexts"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers/mode"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers/schemas"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers/subjects"
-	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
 )
 
 func main() {
-	zc := zap.NewDevelopmentConfig()
-	// zc := zap.NewProductionConfig()
-	z, err := zc.Build()
+	cfg, err := configuration.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "error loading configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	z, err := cfg.Log.Logger()
 	if err != nil {
 		panic(fmt.Sprintf("who watches the watchmen (%v)?", err))
 	}
 	log := zapr.NewLogger(z)
 
-	db, err := gorm.Open(postgres.Open(
-		"host=localhost user=postgres password=postgres dbname=franz-schema-registry port=5432 sslmode=disable",
-	), &gorm.Config{
-		DisableNestedTransaction: true,
-	})
+	db, err := cfg.Database.Open()
 	if err != nil {
 		log.Error(err, "error opening database connection")
 		os.Exit(1)
 	}
 
+	if err := metrics.InstrumentDatabase(db); err != nil {
+		log.Error(err, "error instrumenting database")
+		os.Exit(1)
+	}
+
 	log.Info("Running database migrations")
 	if err = migrations.RunMigrations(db); err != nil {
 		log.Error(err, "error running database migrations")
@@ -46,21 +64,77 @@ func main() {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(metrics.Middleware)
 	r.Use(middleware.Logger) // TODO: convert to logr
 	r.Use(middleware.Recoverer)
 
 	// Set a timeout value on the request context (ctx), that will signal
 	// through ctx.Done() that the request has timed out and further
 	// processing should be stopped.
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(middleware.Timeout(cfg.HTTP.RequestTimeout))
 	r.Use(middleware.Heartbeat("/ping"))
 
-	r.Use(middleware.AllowContentType("application/json"))
+	// these need to be set before mounting so the sub routers use them
+	r.NotFound(routers.NotFound)
+	r.MethodNotAllowed(routers.MethodNotAllowed)
 
-	r.Mount("/schemas", schemas.NewRouter())
-	r.Mount("/subjects", subjects.NewRouter(db))
+	// prometheus asks for its own text formats so metrics are outside the content type negotiation
+	r.Handle("/metrics", metrics.Handler())
+
+	registry, err := subjects.NewRegistry(db, cfg)
+	if err != nil {
+		log.Error(err, "error setting up subjects registry")
+		os.Exit(1)
+	}
+	schemaCache, err := schemas.NewCache(cfg.Cache)
+	if err != nil {
+		log.Error(err, "error creating schemas cache")
+		os.Exit(1)
+	}
+
+	publisher := events.NewPublisher(cfg.Events)
+	// a transaction can publish an event for as long as a request can take so the outbox is read again for that long
+	subscriber := events.NewSubscriber(db, log.WithName("events"), cfg.Events, cfg.HTTP.RequestTimeout)
+	subscriber.Subscribe(registry.InvalidateCache)
+	subscriber.Subscribe(schemaCache.Invalidate)
+	go subscriber.Run(context.Background())
+
+	authenticators, err := auth.NewAuthenticators(cfg.Auth)
+	if err != nil {
+		log.Error(err, "error setting up authentication")
+		os.Exit(1)
+	}
+
+	authorizer := acl.NewAuthorizer(db, cfg.Auth.ACL)
+
+	r.Group(func(r chi.Router) {
+		r.Use(srMiddleware.ContentType)
+		if len(authenticators) > 0 {
+			r.Use(auth.Middleware(authenticators...))
+		}
+
+		r.Mount("/schemas", schemas.NewRouter(db, authorizer, schemaCache, cfg.Limits))
+		r.Mount("/subjects", subjects.NewRouter(db, authorizer, registry))
+		r.Mount("/config", config.NewRouter(db, authorizer, publisher))
+		r.Mount("/mode", mode.NewRouter(db, authorizer, publisher))
+		r.Mount("/compatibility", subjects.NewCompatibilityRouter(db, authorizer, registry))
+		r.Mount("/subjects:batch", subjects.NewBatchRouter(db, authorizer, registry))
+		r.Mount("/contexts", contexts.NewRouter(db, authorizer))
+		if authorizer.Enabled() {
+			r.Mount("/acls", acls.NewRouter(db, authorizer))
+		}
+	})
+
+	server := &http.Server{
+		Addr:         cfg.HTTP.ListenAddress,
+		Handler:      r,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}
 
-	if err := http.ListenAndServe(":9091", r); err != nil {
+	log.Info("Starting api server", "address", cfg.HTTP.ListenAddress)
+	if err := server.ListenAndServe(); err != nil {
 		log.Error(err, "error running api server")
 		os.Exit(1)
 	}
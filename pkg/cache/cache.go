@@ -0,0 +1,95 @@
+package cache
+
+import (
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
+)
+
+// Cache is a bounded least recently used cache that counts its hits and misses,
+// it is disabled when its size is zero
+type Cache[K comparable, V any] struct {
+	name string
+	lru  *lru.Cache[K, V]
+}
+
+// New creates a cache holding up to size entries, the name is used as the label of its metrics
+func New[K comparable, V any](name string, size int) (*Cache[K, V], error) {
+	c := &Cache[K, V]{name: name}
+	if size <= 0 {
+		return c, nil
+	}
+
+	var err error
+	c.lru, err = lru.NewWithEvict[K, V](size, func(key K, value V) {
+		metrics.ObserveCacheEviction(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Enabled returns if the cache stores anything
+func (c *Cache[K, V]) Enabled() bool {
+	return c.lru != nil
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	if c.lru == nil {
+		var value V
+		return value, false
+	}
+
+	value, ok := c.lru.Get(key)
+	if ok {
+		metrics.ObserveCacheLookup(c.name, metrics.CacheResultHit)
+	} else {
+		metrics.ObserveCacheLookup(c.name, metrics.CacheResultMiss)
+	}
+
+	return value, ok
+}
+
+func (c *Cache[K, V]) Add(key K, value V) {
+	if c.lru == nil {
+		return
+	}
+
+	c.lru.Add(key, value)
+}
+
+func (c *Cache[K, V]) Len() int {
+	if c.lru == nil {
+		return 0
+	}
+
+	return c.lru.Len()
+}
+
+// Remove evicts the key, it returns if the key was cached
+func (c *Cache[K, V]) Remove(key K) bool {
+	if c.lru == nil {
+		return false
+	}
+
+	return c.lru.Remove(key)
+}
+
+// RemoveFunc evicts every entry the function returns true for and returns how many were evicted
+func (c *Cache[K, V]) RemoveFunc(fn func(key K, value V) bool) int {
+	if c.lru == nil {
+		return 0
+	}
+
+	removed := 0
+	for _, key := range c.lru.Keys() {
+		// peek so checking entries doesn't change how recently they were used
+		value, ok := c.lru.Peek(key)
+		if ok && fn(key, value) && c.lru.Remove(key) {
+			removed++
+		}
+	}
+
+	return removed
+}
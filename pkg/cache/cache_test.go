@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c, err := New[int, string]("test", 2)
+	assert.NoError(t, err)
+	assert.True(t, c.Enabled())
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+
+	c.Add(1, "one")
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", value)
+
+	// 1 was used most recently so 2 is evicted
+	c.Add(2, "two")
+	c.Get(1)
+	c.Add(3, "three")
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+	_, ok = c.Get(1)
+	assert.True(t, ok)
+
+	expected := `
+# HELP franz_schema_registry_cache_evictions_total Total number of entries evicted from in memory caches because they were full or invalidated.
+# TYPE franz_schema_registry_cache_evictions_total counter
+franz_schema_registry_cache_evictions_total{cache="test"} 1
+# HELP franz_schema_registry_cache_lookups_total Total number of in memory cache lookups by cache and result, hit or miss.
+# TYPE franz_schema_registry_cache_lookups_total counter
+franz_schema_registry_cache_lookups_total{cache="test",result="hit"} 3
+franz_schema_registry_cache_lookups_total{cache="test",result="miss"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(prometheus.DefaultGatherer, strings.NewReader(expected), "franz_schema_registry_cache_evictions_total", "franz_schema_registry_cache_lookups_total"))
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c, err := New[int, string]("disabled", 0)
+	assert.NoError(t, err)
+	assert.False(t, c.Enabled())
+
+	c.Add(1, "one")
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCacheRemove(t *testing.T) {
+	c, err := New[int, string]("remove", 10)
+	assert.NoError(t, err)
+
+	for key, value := range []string{"zero", "one", "two", "three"} {
+		c.Add(key, value)
+	}
+
+	assert.True(t, c.Remove(0))
+	assert.False(t, c.Remove(0))
+	assert.Equal(t, 3, c.Len())
+
+	removed := c.RemoveFunc(func(key int, value string) bool {
+		return strings.HasPrefix(value, "t")
+	})
+	assert.Equal(t, 2, removed)
+	assert.Equal(t, 1, c.Len())
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", value)
+
+	disabled, err := New[int, string]("remove_disabled", 0)
+	assert.NoError(t, err)
+	assert.False(t, disabled.Remove(1))
+	assert.Equal(t, 0, disabled.RemoveFunc(func(key int, value string) bool { return true }))
+}
@@ -0,0 +1,583 @@
+package configuration
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// EnvPrefix is prepended to the environment variable of every option
+// i.e. the option database.dsn is read from FRANZ_SR_DATABASE_DSN
+const EnvPrefix = "FRANZ_SR_"
+
+type DatabaseDriver string
+
+const (
+	DatabaseDriverPostgres DatabaseDriver = "postgres"
+	DatabaseDriverSQLite   DatabaseDriver = "sqlite"
+)
+
+type LogFormat string
+
+const (
+	LogFormatConsole LogFormat = "console"
+	LogFormatJSON    LogFormat = "json"
+)
+
+type Configuration struct {
+	Database  DatabaseConfiguration  `yaml:"database"`
+	HTTP      HTTPConfiguration      `yaml:"http"`
+	Log       LogConfiguration       `yaml:"log"`
+	Limits    LimitsConfiguration    `yaml:"limits"`
+	Auth      AuthConfiguration      `yaml:"auth"`
+	Cache     CacheConfiguration     `yaml:"cache"`
+	Events    EventsConfiguration    `yaml:"events"`
+	SchemaIDs SchemaIDsConfiguration `yaml:"schemaIds"`
+}
+
+type DatabaseConfiguration struct {
+	Driver DatabaseDriver `yaml:"driver"`
+	DSN    string         `yaml:"dsn"`
+	// connection pool settings, zero leaves the database/sql default in place
+	MaxOpenConnections    int           `yaml:"maxOpenConnections"`
+	MaxIdleConnections    int           `yaml:"maxIdleConnections"`
+	ConnectionMaxLifetime time.Duration `yaml:"connectionMaxLifetime"`
+	ConnectionMaxIdleTime time.Duration `yaml:"connectionMaxIdleTime"`
+}
+
+type HTTPConfiguration struct {
+	ListenAddress string `yaml:"listenAddress"`
+	// RequestTimeout is how long a request can be processed for before its context is canceled
+	RequestTimeout time.Duration `yaml:"requestTimeout"`
+	// server timeouts, zero means no timeout
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`
+}
+
+type LogConfiguration struct {
+	Level  string    `yaml:"level"`
+	Format LogFormat `yaml:"format"`
+}
+
+type LimitsConfiguration struct {
+	// MaxReferenceDepth is how deep a chain of schema references can go,
+	// the longer the chain the more db queries and the longer it'll take
+	MaxReferenceDepth int `yaml:"maxReferenceDepth"`
+	// MaxReferences is the total number of schema references a schema can pull in, zero is unlimited
+	MaxReferences int `yaml:"maxReferences"`
+	// MaxSearchScan is how many schemas searching them by name parses before giving up, zero is unlimited.
+	// Searches have to parse every schema they go through so narrow ones stay fast
+	MaxSearchScan int `yaml:"maxSearchScan"`
+}
+
+// CacheConfiguration is the number of entries kept in the in memory caches, zero disables a cache.
+// Schemas never change once they're created so nothing in the caches goes stale
+type CacheConfiguration struct {
+	// ParsedSchemas caches parsed schemas along with their references for compatibility checks
+	ParsedSchemas int `yaml:"parsedSchemas"`
+	// Schemas caches the responses of looking up schemas by id
+	Schemas int `yaml:"schemas"`
+}
+
+// SchemaIDsConfiguration is how the global ids of new schemas are handed out. Replicas reserve blocks of ids so
+// registrations don't all update the same row, ids left in a block when a replica stops are never used
+type SchemaIDsConfiguration struct {
+	BlockSize int `yaml:"blockSize"`
+	// RangeStart and RangeEnd are the ids handed out, both inclusive, deployments in multiple regions
+	// give each region its own range so regions never reserve the same ids
+	RangeStart int `yaml:"rangeStart"`
+	RangeEnd   int `yaml:"rangeEnd"`
+	// ContextRanges give schema contexts their own ids, each is .context=start-end
+	ContextRanges []string `yaml:"contextRanges"`
+}
+
+// SchemaIDsRange is a range of schema ids, both inclusive
+type SchemaIDsRange struct {
+	Start int
+	End   int
+}
+
+func (r SchemaIDsRange) overlaps(other SchemaIDsRange) bool {
+	return r.Start <= other.End && other.Start <= r.End
+}
+
+// ContextIDRanges parses the context ranges into the range of each context
+func (c *SchemaIDsConfiguration) ContextIDRanges() (map[string]SchemaIDsRange, error) {
+	ranges := make(map[string]SchemaIDsRange, len(c.ContextRanges))
+	for _, contextRange := range c.ContextRanges {
+		context, rawRange, ok := strings.Cut(contextRange, "=")
+		if !ok || !strings.HasPrefix(context, ".") || len(context) == 1 || strings.Contains(context, ":") {
+			return nil, fmt.Errorf("invalid schema ids context range %s, it must be .context=start-end", contextRange)
+		}
+
+		rawStart, rawEnd, ok := strings.Cut(rawRange, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid schema ids context range %s, it must be .context=start-end", contextRange)
+		}
+
+		start, err := strconv.Atoi(rawStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of schema ids context range %s: %w", contextRange, err)
+		}
+
+		end, err := strconv.Atoi(rawEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end of schema ids context range %s: %w", contextRange, err)
+		}
+
+		if start < 1 || end > math.MaxInt32 || start > end {
+			return nil, fmt.Errorf("schema ids context range %s must be within 1 and %d and start before it ends", contextRange, math.MaxInt32)
+		}
+
+		if _, ok := ranges[context]; ok {
+			return nil, fmt.Errorf("schema ids context %s has more than one range", context)
+		}
+
+		ranges[context] = SchemaIDsRange{Start: start, End: end}
+	}
+
+	return ranges, nil
+}
+
+// EventsConfiguration is how replicas find out about changes made by each other to evict what they have cached.
+// Changes are recorded in an outbox table that every replica polls
+type EventsConfiguration struct {
+	// Notify also sends events with postgres NOTIFY so replicas find out straight away instead of on the next poll
+	Notify       bool          `yaml:"notify"`
+	PollInterval time.Duration `yaml:"pollInterval"`
+	// Retention is how long events are kept in the outbox
+	Retention time.Duration `yaml:"retention"`
+}
+
+// AuthConfiguration enables authentication when any of the authenticators are configured
+type AuthConfiguration struct {
+	Basic  BasicAuthConfiguration  `yaml:"basic"`
+	Bearer BearerAuthConfiguration `yaml:"bearer"`
+	ACL    ACLConfiguration        `yaml:"acl"`
+}
+
+type BasicAuthConfiguration struct {
+	// CredentialsFile has a username:bcrypt-hash pair on each line
+	CredentialsFile string `yaml:"credentialsFile"`
+}
+
+type BearerAuthConfiguration struct {
+	// KeysFile is a JWKS file or PEM encoded public keys that tokens are signed with
+	KeysFile string `yaml:"keysFile"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// GroupsClaim is the claim holding the groups of the principal, empty disables groups
+	GroupsClaim string `yaml:"groupsClaim"`
+}
+
+// ACLConfiguration enables authorizing requests against the ACLs stored in the database
+type ACLConfiguration struct {
+	Enabled bool `yaml:"enabled"`
+	// SuperUsers are allowed to do anything and are the only users allowed to manage ACLs
+	SuperUsers []string `yaml:"superUsers"`
+}
+
+func Default() *Configuration {
+	return &Configuration{
+		Database: DatabaseConfiguration{
+			Driver: DatabaseDriverPostgres,
+			DSN:    "host=localhost user=postgres password=postgres dbname=franz-schema-registry port=5432 sslmode=disable",
+		},
+		HTTP: HTTPConfiguration{
+			ListenAddress:  ":9091",
+			RequestTimeout: 60 * time.Second,
+		},
+		Log: LogConfiguration{
+			Level:  "debug",
+			Format: LogFormatConsole,
+		},
+		Limits: DefaultLimits(),
+		Cache: CacheConfiguration{
+			ParsedSchemas: 1000,
+			Schemas:       1000,
+		},
+		Events: EventsConfiguration{
+			Notify:       true,
+			PollInterval: 5 * time.Second,
+			Retention:    time.Hour,
+		},
+		SchemaIDs: SchemaIDsConfiguration{
+			BlockSize:  50,
+			RangeStart: 1,
+			RangeEnd:   math.MaxInt32,
+		},
+		Auth: AuthConfiguration{
+			Bearer: BearerAuthConfiguration{
+				GroupsClaim: "groups",
+			},
+		},
+	}
+}
+
+func DefaultLimits() LimitsConfiguration {
+	return LimitsConfiguration{
+		MaxReferenceDepth: 5,
+		MaxSearchScan:     10000,
+	}
+}
+
+type option struct {
+	name  string
+	usage string
+	field func(c *Configuration) any
+}
+
+var options = []option{
+	{"database.driver", "database driver, postgres or sqlite", func(c *Configuration) any { return &c.Database.Driver }},
+	{"database.dsn", "database connection string", func(c *Configuration) any { return &c.Database.DSN }},
+	{"database.max-open-connections", "maximum number of open database connections", func(c *Configuration) any { return &c.Database.MaxOpenConnections }},
+	{"database.max-idle-connections", "maximum number of idle database connections", func(c *Configuration) any { return &c.Database.MaxIdleConnections }},
+	{"database.connection-max-lifetime", "maximum amount of time a database connection may be reused", func(c *Configuration) any { return &c.Database.ConnectionMaxLifetime }},
+	{"database.connection-max-idle-time", "maximum amount of time a database connection may be idle", func(c *Configuration) any { return &c.Database.ConnectionMaxIdleTime }},
+	{"http.listen-address", "address the api server listens on", func(c *Configuration) any { return &c.HTTP.ListenAddress }},
+	{"http.request-timeout", "maximum amount of time to process a request", func(c *Configuration) any { return &c.HTTP.RequestTimeout }},
+	{"http.read-timeout", "maximum amount of time to read a request", func(c *Configuration) any { return &c.HTTP.ReadTimeout }},
+	{"http.write-timeout", "maximum amount of time to write a response", func(c *Configuration) any { return &c.HTTP.WriteTimeout }},
+	{"http.idle-timeout", "maximum amount of time to wait for the next request on a keep-alive connection", func(c *Configuration) any { return &c.HTTP.IdleTimeout }},
+	{"log.level", "log level, debug, info, warn or error", func(c *Configuration) any { return &c.Log.Level }},
+	{"log.format", "log format, console or json", func(c *Configuration) any { return &c.Log.Format }},
+	{"limits.max-reference-depth", "maximum depth of a schema reference chain", func(c *Configuration) any { return &c.Limits.MaxReferenceDepth }},
+	{"limits.max-references", "maximum number of schema references a schema can pull in, 0 is unlimited", func(c *Configuration) any { return &c.Limits.MaxReferences }},
+	{"limits.max-search-scan", "maximum number of schemas a schema search parses, 0 is unlimited", func(c *Configuration) any { return &c.Limits.MaxSearchScan }},
+	{"cache.parsed-schemas", "number of parsed schemas to cache, 0 disables the cache", func(c *Configuration) any { return &c.Cache.ParsedSchemas }},
+	{"cache.schemas", "number of schemas looked up by id to cache, 0 disables the cache", func(c *Configuration) any { return &c.Cache.Schemas }},
+	{"events.notify", "send events with postgres NOTIFY as well as the outbox", func(c *Configuration) any { return &c.Events.Notify }},
+	{"events.poll-interval", "how often the events outbox is polled", func(c *Configuration) any { return &c.Events.PollInterval }},
+	{"events.retention", "how long events are kept in the outbox", func(c *Configuration) any { return &c.Events.Retention }},
+	{"schema-ids.block-size", "number of schema ids each replica reserves at a time", func(c *Configuration) any { return &c.SchemaIDs.BlockSize }},
+	{"schema-ids.range-start", "first schema id handed out", func(c *Configuration) any { return &c.SchemaIDs.RangeStart }},
+	{"schema-ids.range-end", "last schema id handed out", func(c *Configuration) any { return &c.SchemaIDs.RangeEnd }},
+	{"schema-ids.context-ranges", "comma separated schema id ranges of contexts, i.e. .team-a=1000000-1999999", func(c *Configuration) any { return &c.SchemaIDs.ContextRanges }},
+	{"auth.basic.credentials-file", "file of username:bcrypt-hash pairs for http basic auth", func(c *Configuration) any { return &c.Auth.Basic.CredentialsFile }},
+	{"auth.bearer.keys-file", "JWKS or PEM file of the public keys bearer tokens are signed with", func(c *Configuration) any { return &c.Auth.Bearer.KeysFile }},
+	{"auth.bearer.issuer", "required issuer of bearer tokens", func(c *Configuration) any { return &c.Auth.Bearer.Issuer }},
+	{"auth.bearer.audience", "required audience of bearer tokens", func(c *Configuration) any { return &c.Auth.Bearer.Audience }},
+	{"auth.bearer.groups-claim", "bearer token claim holding the groups of the principal", func(c *Configuration) any { return &c.Auth.Bearer.GroupsClaim }},
+	{"auth.acl.enabled", "authorize requests against the ACLs stored in the database", func(c *Configuration) any { return &c.Auth.ACL.Enabled }},
+	{"auth.acl.super-users", "comma separated list of users that bypass ACLs and can manage them", func(c *Configuration) any { return &c.Auth.ACL.SuperUsers }},
+}
+
+// optionFlag keeps the value an option is given as a flag so it can be applied after the file and environment
+type optionFlag struct {
+	name   string
+	isBool bool
+	values map[string]string
+}
+
+func (f *optionFlag) String() string {
+	return ""
+}
+
+func (f *optionFlag) Set(value string) error {
+	f.values[f.name] = value
+	return nil
+}
+
+// IsBoolFlag lets bool options be given without a value the same as other bool flags
+func (f *optionFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// envName returns the environment variable of an option
+func envName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// Load builds the configuration from the defaults, the YAML file, environment variables and then flags
+// each one overriding the one before it
+func Load(args []string) (*Configuration, error) {
+	config := Default()
+
+	flags := flag.NewFlagSet("franz-schema-registry", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(envName("config")), "path to a YAML configuration file")
+
+	// flags are parsed first to find the configuration file but are applied last
+	flagValues := make(map[string]string)
+	for _, opt := range options {
+		_, isBool := opt.field(config).(*bool)
+		flags.Var(&optionFlag{name: opt.name, isBool: isBool, values: flagValues}, opt.name, fmt.Sprintf("%s (env %s, default %v)", opt.usage, envName(opt.name), defaultValue(opt)))
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// a value given to a bool flag without = ends up here instead
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %s", flags.Arg(0))
+	}
+
+	if len(*configFile) > 0 {
+		if err := config.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range options {
+		value, ok := os.LookupEnv(envName(opt.name))
+		if !ok {
+			continue
+		}
+
+		if err := setOption(config, opt, value); err != nil {
+			return nil, fmt.Errorf("error parsing environment variable %s: %w", envName(opt.name), err)
+		}
+	}
+
+	for _, opt := range options {
+		value, ok := flagValues[opt.name]
+		if !ok {
+			continue
+		}
+
+		if err := setOption(config, opt, value); err != nil {
+			return nil, fmt.Errorf("error parsing flag %s: %w", opt.name, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Configuration) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening configuration file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		// an empty file leaves the defaults as they are
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("error parsing configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func defaultValue(opt option) any {
+	return reflect.ValueOf(opt.field(Default())).Elem().Interface()
+}
+
+func setOption(config *Configuration, opt option, value string) error {
+	switch field := opt.field(config).(type) {
+	case *string:
+		*field = value
+	case *DatabaseDriver:
+		*field = DatabaseDriver(value)
+	case *LogFormat:
+		*field = LogFormat(value)
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *[]string:
+		parsed := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				parsed = append(parsed, item)
+			}
+		}
+		*field = parsed
+	case *int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	case *time.Duration:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+	default:
+		return fmt.Errorf("unsupported option type %T", field)
+	}
+
+	return nil
+}
+
+func (c *Configuration) Validate() error {
+	switch c.Database.Driver {
+	case DatabaseDriverPostgres, DatabaseDriverSQLite:
+	default:
+		return fmt.Errorf("unknown database driver: %s", c.Database.Driver)
+	}
+
+	if len(c.Database.DSN) == 0 {
+		return fmt.Errorf("database dsn must be set")
+	}
+
+	if c.Database.MaxOpenConnections < 0 || c.Database.MaxIdleConnections < 0 {
+		return fmt.Errorf("database connection pool sizes cannot be negative")
+	}
+
+	if len(c.HTTP.ListenAddress) == 0 {
+		return fmt.Errorf("http listen address must be set")
+	}
+
+	if c.HTTP.RequestTimeout <= 0 {
+		return fmt.Errorf("http request timeout must be greater than 0")
+	}
+
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+
+	switch c.Log.Format {
+	case LogFormatConsole, LogFormatJSON:
+	default:
+		return fmt.Errorf("unknown log format: %s", c.Log.Format)
+	}
+
+	if c.Limits.MaxReferenceDepth < 1 {
+		return fmt.Errorf("max reference depth must be at least 1")
+	}
+
+	if c.Limits.MaxReferences < 0 {
+		return fmt.Errorf("max references cannot be negative")
+	}
+
+	if c.Limits.MaxSearchScan < 0 {
+		return fmt.Errorf("max search scan cannot be negative")
+	}
+
+	if c.Cache.ParsedSchemas < 0 || c.Cache.Schemas < 0 {
+		return fmt.Errorf("cache sizes cannot be negative")
+	}
+
+	if c.Events.PollInterval <= 0 {
+		return fmt.Errorf("events poll interval must be greater than 0")
+	}
+
+	// events are read again for as long as a request can take so they need to be kept for longer than that
+	if c.Events.Retention <= c.HTTP.RequestTimeout {
+		return fmt.Errorf("events retention must be longer than the http request timeout")
+	}
+
+	if c.SchemaIDs.BlockSize < 1 {
+		return fmt.Errorf("schema ids block size must be at least 1")
+	}
+
+	if c.SchemaIDs.RangeStart < 1 || c.SchemaIDs.RangeEnd > math.MaxInt32 || c.SchemaIDs.RangeStart > c.SchemaIDs.RangeEnd {
+		return fmt.Errorf("schema ids range must be within 1 and %d and start before it ends", math.MaxInt32)
+	}
+
+	contextRanges, err := c.SchemaIDs.ContextIDRanges()
+	if err != nil {
+		return err
+	}
+
+	// contexts with their own range only get their own ids when nothing else hands out ids from it
+	checkedRanges := map[string]SchemaIDsRange{"the default context": {Start: c.SchemaIDs.RangeStart, End: c.SchemaIDs.RangeEnd}}
+	for context, contextRange := range contextRanges {
+		for otherContext, otherRange := range checkedRanges {
+			if contextRange.overlaps(otherRange) {
+				return fmt.Errorf("schema ids range of context %s overlaps the range of %s", context, otherContext)
+			}
+		}
+		checkedRanges[context] = contextRange
+	}
+
+	if len(c.Auth.Bearer.KeysFile) == 0 && (len(c.Auth.Bearer.Issuer) > 0 || len(c.Auth.Bearer.Audience) > 0) {
+		return fmt.Errorf("bearer auth keys file must be set when the issuer or audience is set")
+	}
+
+	if c.Auth.ACL.Enabled && len(c.Auth.Basic.CredentialsFile) == 0 && len(c.Auth.Bearer.KeysFile) == 0 {
+		return fmt.Errorf("acls require basic or bearer auth to be configured")
+	}
+
+	return nil
+}
+
+// Logger builds the zap logger, console uses the development config and json the production config
+func (c *LogConfiguration) Logger() (*zap.Logger, error) {
+	zc := zap.NewDevelopmentConfig()
+	if c.Format == LogFormatJSON {
+		zc = zap.NewProductionConfig()
+	}
+
+	level, err := zap.ParseAtomicLevel(c.Level)
+	if err != nil {
+		return nil, err
+	}
+	zc.Level = level
+
+	return zc.Build()
+}
+
+// GormConfig returns the gorm config databases are opened with, tests open theirs with it too so transactions
+// behave the same under test as they do when serving
+func GormConfig() *gorm.Config {
+	return &gorm.Config{
+		DisableNestedTransaction: true,
+	}
+}
+
+// Open opens the database with GormConfig
+func (c *DatabaseConfiguration) Open() (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch c.Driver {
+	case DatabaseDriverPostgres:
+		dialector = postgres.Open(c.DSN)
+	case DatabaseDriverSQLite:
+		dialector = sqlite.Open(c.DSN)
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", c.Driver)
+	}
+
+	db, err := gorm.Open(dialector, GormConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("error getting database connection pool: %w", err)
+	}
+
+	if c.MaxOpenConnections > 0 {
+		sqlDB.SetMaxOpenConns(c.MaxOpenConnections)
+	}
+	if c.MaxIdleConnections > 0 {
+		sqlDB.SetMaxIdleConns(c.MaxIdleConnections)
+	}
+	if c.ConnectionMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(c.ConnectionMaxLifetime)
+	}
+	if c.ConnectionMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(c.ConnectionMaxIdleTime)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,168 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), config)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte(`
+database:
+  driver: sqlite
+  dsn: file.db
+  maxOpenConnections: 10
+http:
+  listenAddress: ":8080"
+  requestTimeout: 30s
+limits:
+  maxReferenceDepth: 10
+`), 0600)
+	assert.NoError(t, err)
+
+	// file only
+	config, err := Load([]string{"-config", configFile})
+	assert.NoError(t, err)
+	assert.Equal(t, DatabaseDriverSQLite, config.Database.Driver)
+	assert.Equal(t, "file.db", config.Database.DSN)
+	assert.Equal(t, 10, config.Database.MaxOpenConnections)
+	assert.Equal(t, ":8080", config.HTTP.ListenAddress)
+	assert.Equal(t, 30*time.Second, config.HTTP.RequestTimeout)
+	assert.Equal(t, 10, config.Limits.MaxReferenceDepth)
+	// not in the file so keeps the default
+	assert.Equal(t, LogFormatConsole, config.Log.Format)
+
+	// env overrides the file
+	t.Setenv("FRANZ_SR_DATABASE_DSN", "env.db")
+	t.Setenv("FRANZ_SR_HTTP_REQUEST_TIMEOUT", "45s")
+	t.Setenv("FRANZ_SR_LOG_FORMAT", "json")
+	config, err = Load([]string{"-config", configFile})
+	assert.NoError(t, err)
+	assert.Equal(t, "env.db", config.Database.DSN)
+	assert.Equal(t, 45*time.Second, config.HTTP.RequestTimeout)
+	assert.Equal(t, LogFormatJSON, config.Log.Format)
+	assert.Equal(t, ":8080", config.HTTP.ListenAddress)
+
+	// flags override env and the file
+	config, err = Load([]string{"-config", configFile, "-database.dsn", "flag.db", "-limits.max-reference-depth", "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "flag.db", config.Database.DSN)
+	assert.Equal(t, 3, config.Limits.MaxReferenceDepth)
+	assert.Equal(t, 45*time.Second, config.HTTP.RequestTimeout)
+
+	// the file can be set from env
+	t.Setenv("FRANZ_SR_CONFIG", configFile)
+	config, err = Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", config.HTTP.ListenAddress)
+}
+
+func TestLoadACL(t *testing.T) {
+	t.Setenv("FRANZ_SR_AUTH_ACL_SUPER_USERS", "admin, other,")
+	config, err := Load([]string{"-auth.basic.credentials-file", "credentials", "-auth.acl.enabled=true"})
+	assert.NoError(t, err)
+	assert.True(t, config.Auth.ACL.Enabled)
+	assert.Equal(t, []string{"admin", "other"}, config.Auth.ACL.SuperUsers)
+	assert.Equal(t, "groups", config.Auth.Bearer.GroupsClaim)
+}
+
+func TestLoadBoolFlags(t *testing.T) {
+	// bool flags don't need a value
+	config, err := Load([]string{"-auth.acl.enabled", "-auth.basic.credentials-file", "credentials"})
+	assert.NoError(t, err)
+	assert.True(t, config.Auth.ACL.Enabled)
+
+	config, err = Load([]string{"-events.notify=false"})
+	assert.NoError(t, err)
+	assert.False(t, config.Events.Notify)
+
+	// other flags still do
+	_, err = Load([]string{"-database.dsn"})
+	assert.ErrorContains(t, err, "flag needs an argument")
+
+	// a value after a bool flag isn't its value
+	_, err = Load([]string{"-events.notify", "false"})
+	assert.ErrorContains(t, err, "unexpected argument false")
+}
+
+func TestLoadSchemaIDsContextRanges(t *testing.T) {
+	config, err := Load([]string{"-schema-ids.range-end", "999999", "-schema-ids.context-ranges", ".team-a=1000000-1999999,.team-b=2000000-2999999"})
+	assert.NoError(t, err)
+
+	ranges, err := config.SchemaIDs.ContextIDRanges()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]SchemaIDsRange{
+		".team-a": {Start: 1000000, End: 1999999},
+		".team-b": {Start: 2000000, End: 2999999},
+	}, ranges)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	// bad values
+	_, err := Load([]string{"-database.max-open-connections", "abc"})
+	assert.Error(t, err)
+
+	_, err = Load([]string{"-http.request-timeout", "10"})
+	assert.Error(t, err)
+
+	// unknown flag
+	_, err = Load([]string{"-bad"})
+	assert.Error(t, err)
+
+	// failed validation
+	_, err = Load([]string{"-database.driver", "mysql"})
+	assert.ErrorContains(t, err, "unknown database driver")
+
+	_, err = Load([]string{"-log.level", "loud"})
+	assert.ErrorContains(t, err, "invalid log level")
+
+	_, err = Load([]string{"-limits.max-reference-depth", "0"})
+	assert.ErrorContains(t, err, "max reference depth")
+
+	_, err = Load([]string{"-auth.acl.enabled"})
+	assert.ErrorContains(t, err, "acls require basic or bearer auth")
+
+	_, err = Load([]string{"-auth.acl.enabled=maybe"})
+	assert.Error(t, err)
+
+	_, err = Load([]string{"-cache.schemas", "-1"})
+	assert.ErrorContains(t, err, "cache sizes")
+
+	_, err = Load([]string{"-schema-ids.block-size", "0"})
+	assert.ErrorContains(t, err, "block size")
+
+	_, err = Load([]string{"-schema-ids.range-start", "100", "-schema-ids.range-end", "10"})
+	assert.ErrorContains(t, err, "schema ids range")
+
+	_, err = Load([]string{"-schema-ids.context-ranges", "team-a=1-10"})
+	assert.ErrorContains(t, err, "invalid schema ids context range")
+
+	_, err = Load([]string{"-schema-ids.context-ranges", ".team-a=10-1"})
+	assert.ErrorContains(t, err, "must be within")
+
+	_, err = Load([]string{"-schema-ids.range-end", "1000", "-schema-ids.context-ranges", ".team-a=1000-2000"})
+	assert.ErrorContains(t, err, "overlaps the range of the default context")
+
+	_, err = Load([]string{"-schema-ids.range-end", "1000", "-schema-ids.context-ranges", ".team-a=1001-2000,.team-b=2000-3000"})
+	assert.ErrorContains(t, err, "overlaps")
+
+	// unknown keys in the file
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, []byte("database:\n  bad: true\n"), 0600))
+	_, err = Load([]string{"-config", configFile})
+	assert.Error(t, err)
+
+	// missing file
+	_, err = Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func migration20230415100Config() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230415100_config",
+		Migrate: func(tx *gorm.DB) error {
+			type Config struct {
+				ID            uuid.UUID `gorm:"primaryKey"`
+				Subject       string    `gorm:"uniqueIndex;not null"`
+				Compatibility string    `gorm:"not null"`
+				CreatedAt     time.Time `gorm:"not null"`
+				UpdatedAt     time.Time `gorm:"not null"`
+			}
+
+			type Subject struct {
+				Compatibility string
+			}
+
+			if err := tx.Migrator().AutoMigrate(&Config{}); err != nil {
+				return err
+			}
+
+			// compatibility is now stored in configs, subjects were always created with BACKWARD
+			// which is also the default, so there is nothing to copy over
+			return tx.Migrator().DropColumn(&Subject{}, "Compatibility")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			type Subject struct {
+				Compatibility string `gorm:"not null;default:BACKWARD"`
+			}
+
+			if err := tx.Migrator().AddColumn(&Subject{}, "Compatibility"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable("configs"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
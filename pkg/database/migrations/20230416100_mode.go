@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func migration20230416100Mode() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230416100_mode",
+		Migrate: func(tx *gorm.DB) error {
+			type Mode struct {
+				ID        uuid.UUID `gorm:"primaryKey"`
+				Subject   string    `gorm:"uniqueIndex;not null"`
+				Mode      string    `gorm:"not null"`
+				CreatedAt time.Time `gorm:"not null"`
+				UpdatedAt time.Time `gorm:"not null"`
+			}
+
+			return tx.Migrator().AutoMigrate(&Mode{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("modes"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func migration20230417100ConfigNormalize() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230417100_config_normalize",
+		Migrate: func(tx *gorm.DB) error {
+			// nullable so a config can leave normalize unset and fall back to the global config
+			type Config struct {
+				Normalize *bool
+			}
+
+			return tx.Migrator().AddColumn(&Config{}, "Normalize")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			type Config struct {
+				Normalize *bool
+			}
+
+			return tx.Migrator().DropColumn(&Config{}, "Normalize")
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func migration20230418100ACLs() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230418100_acls",
+		Migrate: func(tx *gorm.DB) error {
+			type ACL struct {
+				ID            uuid.UUID `gorm:"primaryKey"`
+				PrincipalType string    `gorm:"not null;uniqueIndex:idx_acls_principal_pattern_resource_operation"`
+				PrincipalName string    `gorm:"not null;uniqueIndex:idx_acls_principal_pattern_resource_operation"`
+				PatternType   string    `gorm:"not null;uniqueIndex:idx_acls_principal_pattern_resource_operation"`
+				Resource      string    `gorm:"not null;uniqueIndex:idx_acls_principal_pattern_resource_operation"`
+				Operation     string    `gorm:"not null;uniqueIndex:idx_acls_principal_pattern_resource_operation"`
+				CreatedAt     time.Time `gorm:"not null"`
+				UpdatedAt     time.Time `gorm:"not null"`
+			}
+
+			return tx.Migrator().AutoMigrate(&ACL{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("acls"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func migration20230419100Events() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230419100_events",
+		Migrate: func(tx *gorm.DB) error {
+			type Event struct {
+				ID        uuid.UUID `gorm:"primaryKey"`
+				Payload   string    `gorm:"not null"`
+				CreatedAt time.Time `gorm:"index;not null"`
+			}
+
+			return tx.Migrator().AutoMigrate(&Event{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("events"); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+}
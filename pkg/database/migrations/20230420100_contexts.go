@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func migration20230420100Contexts() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230420100_contexts",
+		Migrate: func(tx *gorm.DB) error {
+			// existing subjects and schemas are all in the default context which is empty
+			type Subject struct {
+				Context string `gorm:"index;not null;default:''"`
+			}
+
+			// each context has its own schemas so the hash is only unique within a context
+			type Schema struct {
+				Context string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:1;not null;default:''"`
+				Hash    string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:2;not null"`
+			}
+
+			if err := tx.Migrator().AddColumn(&Subject{}, "Context"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().CreateIndex(&Subject{}, "Context"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().AddColumn(&Schema{}, "Context"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().DropIndex(&Schema{}, "idx_schemas_hash"); err != nil {
+				return err
+			}
+
+			return tx.Migrator().CreateIndex(&Schema{}, "idx_schemas_context_hash")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			type Subject struct {
+				Context string `gorm:"index;not null;default:''"`
+			}
+
+			type Schema struct {
+				Context string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:1;not null;default:''"`
+				Hash    string `gorm:"uniqueIndex;not null"`
+			}
+
+			if err := tx.Migrator().DropIndex(&Schema{}, "idx_schemas_context_hash"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().CreateIndex(&Schema{}, "Hash"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().DropColumn(&Schema{}, "Context"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().DropIndex(&Subject{}, "Context"); err != nil {
+				return err
+			}
+
+			return tx.Migrator().DropColumn(&Subject{}, "Context")
+		},
+	}
+}
@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func migration20230421100DataContracts() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "20230421100_data_contracts",
+		Migrate: func(tx *gorm.DB) error {
+			// metadata and rule sets are stored as json, existing versions and configs don't have any
+			type SubjectVersion struct {
+				Metadata string `gorm:"not null;default:''"`
+				RuleSet  string `gorm:"not null;default:''"`
+			}
+
+			type Config struct {
+				DefaultMetadata  string `gorm:"not null;default:''"`
+				OverrideMetadata string `gorm:"not null;default:''"`
+				DefaultRuleSet   string `gorm:"not null;default:''"`
+				OverrideRuleSet  string `gorm:"not null;default:''"`
+			}
+
+			// the same schema with a different data contract is a different schema, existing schemas don't have one
+			type Schema struct {
+				Context      string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:1;not null;default:''"`
+				Hash         string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:2;not null"`
+				ContractHash string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:3;not null;default:''"`
+			}
+
+			for _, column := range []string{"Metadata", "RuleSet"} {
+				if err := tx.Migrator().AddColumn(&SubjectVersion{}, column); err != nil {
+					return err
+				}
+			}
+
+			for _, column := range []string{"DefaultMetadata", "OverrideMetadata", "DefaultRuleSet", "OverrideRuleSet"} {
+				if err := tx.Migrator().AddColumn(&Config{}, column); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Migrator().AddColumn(&Schema{}, "ContractHash"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().DropIndex(&Schema{}, "idx_schemas_context_hash"); err != nil {
+				return err
+			}
+
+			return tx.Migrator().CreateIndex(&Schema{}, "idx_schemas_context_hash")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			type SubjectVersion struct {
+				Metadata string
+				RuleSet  string
+			}
+
+			type Config struct {
+				DefaultMetadata  string
+				OverrideMetadata string
+				DefaultRuleSet   string
+				OverrideRuleSet  string
+			}
+
+			type Schema struct {
+				Context      string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:1;not null;default:''"`
+				Hash         string `gorm:"uniqueIndex:idx_schemas_context_hash,priority:2;not null"`
+				ContractHash string
+			}
+
+			if err := tx.Migrator().DropIndex(&Schema{}, "idx_schemas_context_hash"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().CreateIndex(&Schema{}, "idx_schemas_context_hash"); err != nil {
+				return err
+			}
+
+			if err := tx.Migrator().DropColumn(&Schema{}, "ContractHash"); err != nil {
+				return err
+			}
+
+			for _, column := range []string{"Metadata", "RuleSet"} {
+				if err := tx.Migrator().DropColumn(&SubjectVersion{}, column); err != nil {
+					return err
+				}
+			}
+
+			for _, column := range []string{"DefaultMetadata", "OverrideMetadata", "DefaultRuleSet", "OverrideRuleSet"} {
+				if err := tx.Migrator().DropColumn(&Config{}, column); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
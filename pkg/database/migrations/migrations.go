@@ -9,6 +9,13 @@ func RunMigrations(db *gorm.DB) error {
 
 	migrations := make([]*gormigrate.Migration, 0)
 	migrations = append(migrations, migration20230325130Init())
+	migrations = append(migrations, migration20230415100Config())
+	migrations = append(migrations, migration20230416100Mode())
+	migrations = append(migrations, migration20230417100ConfigNormalize())
+	migrations = append(migrations, migration20230418100ACLs())
+	migrations = append(migrations, migration20230419100Events())
+	migrations = append(migrations, migration20230420100Contexts())
+	migrations = append(migrations, migration20230421100DataContracts())
 
 	return gormigrate.New(db, gormigrate.DefaultOptions, migrations).Migrate()
 }
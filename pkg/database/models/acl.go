@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ACLPrincipalType string
+
+const (
+	ACLPrincipalTypeUser  ACLPrincipalType = "USER"
+	ACLPrincipalTypeGroup ACLPrincipalType = "GROUP"
+)
+
+var ACLPrincipalTypes = []ACLPrincipalType{
+	ACLPrincipalTypeUser,
+	ACLPrincipalTypeGroup,
+}
+
+type ACLPatternType string
+
+const (
+	// ACLPatternTypeLiteral matches the subject with the same name as the resource
+	ACLPatternTypeLiteral ACLPatternType = "LITERAL"
+	// ACLPatternTypePrefixed matches every subject starting with the resource
+	ACLPatternTypePrefixed ACLPatternType = "PREFIXED"
+)
+
+var ACLPatternTypes = []ACLPatternType{
+	ACLPatternTypeLiteral,
+	ACLPatternTypePrefixed,
+}
+
+type ACLOperation string
+
+const (
+	ACLOperationRead   ACLOperation = "READ"
+	ACLOperationWrite  ACLOperation = "WRITE"
+	ACLOperationDelete ACLOperation = "DELETE"
+	ACLOperationConfig ACLOperation = "CONFIG"
+	ACLOperationMode   ACLOperation = "MODE"
+)
+
+var ACLOperations = []ACLOperation{
+	ACLOperationRead,
+	ACLOperationWrite,
+	ACLOperationDelete,
+	ACLOperationConfig,
+	ACLOperationMode,
+}
+
+// ACLPrincipalWildcard as the principal name matches every principal of the principal type
+const ACLPrincipalWildcard = "*"
+
+// ACL allows a principal to do an operation on the subjects matching the resource,
+// the global config and mode are the subject with an empty name
+type ACL struct {
+	ID            uuid.UUID
+	PrincipalType ACLPrincipalType
+	PrincipalName string
+	PatternType   ACLPatternType
+	Resource      string
+	Operation     ACLOperation
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Matches returns if the acl applies to the subject
+func (a *ACL) Matches(subjectName string) bool {
+	switch a.PatternType {
+	case ACLPatternTypeLiteral:
+		return a.Resource == subjectName
+	case ACLPatternTypePrefixed:
+		return strings.HasPrefix(subjectName, a.Resource)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConfigSubjectGlobal is the subject name used to store the global config
+const ConfigSubjectGlobal = ""
+
+// DefaultSubjectCompatibility is the compatibility used when neither the subject nor global config sets one
+const DefaultSubjectCompatibility = SubjectCompatibilityBackward
+
+// DefaultSubjectNormalize is if schemas are normalized when neither the subject nor global config sets it
+const DefaultSubjectNormalize = false
+
+type Config struct {
+	ID            uuid.UUID
+	Subject       string
+	Compatibility SubjectCompatibility
+	Normalize     *bool
+	// the json data contract metadata and rule sets of schemas registered to the subject, empty when unset
+	DefaultMetadata  string
+	OverrideMetadata string
+	DefaultRuleSet   string
+	OverrideRuleSet  string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// GetConfig returns the config stored for the subject, or nil if there is none
+func GetConfig(tx *gorm.DB, subjectName string) (*Config, error) {
+	config := &Config{}
+	err := tx.Clauses(ForceIndexHint("idx_configs_subject")).Where("subject = ?", subjectName).First(config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error finding config for subject %s: %w", subjectName, err)
+	}
+
+	return config, nil
+}
+
+// configSubjectNames returns the subjects whose config applies to the subject in the order they're checked,
+// the subject, the subject's context and then global
+func configSubjectNames(subjectName string) []string {
+	subjectNames := []string{subjectName}
+	if contextSubjectName := ContextSubjectName(subjectName); contextSubjectName != subjectName && contextSubjectName != ConfigSubjectGlobal {
+		subjectNames = append(subjectNames, contextSubjectName)
+	}
+	if subjectName != ConfigSubjectGlobal {
+		subjectNames = append(subjectNames, ConfigSubjectGlobal)
+	}
+
+	return subjectNames
+}
+
+// GetSubjectCompatibility returns the compatibility that applies to the subject
+// falling back to the config of its context, the global config and then to the default compatibility
+func GetSubjectCompatibility(tx *gorm.DB, subjectName string) (SubjectCompatibility, error) {
+	subjectNames := configSubjectNames(subjectName)
+
+	for _, name := range subjectNames {
+		config, err := GetConfig(tx, name)
+		if err != nil {
+			return "", err
+		}
+
+		if config != nil && len(config.Compatibility) > 0 {
+			return config.Compatibility, nil
+		}
+	}
+
+	return DefaultSubjectCompatibility, nil
+}
+
+// GetSubjectNormalize returns if schemas registered to the subject should be normalized
+// falling back to the config of its context, the global config and then to the default
+func GetSubjectNormalize(tx *gorm.DB, subjectName string) (bool, error) {
+	subjectNames := configSubjectNames(subjectName)
+
+	for _, name := range subjectNames {
+		config, err := GetConfig(tx, name)
+		if err != nil {
+			return false, err
+		}
+
+		if config != nil && config.Normalize != nil {
+			return *config.Normalize, nil
+		}
+	}
+
+	return DefaultSubjectNormalize, nil
+}
+
+// DataContract is the metadata and rule sets that config applies to schemas registered to a subject, the defaults
+// are used when a schema doesn't have its own and the overrides replace what the schema has
+type DataContract struct {
+	DefaultMetadata  *Metadata
+	OverrideMetadata *Metadata
+	DefaultRuleSet   *RuleSet
+	OverrideRuleSet  *RuleSet
+}
+
+// GetSubjectDataContract returns the data contract config that applies to the subject, each of the metadata and
+// rule sets falling back to the config of its context and then the global config
+func GetSubjectDataContract(tx *gorm.DB, subjectName string) (*DataContract, error) {
+	var defaultMetadata, overrideMetadata, defaultRuleSet, overrideRuleSet string
+
+	for _, name := range configSubjectNames(subjectName) {
+		config, err := GetConfig(tx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if config == nil {
+			continue
+		}
+
+		if len(defaultMetadata) == 0 {
+			defaultMetadata = config.DefaultMetadata
+		}
+		if len(overrideMetadata) == 0 {
+			overrideMetadata = config.OverrideMetadata
+		}
+		if len(defaultRuleSet) == 0 {
+			defaultRuleSet = config.DefaultRuleSet
+		}
+		if len(overrideRuleSet) == 0 {
+			overrideRuleSet = config.OverrideRuleSet
+		}
+	}
+
+	var err error
+	contract := &DataContract{}
+
+	if contract.DefaultMetadata, err = DecodeMetadata(defaultMetadata); err != nil {
+		return nil, err
+	}
+	if contract.OverrideMetadata, err = DecodeMetadata(overrideMetadata); err != nil {
+		return nil, err
+	}
+	if contract.DefaultRuleSet, err = DecodeRuleSet(defaultRuleSet); err != nil {
+		return nil, err
+	}
+	if contract.OverrideRuleSet, err = DecodeRuleSet(overrideRuleSet); err != nil {
+		return nil, err
+	}
+
+	return contract, nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+)
+
+// DefaultContext is the context of subjects that aren't qualified with one
+const DefaultContext = "."
+
+// ContextWildcard is the subject prefix that lists the subjects of every context
+const ContextWildcard = ":*:"
+
+// ParseSubjectName splits a subject name qualified with a context, :.context:subject, into the context and
+// the unqualified name. Subjects that aren't qualified are in the default context
+func ParseSubjectName(subjectName string) (string, string) {
+	if !strings.HasPrefix(subjectName, ":.") {
+		return DefaultContext, subjectName
+	}
+
+	end := strings.Index(subjectName[2:], ":")
+	if end < 0 {
+		return DefaultContext, subjectName
+	}
+
+	return subjectName[1 : end+2], subjectName[end+3:]
+}
+
+// QualifySubjectName returns the name of the subject in the context, subjects in the default context aren't qualified
+func QualifySubjectName(context string, subjectName string) string {
+	if context == DefaultContext || len(context) == 0 {
+		return subjectName
+	}
+
+	return ":" + context + ":" + subjectName
+}
+
+// NormalizeSubjectName removes the default context from a subject name so a subject only has one name
+func NormalizeSubjectName(subjectName string) string {
+	context, name := ParseSubjectName(subjectName)
+	return QualifySubjectName(context, name)
+}
+
+// ContextSubjectName returns the subject name the config and mode of the subject's context are stored as,
+// the default context uses the global config and mode
+func ContextSubjectName(subjectName string) string {
+	context, _ := ParseSubjectName(subjectName)
+	return QualifySubjectName(context, "")
+}
+
+// SubjectContextColumn returns what's stored in the context column of the subject, it's empty for the default context
+func SubjectContextColumn(subjectName string) string {
+	context, _ := ParseSubjectName(subjectName)
+	if context == DefaultContext {
+		return ""
+	}
+
+	return context
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// Metadata is the confluent data contract metadata of a schema, i.e. which fields are sensitive
+type Metadata struct {
+	Tags       map[string][]string `json:"tags,omitempty"`
+	Properties map[string]string   `json:"properties,omitempty"`
+	Sensitive  []string            `json:"sensitive,omitempty"`
+}
+
+type RuleKind string
+
+const (
+	RuleKindTransform RuleKind = "TRANSFORM"
+	RuleKindCondition RuleKind = "CONDITION"
+)
+
+type RuleMode string
+
+const (
+	RuleModeUpgrade   RuleMode = "UPGRADE"
+	RuleModeDowngrade RuleMode = "DOWNGRADE"
+	RuleModeUpDown    RuleMode = "UPDOWN"
+	RuleModeWrite     RuleMode = "WRITE"
+	RuleModeRead      RuleMode = "READ"
+	RuleModeWriteRead RuleMode = "WRITEREAD"
+)
+
+// migrationRuleModes are the modes of rules that migrate data between versions
+var migrationRuleModes = []RuleMode{RuleModeUpgrade, RuleModeDowngrade, RuleModeUpDown}
+
+// domainRuleModes are the modes of rules that run when data is written or read
+var domainRuleModes = []RuleMode{RuleModeWrite, RuleModeRead, RuleModeWriteRead}
+
+// Rule is a confluent data contract rule, the registry stores them for the clients that run them
+type Rule struct {
+	Name      string            `json:"name"`
+	Doc       string            `json:"doc,omitempty"`
+	Kind      RuleKind          `json:"kind"`
+	Mode      RuleMode          `json:"mode"`
+	Type      string            `json:"type"`
+	Tags      []string          `json:"tags,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+	Expr      string            `json:"expr,omitempty"`
+	OnSuccess string            `json:"onSuccess,omitempty"`
+	OnFailure string            `json:"onFailure,omitempty"`
+	Disabled  bool              `json:"disabled,omitempty"`
+}
+
+// RuleSet is the confluent data contract rules of a schema
+type RuleSet struct {
+	MigrationRules []Rule `json:"migrationRules,omitempty"`
+	DomainRules    []Rule `json:"domainRules,omitempty"`
+}
+
+func (m *Metadata) Validate() error {
+	for _, sensitive := range m.Sensitive {
+		if len(sensitive) == 0 {
+			return fmt.Errorf("sensitive fields may not be empty")
+		}
+	}
+
+	for key := range m.Properties {
+		if len(key) == 0 {
+			return fmt.Errorf("property names may not be empty")
+		}
+	}
+
+	return nil
+}
+
+func validateRules(rules []Rule, modes []RuleMode) error {
+	names := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if len(rule.Name) == 0 {
+			return fmt.Errorf("rule name may not be empty")
+		}
+
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("rule %s is defined more than once", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+
+		if rule.Kind != RuleKindTransform && rule.Kind != RuleKindCondition {
+			return fmt.Errorf("rule %s has invalid kind %s, valid values are TRANSFORM and CONDITION", rule.Name, rule.Kind)
+		}
+
+		if !slices.Contains(modes, rule.Mode) {
+			return fmt.Errorf("rule %s has invalid mode %s, valid values are %v", rule.Name, rule.Mode, modes)
+		}
+
+		if len(rule.Type) == 0 {
+			return fmt.Errorf("rule %s type may not be empty", rule.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *RuleSet) Validate() error {
+	if err := validateRules(r.MigrationRules, migrationRuleModes); err != nil {
+		return fmt.Errorf("invalid migration rule: %w", err)
+	}
+
+	if err := validateRules(r.DomainRules, domainRuleModes); err != nil {
+		return fmt.Errorf("invalid domain rule: %w", err)
+	}
+
+	return nil
+}
+
+// MergeMetadata merges the metadata in order, tags and properties of later metadata replace the ones with the same name
+// and sensitive fields are combined. It returns nil when all of them are nil
+func MergeMetadata(metadatas ...*Metadata) *Metadata {
+	var merged *Metadata
+	for _, metadata := range metadatas {
+		if metadata == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &Metadata{}
+		}
+
+		for key, tags := range metadata.Tags {
+			if merged.Tags == nil {
+				merged.Tags = make(map[string][]string)
+			}
+			merged.Tags[key] = tags
+		}
+
+		for key, value := range metadata.Properties {
+			if merged.Properties == nil {
+				merged.Properties = make(map[string]string)
+			}
+			merged.Properties[key] = value
+		}
+
+		for _, sensitive := range metadata.Sensitive {
+			if !slices.Contains(merged.Sensitive, sensitive) {
+				merged.Sensitive = append(merged.Sensitive, sensitive)
+			}
+		}
+	}
+
+	return merged
+}
+
+func mergeRules(merged []Rule, rules []Rule) []Rule {
+	for _, rule := range rules {
+		index := slices.IndexFunc(merged, func(mergedRule Rule) bool { return mergedRule.Name == rule.Name })
+		if index >= 0 {
+			merged[index] = rule
+			continue
+		}
+		merged = append(merged, rule)
+	}
+
+	return merged
+}
+
+// MergeRuleSets merges the rule sets in order, rules of later rule sets replace the ones with the same name.
+// It returns nil when all of them are nil
+func MergeRuleSets(ruleSets ...*RuleSet) *RuleSet {
+	var merged *RuleSet
+	for _, ruleSet := range ruleSets {
+		if ruleSet == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &RuleSet{}
+		}
+
+		merged.MigrationRules = mergeRules(merged.MigrationRules, ruleSet.MigrationRules)
+		merged.DomainRules = mergeRules(merged.DomainRules, ruleSet.DomainRules)
+	}
+
+	return merged
+}
+
+// EncodeMetadata returns the metadata as it's stored, nil is stored as an empty string
+func EncodeMetadata(metadata *Metadata) (string, error) {
+	if metadata == nil {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("error encoding metadata: %w", err)
+	}
+
+	return string(raw), nil
+}
+
+// DecodeMetadata returns the stored metadata, an empty string is nil
+func DecodeMetadata(raw string) (*Metadata, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	metadata := &Metadata{}
+	if err := json.Unmarshal([]byte(raw), metadata); err != nil {
+		return nil, fmt.Errorf("error decoding metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// EncodeRuleSet returns the rule set as it's stored, nil is stored as an empty string
+func EncodeRuleSet(ruleSet *RuleSet) (string, error) {
+	if ruleSet == nil {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(ruleSet)
+	if err != nil {
+		return "", fmt.Errorf("error encoding rule set: %w", err)
+	}
+
+	return string(raw), nil
+}
+
+// DecodeRuleSet returns the stored rule set, an empty string is nil
+func DecodeRuleSet(raw string) (*RuleSet, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	ruleSet := &RuleSet{}
+	if err := json.Unmarshal([]byte(raw), ruleSet); err != nil {
+		return nil, fmt.Errorf("error decoding rule set: %w", err)
+	}
+
+	return ruleSet, nil
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a change recorded in the outbox so every replica can find out about it
+type Event struct {
+	ID        uuid.UUID
+	Payload   string
+	CreatedAt time.Time
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"fmt"
+
+	"gorm.io/hints"
+)
+
+// ForceIndexHint returns a Spanner FORCE_INDEX statement hint for the given index
+func ForceIndexHint(index string) hints.Hints {
+	forceIndexHint := hints.CommentBefore("where", fmt.Sprintf("FORCE_INDEX = %s", index))
+	forceIndexHint.Prefix = "/*@ "
+	return forceIndexHint
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SubjectMode string
+
+const (
+	SubjectModeReadWrite        SubjectMode = "READWRITE"
+	SubjectModeReadOnly         SubjectMode = "READONLY"
+	SubjectModeReadOnlyOverride SubjectMode = "READONLY_OVERRIDE"
+	SubjectModeImport           SubjectMode = "IMPORT"
+)
+
+var SubjectModes = []SubjectMode{
+	SubjectModeReadWrite,
+	SubjectModeReadOnly,
+	SubjectModeReadOnlyOverride,
+	SubjectModeImport,
+}
+
+// ModeSubjectGlobal is the subject name used to store the global mode
+const ModeSubjectGlobal = ""
+
+// DefaultSubjectMode is the mode used when neither the subject nor global mode is set
+const DefaultSubjectMode = SubjectModeReadWrite
+
+type Mode struct {
+	ID        uuid.UUID
+	Subject   string
+	Mode      SubjectMode
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// GetMode returns the mode stored for the subject, or nil if there is none
+func GetMode(tx *gorm.DB, subjectName string) (*Mode, error) {
+	mode := &Mode{}
+	err := tx.Clauses(ForceIndexHint("idx_modes_subject")).Where("subject = ?", subjectName).First(mode).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error finding mode for subject %s: %w", subjectName, err)
+	}
+
+	return mode, nil
+}
+
+// GetSubjectMode returns the mode that applies to the subject
+// falling back to the mode of its context, the global mode and then to the default mode
+func GetSubjectMode(tx *gorm.DB, subjectName string) (SubjectMode, error) {
+	globalMode, err := GetMode(tx, ModeSubjectGlobal)
+	if err != nil {
+		return "", err
+	}
+
+	if subjectName != ModeSubjectGlobal {
+		// READONLY_OVERRIDE on global makes everything read only regardless of the subject mode
+		if globalMode != nil && globalMode.Mode == SubjectModeReadOnlyOverride {
+			return SubjectModeReadOnly, nil
+		}
+
+		var contextMode *Mode
+		if contextSubjectName := ContextSubjectName(subjectName); contextSubjectName != ModeSubjectGlobal && contextSubjectName != subjectName {
+			contextMode, err = GetMode(tx, contextSubjectName)
+			if err != nil {
+				return "", err
+			}
+
+			// and READONLY_OVERRIDE on a context makes every subject in it read only
+			if contextMode != nil && contextMode.Mode == SubjectModeReadOnlyOverride {
+				return SubjectModeReadOnly, nil
+			}
+		}
+
+		subjectMode, err := GetMode(tx, subjectName)
+		if err != nil {
+			return "", err
+		}
+
+		if subjectMode != nil {
+			if subjectMode.Mode == SubjectModeReadOnlyOverride {
+				return SubjectModeReadOnly, nil
+			}
+			return subjectMode.Mode, nil
+		}
+
+		if contextMode != nil {
+			return contextMode.Mode, nil
+		}
+	}
+
+	if globalMode != nil {
+		return globalMode.Mode, nil
+	}
+
+	return DefaultSubjectMode, nil
+}
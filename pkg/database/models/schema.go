@@ -17,14 +17,20 @@ const (
 
 type Schema struct {
 	gorm.Model
-	ID         uuid.UUID
-	GlobalID   int32
-	Schema     string
-	Hash       string
-	SchemaType SchemaType
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  gorm.DeletedAt
+	ID       uuid.UUID
+	GlobalID int32
+	Schema   string
+	Hash     string
+	// Context the schema is registered in, empty for the default context. Each context has its own schemas
+	// so the same schema gets its own id in every context
+	Context string
+	// ContractHash is the hash of the metadata and rule set registered with the schema, empty when it had neither.
+	// The same schema with a different data contract is a different schema
+	ContractHash string
+	SchemaType   SchemaType
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt
 }
 
 type SchemaReference struct {
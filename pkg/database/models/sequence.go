@@ -1,10 +1,12 @@
 package models
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SequenceName string
@@ -18,36 +20,98 @@ type Sequence struct {
 	NextValue int64
 }
 
-func NextSequenceID(db *gorm.DB, name SequenceName) (int64, error) {
-	nextValue := int64(0)
+// findSequence returns the sequence, locked on postgres, a sequence that doesn't exist yet is seeded with the highest
+// schema id between start and end so it never hands out ids of schemas that were created before it, i.e. imported ones
+func findSequence(tx *gorm.DB, name SequenceName, start int64, end int64) (*Sequence, error) {
+	lockTx := tx
+	if tx.Dialector.Name() == "postgres" {
+		// concurrent reservations would otherwise read the same value, spanner transactions are serializable
+		lockTx = lockTx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	sequence := &Sequence{}
+	err := lockTx.Where("name = ?", name).First(sequence).Error
+	if err == nil {
+		return sequence, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) == false {
+		return nil, err
+	}
 
+	var maxGlobalID sql.NullInt64
+	err = tx.Model(&Schema{}).Unscoped().Clauses(ForceIndexHint("idx_schemas_global_id")).
+		Where("global_id >= ? AND global_id <= ?", start, end).
+		Select("MAX(global_id)").Scan(&maxGlobalID).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding highest schema id of sequence %s: %w", name, err)
+	}
+
+	return &Sequence{Name: name, NextValue: maxGlobalID.Int64}, nil
+}
+
+// EnsureSequenceID makes sure that the sequence will never hand out the given value or anything below it,
+// start and end are the range the sequence is used with
+func EnsureSequenceID(db *gorm.DB, name SequenceName, value int64, start int64, end int64) error {
 	err := db.Transaction(func(tx *gorm.DB) error {
+		sequence, err := findSequence(tx, name, start, end)
+		if err != nil {
+			return err
+		}
+
+		if sequence.NextValue >= value {
+			return nil
+		}
+
+		sequence.NextValue = value
+		if err := tx.Save(sequence).Error; err != nil {
+			return fmt.Errorf("error saving sequence: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error running sequence transaction: %w", err)
+	}
 
-		sequence := &Sequence{}
-		err := tx.Where("name = ?", name).First(sequence).Error
+	return nil
+}
+
+// ReserveSequenceIDs reserves up to count values of the sequence that are between start and end, both inclusive,
+// and returns the first and last value reserved. A sequence used with a range should only ever be used with that range
+func ReserveSequenceIDs(db *gorm.DB, name SequenceName, count int64, start int64, end int64) (int64, int64, error) {
+	first := int64(0)
+	last := int64(0)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sequence, err := findSequence(tx, name, start, end)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) == false {
-				return err
-			}
-			sequence = nil
+			return err
+		}
+
+		if sequence.NextValue < start-1 {
+			sequence.NextValue = start - 1
+		}
+
+		if sequence.NextValue >= end {
+			return fmt.Errorf("sequence %s has run out of values, it is at %d", name, end)
 		}
 
-		if sequence == nil {
-			sequence = &Sequence{}
-			sequence.Name = name
+		first = sequence.NextValue + 1
+		last = sequence.NextValue + count
+		if last > end {
+			last = end
 		}
 
-		sequence.NextValue += 1
+		sequence.NextValue = last
 		if err := tx.Save(sequence).Error; err != nil {
 			return fmt.Errorf("error saving sequence: %w", err)
 		}
 
-		nextValue = sequence.NextValue
 		return nil
 	})
 	if err != nil {
-		return 0, fmt.Errorf("error running sequence transaction: %w", err)
+		return 0, 0, fmt.Errorf("error running sequence transaction: %w", err)
 	}
 
-	return nextValue, nil
+	return first, last, nil
 }
@@ -19,14 +19,25 @@ const (
 	SubjectCompatibilityNone               SubjectCompatibility = "NONE"
 )
 
+var SubjectCompatibilities = []SubjectCompatibility{
+	SubjectCompatibilityBackward,
+	SubjectCompatibilityBackwardTransitive,
+	SubjectCompatibilityForward,
+	SubjectCompatibilityForwardTransitive,
+	SubjectCompatibilityFull,
+	SubjectCompatibilityFullTransitive,
+	SubjectCompatibilityNone,
+}
+
 type Subject struct {
 	gorm.Model
-	ID            uuid.UUID
-	Name          string
-	Compatibility SubjectCompatibility
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     gorm.DeletedAt
+	ID   uuid.UUID
+	Name string
+	// Context the subject is in, empty for the default context
+	Context   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
 }
 
 type SubjectVersion struct {
@@ -35,6 +46,9 @@ type SubjectVersion struct {
 	SubjectID uuid.UUID
 	SchemaID  uuid.UUID
 	Version   int32
+	// Metadata and RuleSet are the json data contract of the version, empty when it has none
+	Metadata  string
+	RuleSet   string
 	CreatedAt time.Time
 	DeletedAt gorm.DeletedAt
 
@@ -0,0 +1,60 @@
+package testdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/database/migrations"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TempDatabase creates a migrated sqlite database in a temp file for tests, the caller removes the file.
+// It is opened with the same gorm config as the server uses
+func TempDatabase(t testing.TB) (*gorm.DB, string) {
+	f, err := os.CreateTemp("", "franz-go-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var db *gorm.DB
+
+	defer func() {
+		if err != nil {
+			err := os.Remove(f.Name())
+			if err != nil {
+				t.Error("db file remove error while in temp database:", err)
+			}
+		}
+	}()
+
+	db, err = gorm.Open(sqlite.Open(f.Name()), configuration.GormConfig())
+	assert.NoError(t, err)
+	assert.NoError(t, migrations.RunMigrations(db))
+
+	return db, f.Name()
+}
+
+// PostgresDSNEnv is the environment variable with the dsn of the postgres database tests that need postgres use
+const PostgresDSNEnv = "FRANZ_TEST_POSTGRES_DSN"
+
+// PostgresDatabase opens and migrates the postgres database from PostgresDSNEnv, the test is skipped when it isn't set.
+// The database is shared so tests should roll back what they write
+func PostgresDatabase(t testing.TB) *gorm.DB {
+	dsn := os.Getenv(PostgresDSNEnv)
+	if len(dsn) == 0 {
+		t.Skipf("%s is not set", PostgresDSNEnv)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), configuration.GormConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, migrations.RunMigrations(db))
+
+	return db
+}
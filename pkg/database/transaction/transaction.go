@@ -0,0 +1,76 @@
+package transaction
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxAttempts is how many times a transaction is run before its error is returned
+	maxAttempts = 5
+	// baseBackoff is how long to wait before the first retry, it doubles for each retry after that
+	baseBackoff = 10 * time.Millisecond
+	maxBackoff  = 500 * time.Millisecond
+)
+
+// Run runs fn in a transaction and runs it again in a new transaction when it fails because of a concurrent
+// transaction, fn has to reset anything it sets outside of the transaction as it can be called more than once
+func Run(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	backoff := baseBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := db.Transaction(fn)
+		if err == nil || attempt >= maxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		metrics.ObserveTransactionRetry()
+
+		// jitter so the transactions that conflicted don't conflict again
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)))
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// IsRetryable returns if the error is from a transaction conflicting with a concurrent one,
+// so running the transaction again could succeed
+func IsRetryable(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	pgErr := &pgconn.PgError{}
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		// unique_violation, serialization_failure and deadlock_detected
+		case "23505", "40001", "40P01":
+			return true
+		}
+		return false
+	}
+
+	sqliteErr := sqlite3.Error{}
+	if errors.As(err, &sqliteErr) {
+		switch {
+		case sqliteErr.Code == sqlite3.ErrBusy, sqliteErr.Code == sqlite3.ErrLocked:
+			return true
+		case sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique, sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey:
+			return true
+		}
+		return false
+	}
+
+	// spanner aborts transactions that conflict, its errors are matched by their message
+	// as spanner isn't a dependency
+	return strings.Contains(err.Error(), `code = "Aborted"`)
+}
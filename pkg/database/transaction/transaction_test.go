@@ -0,0 +1,75 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(gorm.ErrDuplicatedKey))
+	assert.True(t, IsRetryable(&pgconn.PgError{Code: "23505"}))
+	assert.True(t, IsRetryable(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, IsRetryable(&pgconn.PgError{Code: "40P01"}))
+	assert.False(t, IsRetryable(&pgconn.PgError{Code: "23503"}))
+	assert.True(t, IsRetryable(sqlite3.Error{Code: sqlite3.ErrBusy}))
+	assert.True(t, IsRetryable(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}))
+	assert.False(t, IsRetryable(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}))
+	assert.True(t, IsRetryable(fmt.Errorf(`spanner: code = "Aborted", desc = "Transaction was aborted."`)))
+	assert.False(t, IsRetryable(gorm.ErrRecordNotFound))
+
+	// wrapped errors are unwrapped
+	assert.True(t, IsRetryable(fmt.Errorf("error creating version: %w", &pgconn.PgError{Code: "23505"})))
+	assert.False(t, IsRetryable(fmt.Errorf("error creating version: %v", &pgconn.PgError{Code: "23505"})))
+}
+
+func TestRun(t *testing.T) {
+	f, err := os.CreateTemp("", "franz-go-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	db, err := gorm.Open(sqlite.Open(f.Name()), configuration.GormConfig())
+	assert.NoError(t, err)
+
+	// retried until it succeeds
+	attempts := 0
+	err = Run(db, func(tx *gorm.DB) error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// errors that aren't conflicts aren't retried
+	attempts = 0
+	notRetryable := errors.New("bad")
+	err = Run(db, func(tx *gorm.DB) error {
+		attempts++
+		return notRetryable
+	})
+	assert.ErrorIs(t, err, notRetryable)
+	assert.Equal(t, 1, attempts)
+
+	// gives up after the max attempts
+	attempts = 0
+	err = Run(db, func(tx *gorm.DB) error {
+		attempts++
+		return gorm.ErrDuplicatedKey
+	})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	assert.Equal(t, maxAttempts, attempts)
+}
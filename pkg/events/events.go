@@ -0,0 +1,79 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// NotifyChannel is the postgres channel replicas are notified on when an event is published
+const NotifyChannel = "franz_schema_registry_events"
+
+type Kind string
+
+const (
+	// KindSubjectVersion is a subject version being registered or deleted
+	KindSubjectVersion Kind = "SUBJECT_VERSION"
+	// KindSubject is a subject being deleted along with all of its versions
+	KindSubject Kind = "SUBJECT"
+	KindConfig  Kind = "CONFIG"
+	KindMode    Kind = "MODE"
+)
+
+// Event tells replicas what changed so they can evict what they have cached about it
+type Event struct {
+	Kind Kind `json:"kind"`
+	// Subject that changed, the global config and mode are the subject with an empty name
+	Subject string `json:"subject"`
+	// Deleted is if the change was a delete
+	Deleted bool `json:"deleted,omitempty"`
+	// Permanent is if the delete was permanent
+	Permanent bool `json:"permanent,omitempty"`
+	// Versions of the subject that changed
+	Versions []int32 `json:"versions,omitempty"`
+	// SchemaIDs are the ids of the schemas used by the versions that changed
+	SchemaIDs []uuid.UUID `json:"schemaIds,omitempty"`
+}
+
+// Publisher records events in the outbox
+type Publisher struct {
+	// notify is if postgres NOTIFY is used to tell replicas about events straight away
+	notify bool
+}
+
+// NewPublisher creates a publisher from the events configuration
+func NewPublisher(config configuration.EventsConfiguration) *Publisher {
+	return &Publisher{
+		notify: config.Notify,
+	}
+}
+
+// Publish records the event in the outbox as part of the transaction so it is only seen once the change commits,
+// on postgres replicas are also notified when the transaction commits
+func (p *Publisher) Publish(tx *gorm.DB, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %w", err)
+	}
+
+	outboxEvent := &dbModels.Event{
+		ID:      uuid.New(),
+		Payload: string(payload),
+	}
+	if err := tx.Create(outboxEvent).Error; err != nil {
+		return fmt.Errorf("error publishing event: %w", err)
+	}
+
+	if p.notify && tx.Dialector.Name() == "postgres" {
+		// the payload is only the id as notifications are limited in size, listeners read the outbox
+		if err := tx.Exec("SELECT pg_notify(?, ?)", NotifyChannel, outboxEvent.ID.String()).Error; err != nil {
+			return fmt.Errorf("error notifying event: %w", err)
+		}
+	}
+
+	return nil
+}
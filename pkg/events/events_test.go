@@ -0,0 +1,144 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func testSubscriber(db *gorm.DB, received *[]Event) *Subscriber {
+	subscriber := NewSubscriber(db, logr.Discard(), configuration.EventsConfiguration{
+		PollInterval: time.Second,
+		Retention:    time.Hour,
+	}, time.Minute)
+	subscriber.Subscribe(func(event Event) {
+		*received = append(*received, event)
+	})
+
+	return subscriber
+}
+
+func TestPublish(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	publisher := NewPublisher(configuration.EventsConfiguration{Notify: true})
+
+	received := make([]Event, 0)
+	subscriber := testSubscriber(db, &received)
+
+	schemaID := uuid.New()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindSubjectVersion, Subject: "one", Deleted: true, Versions: []int32{1}, SchemaIDs: []uuid.UUID{schemaID}})
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Equal(t, []Event{{Kind: KindSubjectVersion, Subject: "one", Deleted: true, Versions: []int32{1}, SchemaIDs: []uuid.UUID{schemaID}}}, received)
+
+	// events are only handled once even though they're read again
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Len(t, received, 1)
+
+	// events are handled in order
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindConfig, Subject: "two"})
+	}))
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindMode, Subject: dbModels.ConfigSubjectGlobal, Deleted: true})
+	}))
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Len(t, received, 3)
+	assert.Equal(t, Event{Kind: KindConfig, Subject: "two"}, received[1])
+	assert.Equal(t, Event{Kind: KindMode, Subject: dbModels.ConfigSubjectGlobal, Deleted: true}, received[2])
+
+	// rolled back events aren't handled
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := publisher.Publish(tx, Event{Kind: KindSubject, Subject: "three", Deleted: true}); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("rollback")
+	})
+	assert.Error(t, err)
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Len(t, received, 3)
+}
+
+func TestSubscriberIgnoresEarlierEvents(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	publisher := NewPublisher(configuration.EventsConfiguration{Notify: true})
+
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindConfig, Subject: "one"})
+	}))
+
+	// events from before the subscriber was created are outside the lookback
+	received := make([]Event, 0)
+	subscriber := NewSubscriber(db, logr.Discard(), configuration.EventsConfiguration{
+		PollInterval: time.Second,
+		Retention:    time.Hour,
+	}, 0)
+	subscriber.Subscribe(func(event Event) {
+		received = append(received, event)
+	})
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Len(t, received, 0)
+}
+
+func TestSubscriberCleanup(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	publisher := NewPublisher(configuration.EventsConfiguration{Notify: true})
+
+	received := make([]Event, 0)
+	subscriber := testSubscriber(db, &received)
+
+	assert.NoError(t, db.Create(&dbModels.Event{ID: uuid.New(), Payload: "{}", CreatedAt: time.Now().Add(-2 * time.Hour)}).Error)
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindConfig, Subject: "one"})
+	}))
+
+	// old events are only deleted once the retention has passed since the last cleanup
+	assert.NoError(t, subscriber.poll(context.Background()))
+	var count int64
+	assert.NoError(t, db.Model(&dbModels.Event{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	subscriber.lastCleanup = time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.NoError(t, db.Model(&dbModels.Event{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.Len(t, received, 1)
+}
+
+func TestSubscriberSkipsBadEvents(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	publisher := NewPublisher(configuration.EventsConfiguration{Notify: true})
+
+	received := make([]Event, 0)
+	subscriber := testSubscriber(db, &received)
+
+	assert.NoError(t, db.Create(&dbModels.Event{ID: uuid.New(), Payload: "bad"}).Error)
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return publisher.Publish(tx, Event{Kind: KindConfig, Subject: "one"})
+	}))
+
+	assert.NoError(t, subscriber.poll(context.Background()))
+	assert.Equal(t, []Event{{Kind: KindConfig, Subject: "one"}}, received)
+}
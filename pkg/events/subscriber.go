@@ -0,0 +1,188 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// Handler is called with every event published by any replica, including this one
+type Handler func(event Event)
+
+// Subscriber reads events from the outbox and hands them to the handlers.
+// The outbox is polled, on postgres a NOTIFY makes it poll straight away
+type Subscriber struct {
+	db       *gorm.DB
+	log      logr.Logger
+	config   configuration.EventsConfiguration
+	handlers []Handler
+
+	// lookback is how far before the newest event the outbox is read again, transactions can commit events with
+	// an older created at than events that are already committed so they'd be missed otherwise
+	lookback time.Duration
+	cursor   time.Time
+	// seen are the events read within the lookback so they're only handled once
+	seen map[uuid.UUID]time.Time
+	// lastCleanup is when events past the retention were last deleted
+	lastCleanup time.Time
+}
+
+// NewSubscriber creates a subscriber that only handles events published after it was created,
+// lookback should be longer than the longest transaction plus any clock difference between replicas
+func NewSubscriber(db *gorm.DB, log logr.Logger, config configuration.EventsConfiguration, lookback time.Duration) *Subscriber {
+	return &Subscriber{
+		db:          db,
+		log:         log,
+		config:      config,
+		lookback:    lookback,
+		cursor:      time.Now(),
+		seen:        make(map[uuid.UUID]time.Time),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Subscribe adds a handler, it should be called before Run
+func (s *Subscriber) Subscribe(handler Handler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+// Run handles events until the context is canceled
+func (s *Subscriber) Run(ctx context.Context) {
+	wake := make(chan struct{}, 1)
+	if s.config.Notify && s.db.Dialector.Name() == "postgres" {
+		go s.listenLoop(ctx, wake)
+	}
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error(err, "error polling events")
+		}
+	}
+}
+
+// listenLoop keeps listening for notifications, reconnecting when the connection is lost
+func (s *Subscriber) listenLoop(ctx context.Context, wake chan<- struct{}) {
+	for {
+		err := s.listen(ctx, wake)
+		if ctx.Err() != nil {
+			return
+		}
+		s.log.Error(err, "error listening for event notifications, retrying", "retryIn", s.config.PollInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+}
+
+func (s *Subscriber) listen(ctx context.Context, wake chan<- struct{}) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("error getting database connection pool: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting database connection: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("database connection %T does not support LISTEN", driverConn)
+		}
+		pgxConn := stdlibConn.Conn()
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+			return fmt.Errorf("error listening on %s: %w", NotifyChannel, err)
+		}
+
+		// anything published while we weren't listening is picked up by this poll
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+
+		for {
+			if _, err := pgxConn.WaitForNotification(ctx); err != nil {
+				return fmt.Errorf("error waiting for notification: %w", err)
+			}
+
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// poll hands the events that haven't been seen yet to the handlers in the order they were created
+func (s *Subscriber) poll(ctx context.Context) error {
+	outboxEvents := make([]dbModels.Event, 0)
+	err := s.db.WithContext(ctx).Clauses(dbModels.ForceIndexHint("idx_events_created_at")).
+		Where("created_at >= ?", s.cursor.Add(-s.lookback)).
+		Order("created_at asc").Find(&outboxEvents).Error
+	if err != nil {
+		return fmt.Errorf("error reading events: %w", err)
+	}
+
+	for _, outboxEvent := range outboxEvents {
+		if _, ok := s.seen[outboxEvent.ID]; ok {
+			continue
+		}
+		s.seen[outboxEvent.ID] = outboxEvent.CreatedAt
+
+		if outboxEvent.CreatedAt.After(s.cursor) {
+			s.cursor = outboxEvent.CreatedAt
+		}
+
+		event := Event{}
+		if err := json.Unmarshal([]byte(outboxEvent.Payload), &event); err != nil {
+			s.log.Error(err, "error decoding event, skipping it", "id", outboxEvent.ID)
+			continue
+		}
+
+		for _, handler := range s.handlers {
+			handler(event)
+		}
+	}
+
+	for id, createdAt := range s.seen {
+		if createdAt.Before(s.cursor.Add(-s.lookback)) {
+			delete(s.seen, id)
+		}
+	}
+
+	if time.Since(s.lastCleanup) >= s.config.Retention {
+		// every replica does this, deleting events twice doesn't matter
+		err := s.db.WithContext(ctx).Clauses(dbModels.ForceIndexHint("idx_events_created_at")).
+			Where("created_at < ?", time.Now().Add(-s.config.Retention)).Delete(&dbModels.Event{}).Error
+		if err != nil {
+			return fmt.Errorf("error deleting old events: %w", err)
+		}
+		s.lastCleanup = time.Now()
+	}
+
+	return nil
+}
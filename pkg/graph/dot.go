@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// dotEscaper escapes strings for double quoted graphviz ids
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// DOT returns the graph in the graphviz dot language, edges point from a version to the version it references
+func (g *Graph) DOT() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph references {\n")
+
+	for _, node := range g.Nodes {
+		label := fmt.Sprintf("%s\nversion %d\nid %d", node.Subject, node.Version, node.ID)
+		if node.Truncated {
+			// there's more past the max depth
+			label += "\n..."
+		}
+
+		attributes := []string{"label=" + dotQuote(label)}
+		if node.Direction == DirectionRoot {
+			attributes = append(attributes, "penwidth=2")
+		}
+		if node.Deleted {
+			attributes = append(attributes, "style=dashed")
+		}
+
+		fmt.Fprintf(builder, "  %s [%s];\n", dotQuote(node.Key), strings.Join(attributes, ", "))
+	}
+
+	for _, edge := range g.Edges {
+		attributes := []string{"label=" + dotQuote(edge.Name)}
+		if edge.Cycle {
+			attributes = append(attributes, "color=red")
+		}
+
+		fmt.Fprintf(builder, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attributes, ", "))
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// Respond renders the graph in the format of the options, errors and json are rendered as usual
+func Respond(w http.ResponseWriter, r *http.Request, options Options, v render.Renderer) {
+	g, ok := v.(*Graph)
+	if !ok || options.Format != FormatDOT {
+		render.Render(w, r, v)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeDOT+"; charset=utf-8")
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	w.Write([]byte(g.DOT()))
+}
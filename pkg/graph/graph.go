@@ -0,0 +1,425 @@
+package graph
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// Direction is which way references are followed from the root versions
+type Direction string
+
+const (
+	// DirectionUpstream follows the references of a version to the versions it depends on
+	DirectionUpstream Direction = "upstream"
+	// DirectionDownstream follows references back to the versions that depend on a version
+	DirectionDownstream Direction = "downstream"
+	DirectionBoth       Direction = "both"
+	// DirectionRoot is the direction of the versions the graph starts from
+	DirectionRoot Direction = "root"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatDOT  Format = "dot"
+)
+
+const ContentTypeDOT = "text/vnd.graphviz"
+
+type Options struct {
+	Direction Direction
+	// MaxDepth is how many references away from the roots versions are included
+	MaxDepth int
+	// IncludeDeleted includes soft deleted versions that depend on a version,
+	// soft deleted versions that are depended on are always included
+	IncludeDeleted bool
+	Format         Format
+}
+
+// ParseOptions reads direction, depth, deleted and format from the query, invalid values fall back to the defaults
+// and the depth can't go past the max reference depth
+func ParseOptions(request *http.Request, limits configuration.LimitsConfiguration) Options {
+	query := request.URL.Query()
+
+	options := Options{
+		Direction: DirectionBoth,
+		MaxDepth:  limits.MaxReferenceDepth,
+		Format:    FormatJSON,
+	}
+
+	switch direction := Direction(strings.ToLower(query.Get("direction"))); direction {
+	case DirectionUpstream, DirectionDownstream, DirectionBoth:
+		options.Direction = direction
+	}
+
+	if depth, err := strconv.Atoi(query.Get("depth")); err == nil && depth >= 0 && depth < options.MaxDepth {
+		options.MaxDepth = depth
+	}
+
+	options.IncludeDeleted, _ = strconv.ParseBool(query.Get("deleted"))
+
+	if Format(strings.ToLower(query.Get("format"))) == FormatDOT {
+		options.Format = FormatDOT
+	}
+
+	return options
+}
+
+type Node struct {
+	// Key identifies the node in edges and cycles
+	Key       string    `json:"key"`
+	Subject   string    `json:"subject"`
+	Version   int32     `json:"version"`
+	ID        int32     `json:"id"`
+	Direction Direction `json:"direction"`
+	Depth     int       `json:"depth"`
+	Deleted   bool      `json:"deleted,omitempty"`
+	// Truncated is set when the node has references that weren't followed because of the max depth
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Edge is a reference from the schema of a version to another version
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Name string `json:"name"`
+	// Cycle is set on the edges that lead back to a version the reference chain came from
+	Cycle bool `json:"cycle,omitempty"`
+}
+
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+	// Cycles are the keys of the nodes in each reference cycle
+	Cycles [][]string `json:"cycles,omitempty"`
+	// Hidden is the number of nodes left out because they can't be read
+	Hidden int `json:"hidden,omitempty"`
+}
+
+func (g *Graph) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func nodeKey(subjectName string, version int32) string {
+	return fmt.Sprintf("%s/versions/%d", subjectName, version)
+}
+
+type builder struct {
+	tx      *gorm.DB
+	options Options
+	graph   *Graph
+	// nodes is the index of each version in the graph nodes
+	nodes map[uuid.UUID]int
+	edges map[Edge]struct{}
+}
+
+// addNode adds the version to the graph, returning false if it's already in it
+func (b *builder) addNode(version *dbModels.SubjectVersion, direction Direction, depth int) bool {
+	if _, ok := b.nodes[version.ID]; ok {
+		return false
+	}
+
+	b.nodes[version.ID] = len(b.graph.Nodes)
+	b.graph.Nodes = append(b.graph.Nodes, Node{
+		Key:       nodeKey(version.Subject.Name, version.Version),
+		Subject:   version.Subject.Name,
+		Version:   version.Version,
+		ID:        version.Schema.GlobalID,
+		Direction: direction,
+		Depth:     depth,
+		Deleted:   version.DeletedAt.Valid,
+	})
+
+	return true
+}
+
+func (b *builder) addEdge(from uuid.UUID, to uuid.UUID, name string) {
+	edge := Edge{
+		From: b.graph.Nodes[b.nodes[from]].Key,
+		To:   b.graph.Nodes[b.nodes[to]].Key,
+		Name: name,
+	}
+	if _, ok := b.edges[edge]; ok {
+		return
+	}
+
+	b.edges[edge] = struct{}{}
+	b.graph.Edges = append(b.graph.Edges, edge)
+}
+
+// neighbour is a version referenced by or referencing another version
+type neighbour struct {
+	version dbModels.SubjectVersion
+	name    string
+}
+
+// upstream returns the versions the schema of the version references
+func (b *builder) upstream(version *dbModels.SubjectVersion) ([]neighbour, error) {
+	schemaReferences := make([]dbModels.SchemaReference, 0)
+	err := b.tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_schema_id")).
+		Joins("SubjectVersion").Joins("SubjectVersion.Subject").Joins("SubjectVersion.Schema").
+		Where("schema_references.schema_id = ?", version.SchemaID).
+		Order("schema_references.name asc").
+		Find(&schemaReferences).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding references of schema %d: %w", version.Schema.GlobalID, err)
+	}
+
+	neighbours := make([]neighbour, 0, len(schemaReferences))
+	for _, schemaReference := range schemaReferences {
+		neighbours = append(neighbours, neighbour{version: schemaReference.SubjectVersion, name: schemaReference.Name})
+	}
+
+	return neighbours, nil
+}
+
+// downstream returns the versions with schemas that reference the version
+func (b *builder) downstream(version *dbModels.SubjectVersion) ([]neighbour, error) {
+	schemaReferences := make([]dbModels.SchemaReference, 0)
+	err := b.tx.Clauses(dbModels.ForceIndexHint("idx_subject_version_id")).
+		Where("subject_version_id = ?", version.ID).
+		Find(&schemaReferences).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding references to %s version %d: %w", version.Subject.Name, version.Version, err)
+	}
+
+	if len(schemaReferences) == 0 {
+		return nil, nil
+	}
+
+	schemaIDs := make([]uuid.UUID, 0, len(schemaReferences))
+	referenceNames := make(map[uuid.UUID]string, len(schemaReferences))
+	for _, schemaReference := range schemaReferences {
+		schemaIDs = append(schemaIDs, schemaReference.SchemaID)
+		referenceNames[schemaReference.SchemaID] = schemaReference.Name
+	}
+
+	query := b.tx.Clauses(dbModels.ForceIndexHint("idx_subject_id_schema_id")).Joins("Subject").Joins("Schema").
+		Where("subject_versions.schema_id IN ?", schemaIDs)
+	if b.options.IncludeDeleted {
+		query = query.Unscoped()
+	}
+
+	subjectVersions := make([]dbModels.SubjectVersion, 0)
+	err = query.Order("\"Subject\".\"name\" asc").Order("subject_versions.version asc").Find(&subjectVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding versions referencing %s version %d: %w", version.Subject.Name, version.Version, err)
+	}
+
+	neighbours := make([]neighbour, 0, len(subjectVersions))
+	for _, subjectVersion := range subjectVersions {
+		neighbours = append(neighbours, neighbour{version: subjectVersion, name: referenceNames[subjectVersion.SchemaID]})
+	}
+
+	return neighbours, nil
+}
+
+// walk adds the versions that are up to the max depth away from the roots in the direction, a level at a time
+// so every version is at the shortest depth it can be reached at
+func (b *builder) walk(roots []dbModels.SubjectVersion, direction Direction) error {
+	frontier := roots
+	for depth := 0; len(frontier) > 0; depth++ {
+		next := make([]dbModels.SubjectVersion, 0)
+
+		for index := range frontier {
+			version := &frontier[index]
+
+			var neighbours []neighbour
+			var err error
+			if direction == DirectionUpstream {
+				neighbours, err = b.upstream(version)
+			} else {
+				neighbours, err = b.downstream(version)
+			}
+			if err != nil {
+				return err
+			}
+
+			for neighbourIndex := range neighbours {
+				neighbourVersion := &neighbours[neighbourIndex].version
+
+				if _, ok := b.nodes[neighbourVersion.ID]; !ok {
+					if depth >= b.options.MaxDepth {
+						b.graph.Nodes[b.nodes[version.ID]].Truncated = true
+						continue
+					}
+
+					b.addNode(neighbourVersion, direction, depth+1)
+					next = append(next, *neighbourVersion)
+				}
+
+				if direction == DirectionUpstream {
+					b.addEdge(version.ID, neighbourVersion.ID, neighbours[neighbourIndex].name)
+				} else {
+					b.addEdge(neighbourVersion.ID, version.ID, neighbours[neighbourIndex].name)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	return nil
+}
+
+// findCycles marks the edges that lead back to a node on the path that reached them and records each cycle
+func (b *builder) findCycles() {
+	adjacent := make(map[string][]int)
+	for index, edge := range b.graph.Edges {
+		adjacent[edge.From] = append(adjacent[edge.From], index)
+	}
+
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[string]int)
+	path := make([]string, 0)
+
+	var visit func(key string)
+	visit = func(key string) {
+		state[key] = onPath
+		path = append(path, key)
+
+		for _, edgeIndex := range adjacent[key] {
+			to := b.graph.Edges[edgeIndex].To
+			switch state[to] {
+			case unvisited:
+				visit(to)
+			case onPath:
+				b.graph.Edges[edgeIndex].Cycle = true
+				for start := range path {
+					if path[start] == to {
+						b.graph.Cycles = append(b.graph.Cycles, append([]string{}, path[start:]...))
+						break
+					}
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[key] = done
+	}
+
+	for _, node := range b.graph.Nodes {
+		if state[node.Key] == unvisited {
+			visit(node.Key)
+		}
+	}
+}
+
+// Build builds the reference graph around the subject versions with the ids, a version is only in the graph once
+// so walking stops at cycles and the depth can't go past the max reference depth
+func Build(tx *gorm.DB, roots []uuid.UUID, options Options, limits configuration.LimitsConfiguration) (*Graph, error) {
+	if options.MaxDepth > limits.MaxReferenceDepth {
+		options.MaxDepth = limits.MaxReferenceDepth
+	}
+
+	b := &builder{
+		tx:      tx,
+		options: options,
+		graph: &Graph{
+			Nodes: make([]Node, 0),
+			Edges: make([]Edge, 0),
+		},
+		nodes: make(map[uuid.UUID]int),
+		edges: make(map[Edge]struct{}),
+	}
+
+	rootVersions := make([]dbModels.SubjectVersion, 0, len(roots))
+	if len(roots) > 0 {
+		err := tx.Unscoped().Joins("Subject").Joins("Schema").Where("subject_versions.id IN ?", roots).
+			Order("\"Subject\".\"name\" asc").Order("subject_versions.version asc").Find(&rootVersions).Error
+		if err != nil {
+			return nil, fmt.Errorf("error finding graph root versions: %w", err)
+		}
+	}
+
+	for index := range rootVersions {
+		b.addNode(&rootVersions[index], DirectionRoot, 0)
+	}
+
+	if options.Direction == DirectionUpstream || options.Direction == DirectionBoth {
+		if err := b.walk(rootVersions, DirectionUpstream); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Direction == DirectionDownstream || options.Direction == DirectionBoth {
+		if err := b.walk(rootVersions, DirectionDownstream); err != nil {
+			return nil, err
+		}
+	}
+
+	b.findCycles()
+
+	return b.graph, nil
+}
+
+// Subjects returns the names of the subjects in the graph
+func (g *Graph) Subjects() []string {
+	seen := make(map[string]struct{})
+	subjectNames := make([]string, 0)
+	for _, node := range g.Nodes {
+		if _, ok := seen[node.Subject]; ok {
+			continue
+		}
+		seen[node.Subject] = struct{}{}
+		subjectNames = append(subjectNames, node.Subject)
+	}
+
+	return subjectNames
+}
+
+// Filter removes the nodes of the subjects that aren't given along with their edges and cycles
+func (g *Graph) Filter(subjectNames []string) *Graph {
+	keep := make(map[string]struct{}, len(subjectNames))
+	for _, subjectName := range subjectNames {
+		keep[subjectName] = struct{}{}
+	}
+
+	filtered := &Graph{
+		Nodes:  make([]Node, 0, len(g.Nodes)),
+		Edges:  make([]Edge, 0, len(g.Edges)),
+		Hidden: g.Hidden,
+	}
+
+	keys := make(map[string]struct{}, len(g.Nodes))
+	for _, node := range g.Nodes {
+		if _, ok := keep[node.Subject]; !ok {
+			filtered.Hidden++
+			continue
+		}
+		keys[node.Key] = struct{}{}
+		filtered.Nodes = append(filtered.Nodes, node)
+	}
+
+	for _, edge := range g.Edges {
+		_, fromOK := keys[edge.From]
+		_, toOK := keys[edge.To]
+		if fromOK && toOK {
+			filtered.Edges = append(filtered.Edges, edge)
+		}
+	}
+
+cycles:
+	for _, cycle := range g.Cycles {
+		for _, key := range cycle {
+			if _, ok := keys[key]; !ok {
+				continue cycles
+			}
+		}
+		filtered.Cycles = append(filtered.Cycles, cycle)
+	}
+
+	return filtered
+}
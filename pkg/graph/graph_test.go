@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// createVersion creates version 1 of a subject with a schema that references the versions
+func createVersion(t *testing.T, db *gorm.DB, subjectName string, globalID int32, references ...*dbModels.SubjectVersion) *dbModels.SubjectVersion {
+	subject := &dbModels.Subject{
+		ID:   uuid.New(),
+		Name: subjectName,
+	}
+	assert.NoError(t, db.Create(subject).Error)
+
+	schema := &dbModels.Schema{
+		ID:         uuid.New(),
+		GlobalID:   globalID,
+		Schema:     "", // schema and hash doesn't matter for this test
+		Hash:       subjectName,
+		SchemaType: dbModels.SchemaTypeAvro,
+	}
+	assert.NoError(t, db.Create(schema).Error)
+
+	subjectVersion := &dbModels.SubjectVersion{
+		ID:        uuid.New(),
+		SubjectID: subject.ID,
+		SchemaID:  schema.ID,
+		Version:   1,
+	}
+	assert.NoError(t, db.Create(subjectVersion).Error)
+
+	for _, reference := range references {
+		addReference(t, db, subjectVersion, reference)
+	}
+
+	return subjectVersion
+}
+
+func addReference(t *testing.T, db *gorm.DB, from *dbModels.SubjectVersion, to *dbModels.SubjectVersion) {
+	subject := &dbModels.Subject{}
+	assert.NoError(t, db.Where("id = ?", to.SubjectID).First(subject).Error)
+
+	assert.NoError(t, db.Create(&dbModels.SchemaReference{
+		ID:               uuid.New(),
+		SchemaID:         from.SchemaID,
+		SubjectVersionID: to.ID,
+		Name:             subject.Name + ".avsc",
+	}).Error)
+}
+
+func nodeKeys(g *Graph) []string {
+	keys := make([]string, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		keys = append(keys, node.Key)
+	}
+	return keys
+}
+
+func TestBuild(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// d -> c -> b -> a and e -> a
+	a := createVersion(t, db, "a", 1)
+	b := createVersion(t, db, "b", 2, a)
+	c := createVersion(t, db, "c", 3, b)
+	createVersion(t, db, "d", 4, c)
+	createVersion(t, db, "e", 5, a)
+
+	g, err := Build(db, []uuid.UUID{b.ID}, Options{Direction: DirectionBoth, MaxDepth: 5}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/versions/1", "a/versions/1", "c/versions/1", "d/versions/1"}, nodeKeys(g))
+	assert.Equal(t, Node{Key: "b/versions/1", Subject: "b", Version: 1, ID: 2, Direction: DirectionRoot}, g.Nodes[0])
+	assert.Equal(t, Node{Key: "d/versions/1", Subject: "d", Version: 1, ID: 4, Direction: DirectionDownstream, Depth: 2}, g.Nodes[3])
+	assert.Equal(t, []Edge{
+		{From: "b/versions/1", To: "a/versions/1", Name: "a.avsc"},
+		{From: "c/versions/1", To: "b/versions/1", Name: "b.avsc"},
+		{From: "d/versions/1", To: "c/versions/1", Name: "c.avsc"},
+	}, g.Edges)
+	assert.Empty(t, g.Cycles)
+
+	// only what depends on a
+	g, err = Build(db, []uuid.UUID{a.ID}, Options{Direction: DirectionDownstream, MaxDepth: 1}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/versions/1", "b/versions/1", "e/versions/1"}, nodeKeys(g))
+	assert.True(t, g.Nodes[1].Truncated)
+	assert.False(t, g.Nodes[2].Truncated)
+
+	// the depth can't go past the max reference depth
+	g, err = Build(db, []uuid.UUID{a.ID}, Options{Direction: DirectionDownstream, MaxDepth: 100}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Len(t, g.Nodes, 5)
+	g, err = Build(db, []uuid.UUID{a.ID}, Options{Direction: DirectionDownstream, MaxDepth: 100}, configuration.LimitsConfiguration{MaxReferenceDepth: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/versions/1", "b/versions/1", "e/versions/1"}, nodeKeys(g))
+
+	// deleted versions that depend on a version are left out unless asked for
+	assert.NoError(t, db.Where("schema_id = ?", c.SchemaID).Delete(&dbModels.SubjectVersion{}).Error)
+	g, err = Build(db, []uuid.UUID{b.ID}, Options{Direction: DirectionDownstream, MaxDepth: 5}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/versions/1"}, nodeKeys(g))
+	g, err = Build(db, []uuid.UUID{b.ID}, Options{Direction: DirectionDownstream, MaxDepth: 5, IncludeDeleted: true}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b/versions/1", "c/versions/1", "d/versions/1"}, nodeKeys(g))
+	assert.True(t, g.Nodes[1].Deleted)
+}
+
+func TestBuildCycle(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// registering doesn't allow cycles but imported references could have them
+	a := createVersion(t, db, "a", 1)
+	b := createVersion(t, db, "b", 2, a)
+	c := createVersion(t, db, "c", 3, b)
+	addReference(t, db, a, c)
+
+	g, err := Build(db, []uuid.UUID{c.ID}, Options{Direction: DirectionUpstream, MaxDepth: 5}, configuration.DefaultLimits())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c/versions/1", "b/versions/1", "a/versions/1"}, nodeKeys(g))
+	assert.Equal(t, []Edge{
+		{From: "c/versions/1", To: "b/versions/1", Name: "b.avsc"},
+		{From: "b/versions/1", To: "a/versions/1", Name: "a.avsc"},
+		{From: "a/versions/1", To: "c/versions/1", Name: "c.avsc", Cycle: true},
+	}, g.Edges)
+	assert.Equal(t, [][]string{{"c/versions/1", "b/versions/1", "a/versions/1"}}, g.Cycles)
+
+	assert.Contains(t, g.DOT(), `"a/versions/1" -> "c/versions/1" [label="c.avsc", color=red];`)
+
+	// hiding a node drops its edges and cycles
+	filtered := g.Filter([]string{"a", "c"})
+	assert.Equal(t, []string{"c/versions/1", "a/versions/1"}, nodeKeys(filtered))
+	assert.Len(t, filtered.Edges, 1)
+	assert.Empty(t, filtered.Cycles)
+	assert.Equal(t, 1, filtered.Hidden)
+}
+
+func TestParseOptions(t *testing.T) {
+	limits := configuration.LimitsConfiguration{MaxReferenceDepth: 3}
+
+	options := ParseOptions(httptest.NewRequest(http.MethodGet, "/", nil), limits)
+	assert.Equal(t, Options{Direction: DirectionBoth, MaxDepth: 3, Format: FormatJSON}, options)
+
+	options = ParseOptions(httptest.NewRequest(http.MethodGet, "/?direction=UPSTREAM&depth=1&deleted=true&format=dot", nil), limits)
+	assert.Equal(t, Options{Direction: DirectionUpstream, MaxDepth: 1, IncludeDeleted: true, Format: FormatDOT}, options)
+
+	// invalid values and depths past the limit are ignored
+	options = ParseOptions(httptest.NewRequest(http.MethodGet, "/?direction=sideways&depth=10&format=svg", nil), limits)
+	assert.Equal(t, Options{Direction: DirectionBoth, MaxDepth: 3, Format: FormatJSON}, options)
+}
+
+func TestRespond(t *testing.T) {
+	g := &Graph{
+		Nodes: []Node{{Key: "a\"b/versions/1", Subject: "a\"b", Version: 1, ID: 1, Direction: DirectionRoot, Deleted: true}},
+		Edges: []Edge{},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	Respond(w, req, Options{Format: FormatDOT}, g)
+	assert.Equal(t, "text/vnd.graphviz; charset=utf-8", w.Result().Header.Get("Content-Type"))
+	assert.Equal(t, "digraph references {\n  \"a\\\"b/versions/1\" [label=\"a\\\"b\\nversion 1\\nid 1\", penwidth=2, style=dashed];\n}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	Respond(w, req, Options{Format: FormatJSON}, g)
+	assert.Contains(t, w.Result().Header.Get("Content-Type"), "application/json")
+	assert.JSONEq(t, `{"nodes":[{"key":"a\"b/versions/1","subject":"a\"b","version":1,"id":1,"direction":"root","depth":0,"deleted":true}],"edges":[]}`, w.Body.String())
+}
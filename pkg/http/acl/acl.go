@@ -0,0 +1,289 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/auth"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Authorizer checks the principal of a request against the ACLs stored in the database,
+// everything is allowed when it is disabled
+type Authorizer struct {
+	db         *gorm.DB
+	enabled    bool
+	superUsers map[string]struct{}
+}
+
+func NewAuthorizer(db *gorm.DB, config configuration.ACLConfiguration) *Authorizer {
+	superUsers := make(map[string]struct{}, len(config.SuperUsers))
+	for _, superUser := range config.SuperUsers {
+		superUsers[superUser] = struct{}{}
+	}
+
+	return &Authorizer{
+		db:         db,
+		enabled:    config.Enabled,
+		superUsers: superUsers,
+	}
+}
+
+func (a *Authorizer) Enabled() bool {
+	return a.enabled
+}
+
+// IsSuperUser returns if the principal of the context is a super user
+func (a *Authorizer) IsSuperUser(ctx context.Context) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, ok = a.superUsers[principal.Name]
+	return ok
+}
+
+// principalACLs returns the acls of the operation that apply to the principal or one of its groups
+func (a *Authorizer) principalACLs(ctx context.Context, principal *auth.Principal, operation dbModels.ACLOperation) ([]dbModels.ACL, error) {
+	acls := make([]dbModels.ACL, 0)
+
+	principalTx := a.db.Where("principal_type = ? AND principal_name = ?", dbModels.ACLPrincipalTypeUser, principal.Name).
+		Or("principal_name = ?", dbModels.ACLPrincipalWildcard)
+	if len(principal.Groups) > 0 {
+		principalTx = principalTx.Or("principal_type = ? AND principal_name IN ?", dbModels.ACLPrincipalTypeGroup, principal.Groups)
+	}
+
+	err := a.db.WithContext(ctx).Where("operation = ?", operation).Where(principalTx).Find(&acls).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding acls of %s: %w", principal.Name, err)
+	}
+
+	return acls, nil
+}
+
+// allowed returns the acls that apply to the principal of the context, it returns nil acls when everything is allowed
+func (a *Authorizer) allowed(ctx context.Context, operation dbModels.ACLOperation) (*auth.Principal, []dbModels.ACL, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		// auth is always configured when acls are enabled so this shouldn't happen
+		return nil, nil, routers.NewAPIError(http.StatusForbidden, 40301, fmt.Errorf("forbidden: request is not authenticated"))
+	}
+
+	if a.IsSuperUser(ctx) {
+		return principal, nil, nil
+	}
+
+	acls, err := a.principalACLs(ctx, principal, operation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return principal, acls, nil
+}
+
+func matchesAny(acls []dbModels.ACL, subjectName string) bool {
+	for _, acl := range acls {
+		if acl.Matches(subjectName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newForbiddenError(principal *auth.Principal, operation dbModels.ACLOperation, subjectNames []string) error {
+	resource := "the global config and mode"
+	if len(subjectNames) == 1 && subjectNames[0] != dbModels.ConfigSubjectGlobal {
+		resource = fmt.Sprintf("subject %s", subjectNames[0])
+	} else if len(subjectNames) > 1 {
+		resource = "any subject of the schema"
+	}
+
+	return routers.NewAPIError(http.StatusForbidden, 40301, fmt.Errorf("forbidden: %s is not allowed to %s %s", principal.Name, operation, resource))
+}
+
+// Authorize returns a forbidden error when the principal of the context isn't allowed to do the operation on the subject,
+// the global config and mode are authorized as the subject with an empty name
+func (a *Authorizer) Authorize(ctx context.Context, operation dbModels.ACLOperation, subjectName string) error {
+	return a.AuthorizeAny(ctx, operation, []string{subjectName})
+}
+
+// AuthorizeAny returns a forbidden error when the principal of the context isn't allowed to do the operation on
+// any of the subjects, nothing is checked when there are no subjects
+func (a *Authorizer) AuthorizeAny(ctx context.Context, operation dbModels.ACLOperation, subjectNames []string) error {
+	if !a.enabled || len(subjectNames) == 0 {
+		return nil
+	}
+
+	principal, acls, err := a.allowed(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	if acls == nil {
+		return nil
+	}
+
+	for _, subjectName := range subjectNames {
+		if matchesAny(acls, subjectName) {
+			return nil
+		}
+	}
+
+	return newForbiddenError(principal, operation, subjectNames)
+}
+
+// FilterSubjects returns the subjects the principal of the context is allowed to do the operation on
+func (a *Authorizer) FilterSubjects(ctx context.Context, operation dbModels.ACLOperation, subjectNames []string) ([]string, error) {
+	if !a.enabled {
+		return subjectNames, nil
+	}
+
+	_, acls, err := a.allowed(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	if acls == nil {
+		return subjectNames, nil
+	}
+
+	filtered := make([]string, 0, len(subjectNames))
+	for _, subjectName := range subjectNames {
+		if matchesAny(acls, subjectName) {
+			filtered = append(filtered, subjectName)
+		}
+	}
+
+	return filtered, nil
+}
+
+// ScopeSubjects returns a scope only including the subjects the principal of the context is allowed to do the operation on
+// in the query, the subjects table has to be in it. Lists page in the query so every page only has allowed subjects
+func (a *Authorizer) ScopeSubjects(ctx context.Context, operation dbModels.ACLOperation) (func(tx *gorm.DB) *gorm.DB, error) {
+	if !a.enabled {
+		return func(tx *gorm.DB) *gorm.DB { return tx }, nil
+	}
+
+	_, acls, err := a.allowed(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	if acls == nil {
+		return func(tx *gorm.DB) *gorm.DB { return tx }, nil
+	}
+
+	return func(tx *gorm.DB) *gorm.DB {
+		conditions := make([]clause.Expr, 0, len(acls))
+		for _, acl := range acls {
+			switch acl.PatternType {
+			case dbModels.ACLPatternTypeLiteral:
+				conditions = append(conditions, gorm.Expr("subjects.name = ?", acl.Resource))
+			case dbModels.ACLPatternTypePrefixed:
+				conditions = append(conditions, routers.SubjectNamePrefix(tx, acl.Resource))
+			}
+		}
+
+		if len(conditions) == 0 {
+			return tx.Where("1 = 0")
+		}
+
+		allowedTx := tx.Session(&gorm.Session{NewDB: true})
+		for _, condition := range conditions {
+			allowedTx = allowedTx.Or(condition)
+		}
+
+		return tx.Where(allowedTx)
+	}, nil
+}
+
+// getSchemaSubjectNames returns the names of the subjects that have a version using the schema, including deleted ones
+func getSchemaSubjectNames(tx *gorm.DB, globalID string) ([]string, error) {
+	subjectNames := make([]string, 0)
+
+	err := tx.Model(&dbModels.Subject{}).Unscoped().
+		Joins("JOIN subject_versions ON subject_versions.subject_id = subjects.id").
+		Joins("JOIN schemas ON schemas.id = subject_versions.schema_id").
+		Where("schemas.global_id = ?", globalID).
+		Distinct().Pluck("subjects.name", &subjectNames).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding subjects of schema %s: %w", globalID, err)
+	}
+
+	return subjectNames, nil
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	var v render.Renderer = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error authorizing request: %w", err))
+	if renderer, ok := err.(render.Renderer); ok {
+		v = renderer
+	}
+
+	render.Render(w, r, v)
+}
+
+// Subject authorizes the operation on the subject url parameter, routes without the parameter are authorized
+// against the global config and mode
+func (a *Authorizer) Subject(operation dbModels.ACLOperation) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if err := a.Authorize(r.Context(), operation, routers.SubjectParam(r)); err != nil {
+				renderError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Schema authorizes the operation on the schema of the id url parameter, which is allowed when
+// the operation is allowed on any subject with a version using the schema
+func (a *Authorizer) Schema(operation dbModels.ACLOperation) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if a.enabled {
+				subjectNames, err := getSchemaSubjectNames(a.db.WithContext(r.Context()), chi.URLParam(r, "id"))
+				if err != nil {
+					renderError(w, r, err)
+					return
+				}
+
+				// unknown schemas are left to the handler to return not found
+				if err := a.AuthorizeAny(r.Context(), operation, subjectNames); err != nil {
+					renderError(w, r, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// SuperUser only allows super users through
+func (a *Authorizer) SuperUser(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if !a.IsSuperUser(r.Context()) {
+			renderError(w, r, routers.NewAPIError(http.StatusForbidden, 40301, fmt.Errorf("forbidden: only super users are allowed to manage acls")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
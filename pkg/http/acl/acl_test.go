@@ -0,0 +1,193 @@
+package acl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/auth"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createACL(t *testing.T, db *gorm.DB, principalType dbModels.ACLPrincipalType, principalName string, patternType dbModels.ACLPatternType, resource string, operation dbModels.ACLOperation) {
+	err := db.Create(&dbModels.ACL{
+		ID:            uuid.New(),
+		PrincipalType: principalType,
+		PrincipalName: principalName,
+		PatternType:   patternType,
+		Resource:      resource,
+		Operation:     operation,
+	}).Error
+	assert.NoError(t, err)
+}
+
+func principalContext(name string, groups ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Name: name, Method: auth.MethodBasic, Groups: groups})
+}
+
+func assertForbidden(t *testing.T, err error) {
+	apiError := &routers.APIError{}
+	if assert.ErrorAs(t, err, &apiError) {
+		assert.Equal(t, 40301, apiError.ErrorCode)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, "orders-value", dbModels.ACLOperationRead)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypePrefixed, "payments-", dbModels.ACLOperationWrite)
+	createACL(t, db, dbModels.ACLPrincipalTypeGroup, "developers", dbModels.ACLPatternTypePrefixed, "dev-", dbModels.ACLOperationDelete)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, dbModels.ACLPrincipalWildcard, dbModels.ACLPatternTypeLiteral, "public", dbModels.ACLOperationRead)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, dbModels.ConfigSubjectGlobal, dbModels.ACLOperationConfig)
+
+	authorizer := NewAuthorizer(db, configuration.ACLConfiguration{Enabled: true, SuperUsers: []string{"admin"}})
+
+	// literal
+	assert.NoError(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationRead, "orders-value"))
+	assertForbidden(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationRead, "orders-value-2"))
+	assertForbidden(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationWrite, "orders-value"))
+	assertForbidden(t, authorizer.Authorize(principalContext("two"), dbModels.ACLOperationRead, "orders-value"))
+
+	// prefixed
+	assert.NoError(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationWrite, "payments-value"))
+	assertForbidden(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationWrite, "other-payments-value"))
+
+	// group
+	assert.NoError(t, authorizer.Authorize(principalContext("two", "developers"), dbModels.ACLOperationDelete, "dev-value"))
+	assertForbidden(t, authorizer.Authorize(principalContext("two", "testers"), dbModels.ACLOperationDelete, "dev-value"))
+	// a user with the same name as the group doesn't match
+	assertForbidden(t, authorizer.Authorize(principalContext("developers"), dbModels.ACLOperationDelete, "dev-value"))
+
+	// wildcard
+	assert.NoError(t, authorizer.Authorize(principalContext("three"), dbModels.ACLOperationRead, "public"))
+
+	// global
+	assert.NoError(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationConfig, dbModels.ConfigSubjectGlobal))
+	assertForbidden(t, authorizer.Authorize(principalContext("one"), dbModels.ACLOperationConfig, "orders-value"))
+	err := authorizer.Authorize(principalContext("two"), dbModels.ACLOperationConfig, dbModels.ConfigSubjectGlobal)
+	assert.EqualError(t, err, "apiError: forbidden: two is not allowed to CONFIG the global config and mode")
+
+	// super users can do anything
+	assert.NoError(t, authorizer.Authorize(principalContext("admin"), dbModels.ACLOperationDelete, "orders-value"))
+
+	// not authenticated
+	assertForbidden(t, authorizer.Authorize(context.Background(), dbModels.ACLOperationRead, "public"))
+
+	// everything is allowed when disabled
+	disabled := NewAuthorizer(db, configuration.ACLConfiguration{})
+	assert.NoError(t, disabled.Authorize(context.Background(), dbModels.ACLOperationDelete, "orders-value"))
+}
+
+func TestFilterSubjects(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypePrefixed, "orders-", dbModels.ACLOperationRead)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, "payments-value", dbModels.ACLOperationWrite)
+
+	authorizer := NewAuthorizer(db, configuration.ACLConfiguration{Enabled: true, SuperUsers: []string{"admin"}})
+	subjectNames := []string{"orders-key", "orders-value", "payments-value"}
+
+	filtered, err := authorizer.FilterSubjects(principalContext("one"), dbModels.ACLOperationRead, subjectNames)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders-key", "orders-value"}, filtered)
+
+	filtered, err = authorizer.FilterSubjects(principalContext("two"), dbModels.ACLOperationRead, subjectNames)
+	assert.NoError(t, err)
+	assert.Empty(t, filtered)
+
+	filtered, err = authorizer.FilterSubjects(principalContext("admin"), dbModels.ACLOperationRead, subjectNames)
+	assert.NoError(t, err)
+	assert.Equal(t, subjectNames, filtered)
+}
+
+func TestScopeSubjects(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	subjectNames := []string{"orders-key", "orders-value", "orders_value", "payments-value", "shipments-value"}
+	for _, subjectName := range subjectNames {
+		assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: subjectName}).Error)
+	}
+
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypePrefixed, "orders-", dbModels.ACLOperationRead)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, "shipments-value", dbModels.ACLOperationRead)
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, "payments-value", dbModels.ACLOperationWrite)
+
+	authorizer := NewAuthorizer(db, configuration.ACLConfiguration{Enabled: true, SuperUsers: []string{"admin"}})
+
+	scopedNames := func(ctx context.Context) []string {
+		scope, err := authorizer.ScopeSubjects(ctx, dbModels.ACLOperationRead)
+		assert.NoError(t, err)
+
+		names := make([]string, 0)
+		assert.NoError(t, scope(db.Model(&dbModels.Subject{})).Order("name").Pluck("name", &names).Error)
+		return names
+	}
+
+	assert.Equal(t, []string{"orders-key", "orders-value", "shipments-value"}, scopedNames(principalContext("one")))
+	assert.Empty(t, scopedNames(principalContext("two")))
+	assert.Equal(t, subjectNames, scopedNames(principalContext("admin")))
+
+	_, err := authorizer.ScopeSubjects(context.Background(), dbModels.ACLOperationRead)
+	assertForbidden(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	// schema 1 is registered under two subjects
+	schema := &dbModels.Schema{ID: uuid.New(), GlobalID: 1, Schema: `"string"`, Hash: "hash", SchemaType: dbModels.SchemaTypeAvro}
+	assert.NoError(t, db.Create(schema).Error)
+	for _, subjectName := range []string{"orders-value", "payments-value"} {
+		subject := &dbModels.Subject{ID: uuid.New(), Name: subjectName}
+		assert.NoError(t, db.Create(subject).Error)
+		assert.NoError(t, db.Create(&dbModels.SubjectVersion{ID: uuid.New(), Version: 1, SubjectID: subject.ID, SchemaID: schema.ID}).Error)
+	}
+
+	createACL(t, db, dbModels.ACLPrincipalTypeUser, "one", dbModels.ACLPatternTypeLiteral, "payments-value", dbModels.ACLOperationRead)
+
+	authorizer := NewAuthorizer(db, configuration.ACLConfiguration{Enabled: true, SuperUsers: []string{"admin"}})
+
+	r := chi.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/subjects/{subject}", ok)
+	r.With(authorizer.Schema(dbModels.ACLOperationRead)).Get("/schemas/ids/{id}", ok)
+	r.With(authorizer.SuperUser).Get("/acls", ok)
+
+	tests := []struct {
+		principal string
+		path      string
+		status    int
+	}{
+		{"one", "/subjects/payments-value", http.StatusOK},
+		{"one", "/subjects/orders-value", http.StatusForbidden},
+		// readable through one of its subjects
+		{"one", "/schemas/ids/1", http.StatusOK},
+		{"two", "/schemas/ids/1", http.StatusForbidden},
+		// unknown schemas are left to the handler
+		{"two", "/schemas/ids/2", http.StatusOK},
+		{"one", "/acls", http.StatusForbidden},
+		{"admin", "/acls", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req = req.WithContext(principalContext(test.principal))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, test.status, w.Result().StatusCode, "%s %s", test.principal, test.path)
+	}
+}
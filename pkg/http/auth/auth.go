@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+)
+
+type Method string
+
+const (
+	MethodBasic  Method = "basic"
+	MethodBearer Method = "bearer"
+)
+
+// ErrNoCredentials is returned by an authenticator when the request doesn't have credentials it understands,
+// so the next authenticator can try
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is who made the request
+type Principal struct {
+	Name   string
+	Method Method
+	// Groups the principal is a member of, used when authorizing with ACLs
+	Groups []string
+}
+
+// Authenticator finds the principal of a request from its credentials
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+	// Challenge is the WWW-Authenticate header value sent when authentication fails
+	Challenge() string
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of the context holding the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of the request, it doesn't exist when authentication is disabled
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok
+}
+
+// Middleware requires every request to be authenticated by one of the authenticators
+// and puts the principal on the request context
+func Middleware(authenticators ...Authenticator) func(next http.Handler) http.Handler {
+	challenges := make([]string, 0, len(authenticators))
+	for _, authenticator := range authenticators {
+		challenges = append(challenges, authenticator.Challenge())
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(r)
+				if err != nil {
+					if errors.Is(err, ErrNoCredentials) {
+						continue
+					}
+
+					unauthorized(w, r, challenges, err)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			unauthorized(w, r, challenges, fmt.Errorf("missing credentials"))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, challenges []string, err error) {
+	w.Header().Set("WWW-Authenticate", strings.Join(challenges, ", "))
+	render.Render(w, r, routers.NewAPIError(http.StatusUnauthorized, http.StatusUnauthorized, fmt.Errorf("unauthorized: %w", err)))
+}
+
+// NewAuthenticators creates the configured authenticators, authentication is disabled when there are none
+func NewAuthenticators(config configuration.AuthConfiguration) ([]Authenticator, error) {
+	authenticators := make([]Authenticator, 0)
+
+	if len(config.Basic.CredentialsFile) > 0 {
+		basicAuthenticator, err := NewBasicAuthenticatorFromFile(config.Basic.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("error creating basic authenticator: %w", err)
+		}
+		authenticators = append(authenticators, basicAuthenticator)
+	}
+
+	if len(config.Bearer.KeysFile) > 0 {
+		bearerAuthenticator, err := NewBearerAuthenticatorFromFile(config.Bearer.KeysFile, config.Bearer.Issuer, config.Bearer.Audience, config.Bearer.GroupsClaim)
+		if err != nil {
+			return nil, fmt.Errorf("error creating bearer authenticator: %w", err)
+		}
+		authenticators = append(authenticators, bearerAuthenticator)
+	}
+
+	return authenticators, nil
+}
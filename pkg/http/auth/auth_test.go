@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeCredentialsFile(t *testing.T, credentials map[string]string) string {
+	path := filepath.Join(t.TempDir(), "credentials")
+
+	contents := "# users\n\n"
+	for username, password := range credentials {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		assert.NoError(t, err)
+		contents += fmt.Sprintf("%s:%s\n", username, hash)
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func signToken(t *testing.T, key interface{}, algorithm jwa.SignatureAlgorithm, subject string, issuer string, audience string, expiration time.Time) string {
+	token, err := jwt.NewBuilder().
+		Subject(subject).
+		Issuer(issuer).
+		Audience([]string{audience}).
+		Expiration(expiration).
+		Build()
+	assert.NoError(t, err)
+
+	signed, err := jwt.Sign(token, jwt.WithKey(algorithm, key))
+	assert.NoError(t, err)
+	return string(signed)
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	authenticator, err := NewBasicAuthenticatorFromFile(writeCredentialsFile(t, map[string]string{"one": "password1"}))
+	assert.NoError(t, err)
+
+	// no credentials
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	// correct password
+	req.SetBasicAuth("one", "password1")
+	principal, err := authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "one", Method: MethodBasic}, principal)
+
+	// wrong password
+	req.SetBasicAuth("one", "password2")
+	_, err = authenticator.Authenticate(req)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoCredentials)
+
+	// unknown user
+	req.SetBasicAuth("two", "password1")
+	_, err = authenticator.Authenticate(req)
+	assert.Error(t, err)
+
+	// bad files
+	path := filepath.Join(t.TempDir(), "credentials")
+	assert.NoError(t, os.WriteFile(path, []byte("one\n"), 0600))
+	_, err = NewBasicAuthenticatorFromFile(path)
+	assert.ErrorContains(t, err, "line 1")
+
+	assert.NoError(t, os.WriteFile(path, []byte("one:password1\n"), 0600))
+	_, err = NewBasicAuthenticatorFromFile(path)
+	assert.ErrorContains(t, err, "not a bcrypt hash")
+
+	_, err = NewBasicAuthenticatorFromFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestBasicAuthenticatorGroups(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "credentials")
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("one:%s:admins, developers\ntwo:%s\n", hash, hash)), 0600))
+
+	authenticator, err := NewBasicAuthenticatorFromFile(path)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("one", "password1")
+	principal, err := authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admins", "developers"}, principal.Groups)
+
+	req.SetBasicAuth("two", "password1")
+	principal, err = authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Empty(t, principal.Groups)
+}
+
+func TestBearerAuthenticatorJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	publicKey, err := jwk.FromRaw(rsaKey.Public())
+	assert.NoError(t, err)
+	assert.NoError(t, publicKey.Set(jwk.KeyIDKey, "one"))
+	assert.NoError(t, publicKey.Set(jwk.AlgorithmKey, jwa.RS256))
+	keySet := jwk.NewSet()
+	assert.NoError(t, keySet.AddKey(publicKey))
+	keySetJSON, err := json.Marshal(keySet)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, keySetJSON, 0600))
+
+	authenticator, err := NewBearerAuthenticatorFromFile(path, "issuer", "franz-schema-registry", "")
+	assert.NoError(t, err)
+
+	privateKey, err := jwk.FromRaw(rsaKey)
+	assert.NoError(t, err)
+	assert.NoError(t, privateKey.Set(jwk.KeyIDKey, "one"))
+
+	// no credentials
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	// other scheme
+	req.SetBasicAuth("one", "password1")
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	// valid token
+	req.Header.Set("Authorization", "Bearer "+signToken(t, privateKey, jwa.RS256, "one", "issuer", "franz-schema-registry", time.Now().Add(time.Hour)))
+	principal, err := authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "one", Method: MethodBearer}, principal)
+
+	// expired
+	req.Header.Set("Authorization", "Bearer "+signToken(t, privateKey, jwa.RS256, "one", "issuer", "franz-schema-registry", time.Now().Add(-time.Hour)))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "exp")
+
+	// never expires
+	token, err := jwt.NewBuilder().Subject("one").Issuer("issuer").Audience([]string{"franz-schema-registry"}).Build()
+	assert.NoError(t, err)
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, privateKey))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+string(signed))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "exp")
+
+	// wrong issuer
+	req.Header.Set("Authorization", "Bearer "+signToken(t, privateKey, jwa.RS256, "one", "other", "franz-schema-registry", time.Now().Add(time.Hour)))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "iss")
+
+	// wrong audience
+	req.Header.Set("Authorization", "Bearer "+signToken(t, privateKey, jwa.RS256, "one", "issuer", "other", time.Now().Add(time.Hour)))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "aud")
+
+	// signed by another key
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	otherPrivateKey, err := jwk.FromRaw(otherKey)
+	assert.NoError(t, err)
+	assert.NoError(t, otherPrivateKey.Set(jwk.KeyIDKey, "one"))
+	req.Header.Set("Authorization", "Bearer "+signToken(t, otherPrivateKey, jwa.RS256, "one", "issuer", "franz-schema-registry", time.Now().Add(time.Hour)))
+	_, err = authenticator.Authenticate(req)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNoCredentials)
+
+	// missing subject
+	req.Header.Set("Authorization", "Bearer "+signToken(t, privateKey, jwa.RS256, "", "issuer", "franz-schema-registry", time.Now().Add(time.Hour)))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "sub")
+}
+
+func TestBearerAuthenticatorPEM(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keys.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}), 0600))
+
+	// issuer and audience aren't checked when not set
+	authenticator, err := NewBearerAuthenticatorFromFile(path, "", "", "")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, ecKey, jwa.ES256, "one", "issuer", "audience", time.Now().Add(time.Hour)))
+	principal, err := authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "one", principal.Name)
+
+	// empty key sets aren't allowed
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys": []}`), 0600))
+	_, err = NewBearerAuthenticatorFromFile(path, "", "", "")
+	assert.ErrorContains(t, err, "no keys")
+}
+
+func TestBearerAuthenticatorGroups(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	publicKey, err := jwk.FromRaw(ecKey.Public())
+	assert.NoError(t, err)
+	keySet := jwk.NewSet()
+	assert.NoError(t, keySet.AddKey(publicKey))
+
+	authenticator := NewBearerAuthenticator(keySet, "", "", "groups")
+
+	signGroups := func(groups interface{}) string {
+		builder := jwt.NewBuilder().Subject("one").Expiration(time.Now().Add(time.Hour))
+		if groups != nil {
+			builder = builder.Claim("groups", groups)
+		}
+		token, err := builder.Build()
+		assert.NoError(t, err)
+
+		signed, err := jwt.Sign(token, jwt.WithKey(jwa.ES256, ecKey))
+		assert.NoError(t, err)
+		return string(signed)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	// list of groups
+	req.Header.Set("Authorization", "Bearer "+signGroups([]string{"admins", "developers"}))
+	principal, err := authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admins", "developers"}, principal.Groups)
+
+	// single group
+	req.Header.Set("Authorization", "Bearer "+signGroups("admins"))
+	principal, err = authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admins"}, principal.Groups)
+
+	// no groups
+	req.Header.Set("Authorization", "Bearer "+signGroups(nil))
+	principal, err = authenticator.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Empty(t, principal.Groups)
+
+	// not strings
+	req.Header.Set("Authorization", "Bearer "+signGroups([]int{1}))
+	_, err = authenticator.Authenticate(req)
+	assert.ErrorContains(t, err, "groups claim")
+}
+
+func TestMiddleware(t *testing.T) {
+	basicAuthenticator, err := NewBasicAuthenticatorFromFile(writeCredentialsFile(t, map[string]string{"one": "password1"}))
+	assert.NoError(t, err)
+
+	var principal *Principal
+	handler := Middleware(basicAuthenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+	}))
+
+	// no credentials
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	assert.Equal(t, `Basic realm="franz-schema-registry"`, w.Result().Header.Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"error_code": 401, "message": "unauthorized: missing credentials"}`, w.Body.String())
+	assert.Nil(t, principal)
+
+	// bad credentials
+	req.SetBasicAuth("one", "password2")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	assert.Nil(t, principal)
+
+	// good credentials
+	req.SetBasicAuth("one", "password1")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "one", principal.Name)
+}
+
+func TestNewAuthenticators(t *testing.T) {
+	// disabled by default
+	authenticators, err := NewAuthenticators(configuration.AuthConfiguration{})
+	assert.NoError(t, err)
+	assert.Empty(t, authenticators)
+
+	authenticators, err = NewAuthenticators(configuration.AuthConfiguration{
+		Basic: configuration.BasicAuthConfiguration{CredentialsFile: writeCredentialsFile(t, map[string]string{"one": "password1"})},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, authenticators, 1)
+
+	_, err = NewAuthenticators(configuration.AuthConfiguration{
+		Bearer: configuration.BearerAuthConfiguration{KeysFile: filepath.Join(t.TempDir(), "missing")},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the user doesn't exist so unknown users take as long as known ones
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("franz-schema-registry"), bcrypt.DefaultCost)
+
+// BasicAuthenticator authenticates http basic auth against bcrypt hashed passwords
+type BasicAuthenticator struct {
+	credentials map[string][]byte
+	groups      map[string][]string
+}
+
+func NewBasicAuthenticator(credentials map[string][]byte, groups map[string][]string) *BasicAuthenticator {
+	return &BasicAuthenticator{credentials: credentials, groups: groups}
+}
+
+// NewBasicAuthenticatorFromFile reads credentials from a file with a username:bcrypt-hash pair on each line,
+// the same format as an htpasswd file created with `htpasswd -B`. Empty lines and lines starting with # are ignored.
+// A comma separated list of groups can follow the hash, i.e. username:bcrypt-hash:group1,group2
+func NewBasicAuthenticatorFromFile(path string) (*BasicAuthenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening credentials file: %w", err)
+	}
+	defer file.Close()
+
+	credentials := make(map[string][]byte)
+	groups := make(map[string][]string)
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		username, hash, ok := strings.Cut(line, ":")
+		if !ok || len(username) == 0 {
+			return nil, fmt.Errorf("error parsing credentials file line %d: expected username:hash", lineNumber)
+		}
+
+		// bcrypt hashes don't contain colons so anything after the next one is groups
+		hash, userGroups, _ := strings.Cut(hash, ":")
+		for _, group := range strings.Split(userGroups, ",") {
+			if group = strings.TrimSpace(group); len(group) > 0 {
+				groups[username] = append(groups[username], group)
+			}
+		}
+
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("error parsing credentials file line %d: password of %s is not a bcrypt hash: %w", lineNumber, username, err)
+		}
+
+		credentials[username] = []byte(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading credentials file: %w", err)
+	}
+
+	return NewBasicAuthenticator(credentials, groups), nil
+}
+
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	hash, ok := a.credentials[username]
+	if !ok {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, fmt.Errorf("invalid username or password")
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+
+	return &Principal{Name: username, Method: MethodBasic, Groups: a.groups[username]}, nil
+}
+
+func (a *BasicAuthenticator) Challenge() string {
+	return `Basic realm="franz-schema-registry"`
+}
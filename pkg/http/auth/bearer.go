@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// BearerAuthenticator authenticates JWT bearer tokens signed by one of the keys in a local key set
+type BearerAuthenticator struct {
+	keySet      jwk.Set
+	issuer      string
+	audience    string
+	groupsClaim string
+}
+
+// NewBearerAuthenticator validates the signature and expiry of tokens, the issuer and audience are only checked when set.
+// The groups of the principal are read from the groups claim when it is set
+func NewBearerAuthenticator(keySet jwk.Set, issuer string, audience string, groupsClaim string) *BearerAuthenticator {
+	return &BearerAuthenticator{
+		keySet:      keySet,
+		issuer:      issuer,
+		audience:    audience,
+		groupsClaim: groupsClaim,
+	}
+}
+
+// NewBearerAuthenticatorFromFile reads the keys tokens are signed with from a JWKS file or PEM encoded public keys
+func NewBearerAuthenticatorFromFile(path string, issuer string, audience string, groupsClaim string) (*BearerAuthenticator, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading keys file: %w", err)
+	}
+
+	var keySet jwk.Set
+	if strings.HasPrefix(strings.TrimSpace(string(contents)), "-----BEGIN") {
+		keySet, err = jwk.Parse(contents, jwk.WithPEM(true))
+	} else {
+		keySet, err = jwk.Parse(contents)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing keys file: %w", err)
+	}
+
+	if keySet.Len() == 0 {
+		return nil, fmt.Errorf("keys file %s has no keys", path)
+	}
+
+	return NewBearerAuthenticator(keySet, issuer, audience, groupsClaim), nil
+}
+
+func (a *BearerAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	authorization := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	options := []jwt.ParseOption{
+		// PEM keys don't have a key id or say which algorithm they're for
+		// so try every key, using the algorithms that match the key type
+		jwt.WithKeySet(a.keySet, jws.WithRequireKid(false), jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+		// validating only checks exp when it's there so tokens that never expire have to be refused
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+		jwt.WithAcceptableSkew(time.Minute),
+	}
+	if len(a.issuer) > 0 {
+		options = append(options, jwt.WithIssuer(a.issuer))
+	}
+	if len(a.audience) > 0 {
+		options = append(options, jwt.WithAudience(a.audience))
+	}
+
+	parsedToken, err := jwt.ParseString(strings.TrimSpace(token), options...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+
+	if len(parsedToken.Subject()) == 0 {
+		return nil, fmt.Errorf("invalid bearer token: missing sub claim")
+	}
+
+	groups, err := a.groups(parsedToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %w", err)
+	}
+
+	return &Principal{Name: parsedToken.Subject(), Method: MethodBearer, Groups: groups}, nil
+}
+
+// groups returns the groups in the groups claim, which is either a list of strings or a single string
+func (a *BearerAuthenticator) groups(token jwt.Token) ([]string, error) {
+	if len(a.groupsClaim) == 0 {
+		return nil, nil
+	}
+
+	claim, ok := token.Get(a.groupsClaim)
+	if !ok {
+		return nil, nil
+	}
+
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}, nil
+	case []interface{}:
+		groups := make([]string, 0, len(claim))
+		for _, group := range claim {
+			groupName, ok := group.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s claim must be a list of strings", a.groupsClaim)
+			}
+			groups = append(groups, groupName)
+		}
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("%s claim must be a list of strings", a.groupsClaim)
+	}
+}
+
+func (a *BearerAuthenticator) Challenge() string {
+	return `Bearer realm="franz-schema-registry"`
+}
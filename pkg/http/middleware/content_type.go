@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+)
+
+const (
+	ContentTypeSchemaRegistryV1 = "application/vnd.schemaregistry.v1+json"
+	ContentTypeSchemaRegistry   = "application/vnd.schemaregistry+json"
+	ContentTypeJSON             = "application/json"
+	ContentTypeOctetStream      = "application/octet-stream"
+)
+
+// the content types that confluent SR consumes, all of them are decoded as json
+// https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/core/src/main/java/io/confluent/kafka/schemaregistry/rest/resources/SubjectVersionsResource.java#L78-L79
+var requestContentTypes = []string{
+	ContentTypeSchemaRegistryV1,
+	ContentTypeSchemaRegistry,
+	ContentTypeJSON,
+	ContentTypeOctetStream,
+}
+
+// the content types that confluent SR produces
+var responseContentTypes = []string{
+	ContentTypeSchemaRegistryV1,
+	ContentTypeSchemaRegistry,
+	ContentTypeJSON,
+}
+
+// ContentType accepts the content types confluent clients send and responds with the content type they asked for
+func ContentType(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		responseContentType, ok := negotiateResponseContentType(r.Header.Get("Accept"))
+		if !ok {
+			render.Render(w, r, routers.NewAPIError(http.StatusNotAcceptable, http.StatusNotAcceptable, fmt.Errorf("none of the accepted content types are supported: %s", r.Header.Get("Accept"))))
+			return
+		}
+
+		// requests without a body don't need a content type
+		if r.ContentLength != 0 {
+			requestContentType := r.Header.Get("Content-Type")
+			if len(requestContentType) > 0 {
+				mediaType, _, err := mime.ParseMediaType(requestContentType)
+				if err != nil || !isRequestContentType(mediaType) {
+					render.Render(w, r, routers.NewAPIError(http.StatusUnsupportedMediaType, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type: %s", requestContentType)))
+					return
+				}
+			}
+
+			// everything we accept is json so normalize it for render.Bind
+			r.Header.Set("Content-Type", ContentTypeJSON)
+		}
+
+		if responseContentType != ContentTypeJSON {
+			w = &contentTypeResponseWriter{ResponseWriter: w, contentType: responseContentType}
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func isRequestContentType(mediaType string) bool {
+	for _, contentType := range requestContentTypes {
+		if strings.EqualFold(mediaType, contentType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateResponseContentType picks the response content type from an Accept header, defaulting to json
+func negotiateResponseContentType(accept string) (string, bool) {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return ContentTypeJSON, true
+	}
+
+	type acceptedType struct {
+		mediaType string
+		quality   float64
+	}
+
+	acceptedTypes := make([]acceptedType, 0)
+	for _, field := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(field))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if rawQuality, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(rawQuality, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		acceptedTypes = append(acceptedTypes, acceptedType{mediaType: strings.ToLower(mediaType), quality: quality})
+	}
+
+	// keep the header ordering when the quality is the same
+	sort.SliceStable(acceptedTypes, func(i, j int) bool {
+		return acceptedTypes[i].quality > acceptedTypes[j].quality
+	})
+
+	for _, acceptedType := range acceptedTypes {
+		switch acceptedType.mediaType {
+		case "*/*", "application/*":
+			return ContentTypeJSON, true
+		}
+
+		for _, contentType := range responseContentTypes {
+			if acceptedType.mediaType == contentType {
+				return contentType, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// contentTypeResponseWriter replaces the json content type set by render with the negotiated one
+type contentTypeResponseWriter struct {
+	http.ResponseWriter
+	contentType string
+	wroteHeader bool
+}
+
+func (w *contentTypeResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type")); err == nil && mediaType == ContentTypeJSON {
+			w.Header().Set("Content-Type", w.contentType)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *contentTypeResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contentTypeResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
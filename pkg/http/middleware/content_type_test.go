@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBody struct {
+	Schema string `json:"schema"`
+}
+
+func (b *testBody) Bind(request *http.Request) error {
+	return nil
+}
+
+func (b *testBody) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+func testHandler() http.Handler {
+	return ContentType(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data := &testBody{}
+		if request.ContentLength != 0 {
+			if err := render.Bind(request, data); err != nil {
+				render.Render(writer, request, routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, err))
+				return
+			}
+		}
+		render.Render(writer, request, data)
+	}))
+}
+
+func TestContentTypeRequest(t *testing.T) {
+	handler := testHandler()
+
+	for _, contentType := range []string{
+		ContentTypeSchemaRegistryV1,
+		ContentTypeSchemaRegistry,
+		ContentTypeJSON,
+		ContentTypeOctetStream,
+		"application/json; charset=utf-8",
+		"",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"schema": "one"}`))
+		if len(contentType) > 0 {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode, contentType)
+
+		body := &testBody{}
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(body))
+		assert.Equal(t, "one", body.Schema)
+	}
+
+	// unsupported content type
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`schema=one`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Result().StatusCode)
+	apiError := &routers.APIError{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(apiError))
+	assert.Equal(t, http.StatusUnsupportedMediaType, apiError.ErrorCode)
+
+	// requests without a body don't need a supported content type
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+}
+
+func TestContentTypeResponse(t *testing.T) {
+	handler := testHandler()
+
+	tests := map[string]string{
+		"":                          ContentTypeJSON,
+		"*/*":                       ContentTypeJSON,
+		ContentTypeJSON:             ContentTypeJSON,
+		ContentTypeSchemaRegistryV1: ContentTypeSchemaRegistryV1,
+		ContentTypeSchemaRegistry:   ContentTypeSchemaRegistry,
+		"application/vnd.schemaregistry.v1+json, application/vnd.schemaregistry+json; qs=0.9, application/json; qs=0.5": ContentTypeSchemaRegistryV1,
+		"application/json; q=0.5, application/vnd.schemaregistry+json":                                                  ContentTypeSchemaRegistry,
+		"text/html, application/json; q=0.1":                                                                            ContentTypeJSON,
+	}
+
+	for accept, expectedContentType := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode, accept)
+		assert.True(t, strings.HasPrefix(w.Result().Header.Get("Content-Type"), expectedContentType), accept)
+	}
+
+	// nothing we can respond with
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Result().StatusCode)
+	apiError := &routers.APIError{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(apiError))
+	assert.Equal(t, http.StatusNotAcceptable, apiError.ErrorCode)
+}
@@ -0,0 +1,80 @@
+package acls
+
+import (
+	"os"
+	"testing"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestPostACLBind(t *testing.T) {
+	data := &RequestPostACL{PrincipalType: "user", PrincipalName: "one", Resource: "orders-", Operation: "read"}
+	assert.NoError(t, data.Bind(nil))
+	assert.Equal(t, dbModels.ACLPrincipalTypeUser, data.PrincipalType)
+	assert.Equal(t, dbModels.ACLPatternTypeLiteral, data.PatternType)
+	assert.Equal(t, dbModels.ACLOperationRead, data.Operation)
+
+	tests := []*RequestPostACL{
+		{PrincipalType: "bad", PrincipalName: "one", Operation: "READ"},
+		{PrincipalType: "USER", PrincipalName: "", Operation: "READ"},
+		{PrincipalType: "USER", PrincipalName: "one", PatternType: "bad", Operation: "READ"},
+		{PrincipalType: "USER", PrincipalName: "one", Operation: "bad"},
+	}
+
+	for _, test := range tests {
+		err := test.Bind(nil)
+		apiError := &routers.APIError{}
+		if assert.ErrorAs(t, err, &apiError) {
+			assert.Equal(t, 42207, apiError.ErrorCode)
+		}
+	}
+}
+
+func TestACLs(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer os.Remove(dbFile)
+
+	request := &RequestPostACL{PrincipalType: "USER", PrincipalName: "one", PatternType: "PREFIXED", Resource: "orders-", Operation: "READ"}
+	created, err := postACL(db, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-", created.Resource)
+
+	// duplicates aren't allowed
+	_, err = postACL(db, request)
+	apiError := &routers.APIError{}
+	if assert.ErrorAs(t, err, &apiError) {
+		assert.Equal(t, 40903, apiError.ErrorCode)
+	}
+
+	_, err = postACL(db, &RequestPostACL{PrincipalType: "GROUP", PrincipalName: "developers", PatternType: "LITERAL", Resource: "payments-value", Operation: "WRITE"})
+	assert.NoError(t, err)
+
+	acls, err := getACLs(db, "", "")
+	assert.NoError(t, err)
+	assert.Len(t, acls, 2)
+
+	acls, err = getACLs(db, dbModels.ACLPrincipalTypeUser, "one")
+	assert.NoError(t, err)
+	if assert.Len(t, acls, 1) {
+		assert.Equal(t, created.ID, acls[0].ID)
+	}
+
+	deleted, err := deleteACL(db, created.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, deleted.ID)
+
+	acls, err = getACLs(db, "", "")
+	assert.NoError(t, err)
+	assert.Len(t, acls, 1)
+
+	for _, id := range []string{created.ID.String(), "bad"} {
+		_, err = deleteACL(db, id)
+		apiError := &routers.APIError{}
+		if assert.ErrorAs(t, err, &apiError) {
+			assert.Equal(t, 40410, apiError.ErrorCode)
+		}
+	}
+}
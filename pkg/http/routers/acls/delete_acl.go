@@ -0,0 +1,42 @@
+package acls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func deleteACL(db *gorm.DB, id string) (*ResponseACL, error) {
+	aclID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, routers.NewAPIError(http.StatusNotFound, 40410, fmt.Errorf("acl not found"))
+	}
+
+	acl := &dbModels.ACL{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", aclID).First(acl).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40410, fmt.Errorf("acl not found"))
+			}
+			return fmt.Errorf("error finding acl %s: %w", aclID, err)
+		}
+
+		if err := tx.Delete(acl).Error; err != nil {
+			return fmt.Errorf("error deleting acl %s: %w", aclID, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newResponseACL(acl), nil
+}
@@ -0,0 +1,30 @@
+package acls
+
+import (
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+func getACLs(db *gorm.DB, principalType dbModels.ACLPrincipalType, principalName string) (ResponseGetACLs, error) {
+	var acls []dbModels.ACL
+
+	aclsTx := db.Order("principal_type, principal_name, resource, operation")
+	if len(principalType) > 0 {
+		aclsTx = aclsTx.Where("principal_type = ?", principalType)
+	}
+	if len(principalName) > 0 {
+		aclsTx = aclsTx.Where("principal_name = ?", principalName)
+	}
+
+	err := aclsTx.Find(&acls).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(ResponseGetACLs, len(acls))
+	for index := range acls {
+		response[index] = newResponseACL(&acls[index])
+	}
+
+	return response, nil
+}
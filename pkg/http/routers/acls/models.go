@@ -0,0 +1,82 @@
+package acls
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"golang.org/x/exp/slices"
+)
+
+type RequestPostACL struct {
+	PrincipalType dbModels.ACLPrincipalType `json:"principalType"`
+	PrincipalName string                    `json:"principalName"`
+	PatternType   dbModels.ACLPatternType   `json:"patternType"`
+	Resource      string                    `json:"resource"`
+	Operation     dbModels.ACLOperation     `json:"operation"`
+}
+
+func (r *RequestPostACL) Bind(request *http.Request) error {
+	r.PrincipalType = dbModels.ACLPrincipalType(strings.ToUpper(string(r.PrincipalType)))
+	r.PatternType = dbModels.ACLPatternType(strings.ToUpper(string(r.PatternType)))
+	r.Operation = dbModels.ACLOperation(strings.ToUpper(string(r.Operation)))
+
+	// literal is the default like kafka acls
+	if len(r.PatternType) == 0 {
+		r.PatternType = dbModels.ACLPatternTypeLiteral
+	}
+
+	if !slices.Contains(dbModels.ACLPrincipalTypes, r.PrincipalType) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42207, fmt.Errorf("invalid principal type. Valid values are USER and GROUP"))
+	}
+
+	if len(r.PrincipalName) == 0 {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42207, fmt.Errorf("principal name must be set"))
+	}
+
+	if !slices.Contains(dbModels.ACLPatternTypes, r.PatternType) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42207, fmt.Errorf("invalid pattern type. Valid values are LITERAL and PREFIXED"))
+	}
+
+	if !slices.Contains(dbModels.ACLOperations, r.Operation) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42207, fmt.Errorf("invalid operation. Valid values are READ, WRITE, DELETE, CONFIG, and MODE"))
+	}
+
+	return nil
+}
+
+type ResponseACL struct {
+	ID            uuid.UUID                 `json:"id"`
+	PrincipalType dbModels.ACLPrincipalType `json:"principalType"`
+	PrincipalName string                    `json:"principalName"`
+	PatternType   dbModels.ACLPatternType   `json:"patternType"`
+	Resource      string                    `json:"resource"`
+	Operation     dbModels.ACLOperation     `json:"operation"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+}
+
+func newResponseACL(acl *dbModels.ACL) *ResponseACL {
+	return &ResponseACL{
+		ID:            acl.ID,
+		PrincipalType: acl.PrincipalType,
+		PrincipalName: acl.PrincipalName,
+		PatternType:   acl.PatternType,
+		Resource:      acl.Resource,
+		Operation:     acl.Operation,
+		CreatedAt:     acl.CreatedAt,
+	}
+}
+
+func (r *ResponseACL) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type ResponseGetACLs []*ResponseACL
+
+func (r ResponseGetACLs) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package acls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func postACL(db *gorm.DB, data *RequestPostACL) (*ResponseACL, error) {
+	acl := &dbModels.ACL{
+		ID:            uuid.New(),
+		PrincipalType: data.PrincipalType,
+		PrincipalName: data.PrincipalName,
+		PatternType:   data.PatternType,
+		Resource:      data.Resource,
+		Operation:     data.Operation,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		existing := &dbModels.ACL{}
+		err := tx.Where("principal_type = ? AND principal_name = ? AND pattern_type = ? AND resource = ? AND operation = ?",
+			acl.PrincipalType, acl.PrincipalName, acl.PatternType, acl.Resource, acl.Operation).First(existing).Error
+		if err == nil {
+			return routers.NewAPIError(http.StatusConflict, 40903, fmt.Errorf("acl already exists with id %s", existing.ID))
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error finding existing acl: %w", err)
+		}
+
+		if err := tx.Create(acl).Error; err != nil {
+			return fmt.Errorf("error creating acl: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newResponseACL(acl), nil
+}
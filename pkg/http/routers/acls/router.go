@@ -0,0 +1,83 @@
+package acls
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+// NewRouter is the admin api to manage acls, only super users are allowed to use it
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer) *chi.Mux {
+	chiRouter := chi.NewRouter()
+	chiRouter.Use(authorizer.SuperUser)
+
+	chiRouter.Get("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		// Only return acls of this principal
+		principalType := request.URL.Query().Get("principalType")
+		principalName := request.URL.Query().Get("principalName")
+
+		var v render.Renderer
+		v, err := getACLs(db, dbModels.ACLPrincipalType(principalType), principalName)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing acls: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	chiRouter.Post("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		data := &RequestPostACL{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		if v == nil {
+			var err error
+			v, err = postACL(db, data)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error creating acl: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	chiRouter.Delete("/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		id := chi.URLParam(request, "id")
+
+		var v render.Renderer
+		v, err := deleteACL(db, id)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting acl: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
@@ -35,3 +35,7 @@ func (a *APIError) Render(w http.ResponseWriter, r *http.Request) error {
 	render.Status(r, a.httpStatusCode)
 	return nil
 }
+
+func (a *APIError) HTTPStatusCode() int {
+	return a.httpStatusCode
+}
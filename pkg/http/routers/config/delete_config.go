@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func deleteGlobalConfig(db *gorm.DB, publisher *events.Publisher) (*ResponseGetConfig, error) {
+	resp := &ResponseGetConfig{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		config, err := dbModels.GetConfig(tx, dbModels.ConfigSubjectGlobal)
+		if err != nil {
+			return err
+		}
+
+		// nothing to delete, so we are already using the default
+		resp.CompatibilityLevel = dbModels.DefaultSubjectCompatibility
+		if config == nil {
+			return nil
+		}
+
+		if len(config.Compatibility) > 0 {
+			resp.CompatibilityLevel = config.Compatibility
+		}
+		resp.Normalize = config.Normalize
+		if err := resp.DataContract.load(config); err != nil {
+			return err
+		}
+
+		if err := publisher.Publish(tx, events.Event{Kind: events.KindConfig, Subject: config.Subject, Deleted: true}); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(config).Error; err != nil {
+			return fmt.Errorf("error deleting global config: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func deleteSubjectConfig(db *gorm.DB, publisher *events.Publisher, subjectName string) (*ResponseGetConfig, error) {
+	resp := &ResponseGetConfig{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		config, err := dbModels.GetConfig(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		if config == nil {
+			subject := &dbModels.Subject{}
+			err := tx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).Where("name = ?", subjectName).First(subject).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+				}
+				return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+			}
+
+			return routers.NewAPIError(http.StatusNotFound, 40408, fmt.Errorf("subject does not have subject-level compatibility configured"))
+		}
+
+		resp.CompatibilityLevel = config.Compatibility
+		resp.Normalize = config.Normalize
+		if err := resp.DataContract.load(config); err != nil {
+			return err
+		}
+
+		if err := publisher.Publish(tx, events.Event{Kind: events.KindConfig, Subject: config.Subject, Deleted: true}); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(config).Error; err != nil {
+			return fmt.Errorf("error deleting config for subject %s: %w", subjectName, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteGlobalConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// delete on empty db returns the default
+	resp, err := deleteGlobalConfig(db, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectCompatibility, resp.CompatibilityLevel)
+
+	_, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityNone})
+	assert.NoError(t, err)
+
+	// delete returns the previous level
+	resp, err = deleteGlobalConfig(db, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.CompatibilityLevel)
+
+	// global is back to the default
+	getResp, err := getGlobalConfig(db)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectCompatibility, getResp.CompatibilityLevel)
+}
+
+func TestDeleteSubjectConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// unknown subject
+	resp, err := deleteSubjectConfig(db, publisher, "one")
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// subject without config
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error)
+	resp, err = deleteSubjectConfig(db, publisher, "one")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40408, apiError.ErrorCode)
+
+	_, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFull})
+	assert.NoError(t, err)
+	_, err = putConfig(db, publisher, "one", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityNone})
+	assert.NoError(t, err)
+
+	// delete returns the previous level
+	resp, err = deleteSubjectConfig(db, publisher, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.CompatibilityLevel)
+
+	// subject falls back to global
+	compatibility, err := dbModels.GetSubjectCompatibility(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, compatibility)
+}
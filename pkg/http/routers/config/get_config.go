@@ -0,0 +1,112 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func getGlobalConfig(db *gorm.DB) (*ResponseGetConfig, error) {
+	resp := &ResponseGetConfig{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		compatibility, err := dbModels.GetSubjectCompatibility(tx, dbModels.ConfigSubjectGlobal)
+		if err != nil {
+			return err
+		}
+
+		resp.CompatibilityLevel = compatibility
+
+		config, err := dbModels.GetConfig(tx, dbModels.ConfigSubjectGlobal)
+		if err != nil {
+			return err
+		}
+
+		if config != nil {
+			resp.Normalize = config.Normalize
+			if err := resp.DataContract.load(config); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func getSubjectConfig(db *gorm.DB, subjectName string, defaultToGlobal bool) (*ResponseGetConfig, error) {
+	resp := &ResponseGetConfig{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if defaultToGlobal {
+			compatibility, err := dbModels.GetSubjectCompatibility(tx, subjectName)
+			if err != nil {
+				return err
+			}
+
+			normalize, err := dbModels.GetSubjectNormalize(tx, subjectName)
+			if err != nil {
+				return err
+			}
+
+			contract, err := dbModels.GetSubjectDataContract(tx, subjectName)
+			if err != nil {
+				return err
+			}
+
+			resp.CompatibilityLevel = compatibility
+			resp.Normalize = &normalize
+			resp.DefaultMetadata = contract.DefaultMetadata
+			resp.OverrideMetadata = contract.OverrideMetadata
+			resp.DefaultRuleSet = contract.DefaultRuleSet
+			resp.OverrideRuleSet = contract.OverrideRuleSet
+			return nil
+		}
+
+		config, err := dbModels.GetConfig(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		if config == nil {
+			subject := &dbModels.Subject{}
+			err := tx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).Where("name = ?", subjectName).First(subject).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+				}
+				return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+			}
+
+			return routers.NewAPIError(http.StatusNotFound, 40408, fmt.Errorf("subject does not have subject-level compatibility configured"))
+		}
+
+		resp.CompatibilityLevel = config.Compatibility
+		resp.Normalize = config.Normalize
+		if err := resp.DataContract.load(config); err != nil {
+			return err
+		}
+
+		// a config that was emptied is the same as no config
+		if len(resp.CompatibilityLevel) == 0 && resp.Normalize == nil && resp.DataContract.empty() {
+			return routers.NewAPIError(http.StatusNotFound, 40408, fmt.Errorf("subject does not have subject-level compatibility configured"))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGlobalConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// get config on empty db returns the default
+	resp, err := getGlobalConfig(db)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectCompatibility, resp.CompatibilityLevel)
+
+	_, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFullTransitive})
+	assert.NoError(t, err)
+
+	resp, err = getGlobalConfig(db)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFullTransitive, resp.CompatibilityLevel)
+}
+
+func TestGetSubjectConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// unknown subject
+	resp, err := getSubjectConfig(db, "one", false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// subject without config
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error)
+	resp, err = getSubjectConfig(db, "one", false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40408, apiError.ErrorCode)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// subject without config default to global
+	_, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityForward})
+	assert.NoError(t, err)
+	resp, err = getSubjectConfig(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityForward, resp.CompatibilityLevel)
+
+	// subject with config
+	_, err = putConfig(db, publisher, "one", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityNone})
+	assert.NoError(t, err)
+	resp, err = getSubjectConfig(db, "one", false)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.CompatibilityLevel)
+	resp, err = getSubjectConfig(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.CompatibilityLevel)
+}
+
+func TestGetSubjectConfigContext(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: ":.team-a:one", Context: ".team-a"}).Error)
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error)
+
+	_, err := putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityForward})
+	assert.NoError(t, err)
+
+	// the context config applies to the subjects in the context
+	_, err = putConfig(db, publisher, ":.team-a:", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFull})
+	assert.NoError(t, err)
+	resp, err := getSubjectConfig(db, ":.team-a:one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, resp.CompatibilityLevel)
+
+	// but not to the subjects of the default context
+	resp, err = getSubjectConfig(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityForward, resp.CompatibilityLevel)
+
+	// the subject config is used before the context config
+	_, err = putConfig(db, publisher, ":.team-a:one", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityNone})
+	assert.NoError(t, err)
+	resp, err = getSubjectConfig(db, ":.team-a:one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.CompatibilityLevel)
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"golang.org/x/exp/slices"
+)
+
+// DataContract is the metadata and rule sets given to schemas registered to the subject
+type DataContract struct {
+	DefaultMetadata  *dbModels.Metadata `json:"defaultMetadata,omitempty"`
+	OverrideMetadata *dbModels.Metadata `json:"overrideMetadata,omitempty"`
+	DefaultRuleSet   *dbModels.RuleSet  `json:"defaultRuleSet,omitempty"`
+	OverrideRuleSet  *dbModels.RuleSet  `json:"overrideRuleSet,omitempty"`
+}
+
+func (d *DataContract) empty() bool {
+	return d.DefaultMetadata == nil && d.OverrideMetadata == nil && d.DefaultRuleSet == nil && d.OverrideRuleSet == nil
+}
+
+func (d *DataContract) validate() error {
+	for name, metadata := range map[string]*dbModels.Metadata{"default metadata": d.DefaultMetadata, "override metadata": d.OverrideMetadata} {
+		if metadata == nil {
+			continue
+		}
+		if err := metadata.Validate(); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	for name, ruleSet := range map[string]*dbModels.RuleSet{"default rule set": d.DefaultRuleSet, "override rule set": d.OverrideRuleSet} {
+		if ruleSet == nil {
+			continue
+		}
+		if err := ruleSet.Validate(); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// apply sets the metadata and rule sets that aren't nil on the config
+func (d *DataContract) apply(config *dbModels.Config) error {
+	var err error
+
+	if d.DefaultMetadata != nil {
+		if config.DefaultMetadata, err = dbModels.EncodeMetadata(d.DefaultMetadata); err != nil {
+			return err
+		}
+	}
+	if d.OverrideMetadata != nil {
+		if config.OverrideMetadata, err = dbModels.EncodeMetadata(d.OverrideMetadata); err != nil {
+			return err
+		}
+	}
+	if d.DefaultRuleSet != nil {
+		if config.DefaultRuleSet, err = dbModels.EncodeRuleSet(d.DefaultRuleSet); err != nil {
+			return err
+		}
+	}
+	if d.OverrideRuleSet != nil {
+		if config.OverrideRuleSet, err = dbModels.EncodeRuleSet(d.OverrideRuleSet); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load sets the metadata and rule sets from the config
+func (d *DataContract) load(config *dbModels.Config) error {
+	var err error
+
+	if d.DefaultMetadata, err = dbModels.DecodeMetadata(config.DefaultMetadata); err != nil {
+		return err
+	}
+	if d.OverrideMetadata, err = dbModels.DecodeMetadata(config.OverrideMetadata); err != nil {
+		return err
+	}
+	if d.DefaultRuleSet, err = dbModels.DecodeRuleSet(config.DefaultRuleSet); err != nil {
+		return err
+	}
+	if d.OverrideRuleSet, err = dbModels.DecodeRuleSet(config.OverrideRuleSet); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type RequestPutConfig struct {
+	Compatibility dbModels.SubjectCompatibility `json:"compatibility,omitempty"`
+	Normalize     *bool                         `json:"normalize,omitempty"`
+	DataContract
+}
+
+func (r *RequestPutConfig) Bind(request *http.Request) error {
+	if err := r.DataContract.validate(); err != nil {
+		return err
+	}
+
+	// only normalize or the data contract is being changed
+	if len(r.Compatibility) == 0 && (r.Normalize != nil || !r.DataContract.empty()) {
+		return nil
+	}
+
+	// confluent sr accepts levels in any case
+	r.Compatibility = dbModels.SubjectCompatibility(strings.ToUpper(string(r.Compatibility)))
+
+	if !slices.Contains(dbModels.SubjectCompatibilities, r.Compatibility) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42203, fmt.Errorf("invalid compatibility level. Valid values are none, backward, forward, full, backward_transitive, forward_transitive, and full_transitive"))
+	}
+
+	return nil
+}
+
+type ResponsePutConfig struct {
+	Compatibility dbModels.SubjectCompatibility `json:"compatibility,omitempty"`
+	Normalize     *bool                         `json:"normalize,omitempty"`
+	DataContract
+}
+
+func (r *ResponsePutConfig) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type ResponseGetConfig struct {
+	CompatibilityLevel dbModels.SubjectCompatibility `json:"compatibilityLevel,omitempty"`
+	Normalize          *bool                         `json:"normalize,omitempty"`
+	DataContract
+}
+
+func (r *ResponseGetConfig) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"gorm.io/gorm"
+)
+
+func putConfig(db *gorm.DB, publisher *events.Publisher, subjectName string, data *RequestPutConfig) (*ResponsePutConfig, error) {
+	resp := &ResponsePutConfig{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		config, err := dbModels.GetConfig(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		// if config is nil, create it
+		if config == nil {
+			config = &dbModels.Config{
+				ID:      uuid.New(),
+				Subject: subjectName,
+			}
+		}
+
+		if len(data.Compatibility) > 0 {
+			config.Compatibility = data.Compatibility
+		}
+		if data.Normalize != nil {
+			config.Normalize = data.Normalize
+		}
+		if err := data.DataContract.apply(config); err != nil {
+			return err
+		}
+		if err := tx.Save(config).Error; err != nil {
+			return fmt.Errorf("error saving config: %w", err)
+		}
+
+		if err := publisher.Publish(tx, events.Event{Kind: events.KindConfig, Subject: subjectName}); err != nil {
+			return err
+		}
+
+		resp.Compatibility = config.Compatibility
+		resp.Normalize = config.Normalize
+		if err := resp.DataContract.load(config); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestPutConfigBind(t *testing.T) {
+	// valid level
+	data := &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFull}
+	assert.NoError(t, data.Bind(nil))
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, data.Compatibility)
+
+	// lower case level
+	data = &RequestPutConfig{Compatibility: "forward_transitive"}
+	assert.NoError(t, data.Bind(nil))
+	assert.Equal(t, dbModels.SubjectCompatibilityForwardTransitive, data.Compatibility)
+
+	// unknown level
+	data = &RequestPutConfig{Compatibility: "bad"}
+	err := data.Bind(nil)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42203, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	// empty level
+	data = &RequestPutConfig{}
+	err = data.Bind(nil)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42203, apiError.ErrorCode)
+
+	// empty level with normalize
+	normalize := true
+	data = &RequestPutConfig{Normalize: &normalize}
+	assert.NoError(t, data.Bind(nil))
+
+	// empty level with a data contract
+	data = &RequestPutConfig{DataContract: DataContract{DefaultMetadata: &dbModels.Metadata{Sensitive: []string{"ssn"}}}}
+	assert.NoError(t, data.Bind(nil))
+
+	// invalid rule
+	data = &RequestPutConfig{DataContract: DataContract{OverrideRuleSet: &dbModels.RuleSet{
+		DomainRules: []dbModels.Rule{{Name: "encrypt", Kind: dbModels.RuleKindTransform, Mode: dbModels.RuleModeUpgrade, Type: "ENCRYPT"}},
+	}}}
+	assert.ErrorContains(t, data.Bind(nil), "invalid override rule set")
+}
+
+func TestPutConfigDataContract(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	defaultMetadata := &dbModels.Metadata{Properties: map[string]string{"owner": "governance"}}
+	overrideRuleSet := &dbModels.RuleSet{
+		DomainRules: []dbModels.Rule{{Name: "encrypt", Kind: dbModels.RuleKindTransform, Mode: dbModels.RuleModeWriteRead, Type: "ENCRYPT", Tags: []string{"PII"}}},
+	}
+
+	resp, err := putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{DataContract: DataContract{DefaultMetadata: defaultMetadata}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMetadata, resp.DefaultMetadata)
+
+	// changing the compatibility keeps the data contract
+	resp, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFull})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMetadata, resp.DefaultMetadata)
+
+	getResp, err := getGlobalConfig(db)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMetadata, getResp.DefaultMetadata)
+
+	// subjects fall back to the global data contract
+	_, err = putConfig(db, publisher, "one", &RequestPutConfig{DataContract: DataContract{OverrideRuleSet: overrideRuleSet}})
+	assert.NoError(t, err)
+	getResp, err = getSubjectConfig(db, "one", false)
+	assert.NoError(t, err)
+	assert.Nil(t, getResp.DefaultMetadata)
+	assert.Equal(t, overrideRuleSet, getResp.OverrideRuleSet)
+
+	getResp, err = getSubjectConfig(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMetadata, getResp.DefaultMetadata)
+	assert.Equal(t, overrideRuleSet, getResp.OverrideRuleSet)
+}
+
+func TestPutConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// set global config
+	resp, err := putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityFull})
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, resp.Compatibility)
+
+	compatibility, err := dbModels.GetSubjectCompatibility(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, compatibility)
+
+	// set subject config, subject doesn't need to exist
+	resp, err = putConfig(db, publisher, "one", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityNone})
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, resp.Compatibility)
+
+	compatibility, err = dbModels.GetSubjectCompatibility(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityNone, compatibility)
+
+	// update subject config
+	resp, err = putConfig(db, publisher, "one", &RequestPutConfig{Compatibility: dbModels.SubjectCompatibilityForward})
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityForward, resp.Compatibility)
+
+	compatibility, err = dbModels.GetSubjectCompatibility(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityForward, compatibility)
+
+	// other subjects still use global
+	compatibility, err = dbModels.GetSubjectCompatibility(db, "two")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, compatibility)
+
+	var count int64
+	assert.NoError(t, db.Model(&dbModels.Config{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// normalize defaults to false
+	normalize, err := dbModels.GetSubjectNormalize(db, "one")
+	assert.NoError(t, err)
+	assert.False(t, normalize)
+
+	// set global normalize, compatibility is kept
+	normalizeTrue := true
+	resp, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, &RequestPutConfig{Normalize: &normalizeTrue})
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, resp.Compatibility)
+	assert.Equal(t, &normalizeTrue, resp.Normalize)
+
+	normalize, err = dbModels.GetSubjectNormalize(db, "one")
+	assert.NoError(t, err)
+	assert.True(t, normalize)
+
+	// subject normalize overrides global
+	normalizeFalse := false
+	resp, err = putConfig(db, publisher, "one", &RequestPutConfig{Normalize: &normalizeFalse})
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityForward, resp.Compatibility)
+	assert.Equal(t, &normalizeFalse, resp.Normalize)
+
+	normalize, err = dbModels.GetSubjectNormalize(db, "one")
+	assert.NoError(t, err)
+	assert.False(t, normalize)
+
+	normalize, err = dbModels.GetSubjectNormalize(db, "two")
+	assert.NoError(t, err)
+	assert.True(t, normalize)
+
+	// subject with only normalize set still uses the global compatibility
+	resp, err = putConfig(db, publisher, "three", &RequestPutConfig{Normalize: &normalizeFalse})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Compatibility)
+
+	compatibility, err = dbModels.GetSubjectCompatibility(db, "three")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectCompatibilityFull, compatibility)
+}
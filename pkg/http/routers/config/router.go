@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer, publisher *events.Publisher) *chi.Mux {
+	chiRouter := chi.NewRouter()
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#put--config
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationConfig)).Put("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		data := &RequestPutConfig{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		if v == nil {
+			var err error
+			v, err = putConfig(db, publisher, dbModels.ConfigSubjectGlobal, data)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving global config: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--config
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		var v render.Renderer
+		v, err := getGlobalConfig(db)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting global config: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#delete--config
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationConfig)).Delete("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		var v render.Renderer
+		v, err := deleteGlobalConfig(db, publisher)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting global config: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#put--config-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationConfig)).Put("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+		data := &RequestPutConfig{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		if v == nil {
+			var err error
+			v, err = putConfig(db, publisher, subjectName, data)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving subject config: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--config-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+
+		// Whether to return the global config when the subject has no config
+		defaultToGlobalRaw := request.URL.Query().Get("defaultToGlobal")
+		defaultToGlobal, _ := strconv.ParseBool(defaultToGlobalRaw)
+
+		var v render.Renderer
+		v, err := getSubjectConfig(db, subjectName, defaultToGlobal)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting subject config: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#delete--config-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationConfig)).Delete("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+
+		var v render.Renderer
+		v, err := deleteSubjectConfig(db, publisher, subjectName)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting subject config: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
@@ -0,0 +1,23 @@
+package contexts
+
+import (
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// getContexts returns the default context and every context with a subject, sorted by name
+func getContexts(db *gorm.DB) (*ResponseGetContexts, error) {
+	var contexts []string
+
+	err := db.Model(&dbModels.Subject{}).Clauses(dbModels.ForceIndexHint("idx_subjects_context")).
+		Where("context <> ''").Distinct().Order("context asc").Pluck("context", &contexts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	contextList := make(ResponseGetContexts, 0, len(contexts)+1)
+	contextList = append(contextList, dbModels.DefaultContext)
+	contextList = append(contextList, contexts...)
+
+	return &contextList, nil
+}
@@ -0,0 +1,34 @@
+package contexts
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetContexts(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// the default context is always there
+	resp, err := getContexts(db)
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseGetContexts{"."}, *resp)
+
+	for _, subjectName := range []string{"orders", ":.team-b:orders", ":.team-a:orders", ":.team-a:payments"} {
+		assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: subjectName, Context: dbModels.SubjectContextColumn(subjectName)}).Error)
+	}
+
+	resp, err = getContexts(db)
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseGetContexts{".", ".team-a", ".team-b"}, *resp)
+}
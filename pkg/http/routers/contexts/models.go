@@ -0,0 +1,11 @@
+package contexts
+
+import (
+	"net/http"
+)
+
+type ResponseGetContexts []string
+
+func (r ResponseGetContexts) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
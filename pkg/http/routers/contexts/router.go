@@ -0,0 +1,63 @@
+package contexts
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer) *chi.Mux {
+	chiRouter := chi.NewRouter()
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--contexts
+	chiRouter.Get("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		var v render.Renderer
+		contextList, err := getContexts(db)
+		if err == nil {
+			// only list the contexts the principal can read, the default context is always listed
+			*contextList, err = filterContexts(request, authorizer, *contextList)
+			v = contextList
+		}
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing contexts: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
+
+// filterContexts returns the contexts the principal is allowed to read, a context is checked as its qualified name
+func filterContexts(request *http.Request, authorizer *acl.Authorizer, contextList ResponseGetContexts) (ResponseGetContexts, error) {
+	contextSubjects := make([]string, 0, len(contextList))
+	for _, context := range contextList {
+		if context != dbModels.DefaultContext {
+			contextSubjects = append(contextSubjects, dbModels.QualifySubjectName(context, ""))
+		}
+	}
+
+	allowedSubjects, err := authorizer.FilterSubjects(request.Context(), dbModels.ACLOperationRead, contextSubjects)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := ResponseGetContexts{dbModels.DefaultContext}
+	for _, contextSubject := range allowedSubjects {
+		context, _ := dbModels.ParseSubjectName(contextSubject)
+		filtered = append(filtered, context)
+	}
+
+	return filtered, nil
+}
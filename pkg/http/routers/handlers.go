@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// NotFound renders a schema registry error instead of chi's plain text response
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, NewAPIError(http.StatusNotFound, http.StatusNotFound, fmt.Errorf("HTTP 404 Not Found")))
+}
+
+// MethodNotAllowed renders a schema registry error instead of chi's plain text response
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, NewAPIError(http.StatusMethodNotAllowed, http.StatusMethodNotAllowed, fmt.Errorf("HTTP 405 Method Not Allowed")))
+}
@@ -0,0 +1,54 @@
+package mode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func deleteSubjectMode(db *gorm.DB, publisher *events.Publisher, subjectName string) (*ResponseMode, error) {
+	resp := &ResponseMode{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		mode, err := dbModels.GetMode(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		if mode == nil {
+			subject := &dbModels.Subject{}
+			err := tx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).Where("name = ?", subjectName).First(subject).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+				}
+				return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+			}
+
+			return routers.NewAPIError(http.StatusNotFound, 40409, fmt.Errorf("subject does not have subject-level mode configured"))
+		}
+
+		resp.Mode = mode.Mode
+
+		if err := publisher.Publish(tx, events.Event{Kind: events.KindMode, Subject: subjectName, Deleted: true}); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(mode).Error; err != nil {
+			return fmt.Errorf("error deleting mode for subject %s: %w", subjectName, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,47 @@
+package mode
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteSubjectMode(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// unknown subject
+	resp, err := deleteSubjectMode(db, publisher, "one")
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+
+	_, err = putMode(db, publisher, dbModels.ModeSubjectGlobal, &RequestPutMode{Mode: dbModels.SubjectModeImport}, false)
+	assert.NoError(t, err)
+	_, err = putMode(db, publisher, "one", &RequestPutMode{Mode: dbModels.SubjectModeReadOnly}, false)
+	assert.NoError(t, err)
+
+	// delete returns the previous mode
+	resp, err = deleteSubjectMode(db, publisher, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, resp.Mode)
+
+	// subject falls back to global
+	mode, err := dbModels.GetSubjectMode(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeImport, mode)
+}
@@ -0,0 +1,83 @@
+package mode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func getGlobalMode(db *gorm.DB) (*ResponseMode, error) {
+	resp := &ResponseMode{}
+
+	mode, err := dbModels.GetMode(db, dbModels.ModeSubjectGlobal)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Mode = dbModels.DefaultSubjectMode
+	if mode != nil {
+		resp.Mode = mode.Mode
+	}
+
+	return resp, nil
+}
+
+func getSubjectMode(db *gorm.DB, subjectName string, defaultToGlobal bool) (*ResponseMode, error) {
+	resp := &ResponseMode{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		mode, err := dbModels.GetMode(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		if mode != nil {
+			resp.Mode = mode.Mode
+			return nil
+		}
+
+		if defaultToGlobal {
+			// subjects in a context default to the mode of their context before the global one
+			if contextSubjectName := dbModels.ContextSubjectName(subjectName); contextSubjectName != dbModels.ModeSubjectGlobal && contextSubjectName != subjectName {
+				contextMode, err := dbModels.GetMode(tx, contextSubjectName)
+				if err != nil {
+					return err
+				}
+
+				if contextMode != nil {
+					resp.Mode = contextMode.Mode
+					return nil
+				}
+			}
+
+			globalMode, err := getGlobalMode(tx)
+			if err != nil {
+				return err
+			}
+
+			resp.Mode = globalMode.Mode
+			return nil
+		}
+
+		subject := &dbModels.Subject{}
+		err = tx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).Where("name = ?", subjectName).First(subject).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+			}
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+
+		return routers.NewAPIError(http.StatusNotFound, 40409, fmt.Errorf("subject does not have subject-level mode configured"))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+package mode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGlobalMode(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// get mode on empty db returns the default
+	resp, err := getGlobalMode(db)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectMode, resp.Mode)
+
+	_, err = putMode(db, publisher, dbModels.ModeSubjectGlobal, &RequestPutMode{Mode: dbModels.SubjectModeReadOnly}, false)
+	assert.NoError(t, err)
+
+	resp, err = getGlobalMode(db)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, resp.Mode)
+}
+
+func TestGetSubjectMode(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// unknown subject
+	resp, err := getSubjectMode(db, "one", false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// subject without mode
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error)
+	resp, err = getSubjectMode(db, "one", false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40409, apiError.ErrorCode)
+
+	// subject without mode default to global
+	resp, err = getSubjectMode(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectMode, resp.Mode)
+
+	// subject with mode
+	_, err = putMode(db, publisher, "one", &RequestPutMode{Mode: dbModels.SubjectModeReadOnly}, false)
+	assert.NoError(t, err)
+	resp, err = getSubjectMode(db, "one", false)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, resp.Mode)
+}
+
+func TestGetSubjectModeContext(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: ":.team-a:one", Context: ".team-a"}).Error)
+	assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error)
+
+	// the context mode applies to the subjects in the context
+	_, err := putMode(db, publisher, ":.team-a:", &RequestPutMode{Mode: dbModels.SubjectModeReadOnly}, false)
+	assert.NoError(t, err)
+	resp, err := getSubjectMode(db, ":.team-a:one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, resp.Mode)
+
+	mode, err := dbModels.GetSubjectMode(db, ":.team-a:one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, mode)
+
+	// but not to the subjects of the default context
+	resp, err = getSubjectMode(db, "one", true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.DefaultSubjectMode, resp.Mode)
+
+	// the subject mode is used before the context mode
+	_, err = putMode(db, publisher, ":.team-a:one", &RequestPutMode{Mode: dbModels.SubjectModeReadWrite}, false)
+	assert.NoError(t, err)
+	mode, err = dbModels.GetSubjectMode(db, ":.team-a:one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadWrite, mode)
+
+	// unless the context mode is READONLY_OVERRIDE
+	_, err = putMode(db, publisher, ":.team-a:", &RequestPutMode{Mode: dbModels.SubjectModeReadOnlyOverride}, false)
+	assert.NoError(t, err)
+	mode, err = dbModels.GetSubjectMode(db, ":.team-a:one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, mode)
+}
@@ -0,0 +1,33 @@
+package mode
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"golang.org/x/exp/slices"
+)
+
+type RequestPutMode struct {
+	Mode dbModels.SubjectMode `json:"mode"`
+}
+
+func (r *RequestPutMode) Bind(request *http.Request) error {
+	r.Mode = dbModels.SubjectMode(strings.ToUpper(string(r.Mode)))
+
+	if !slices.Contains(dbModels.SubjectModes, r.Mode) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42204, fmt.Errorf("invalid mode. Valid values are READWRITE, READONLY, READONLY_OVERRIDE, and IMPORT"))
+	}
+
+	return nil
+}
+
+type ResponseMode struct {
+	Mode dbModels.SubjectMode `json:"mode"`
+}
+
+func (r *ResponseMode) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
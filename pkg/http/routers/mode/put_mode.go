@@ -0,0 +1,76 @@
+package mode
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func putMode(db *gorm.DB, publisher *events.Publisher, subjectName string, data *RequestPutMode, force bool) (*ResponseMode, error) {
+	resp := &ResponseMode{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// importing into existing schemas could create id and version conflicts
+		// so only allow it on empty subjects unless forced
+		if data.Mode == dbModels.SubjectModeImport && !force {
+			var versions int64
+
+			versionsTx := tx.Model(&dbModels.SubjectVersion{})
+			if subjectName != dbModels.ModeSubjectGlobal && subjectName == dbModels.ContextSubjectName(subjectName) {
+				// the mode of a context applies to every subject in it
+				versionsTx = versionsTx.Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
+					Where("subjects.context = ? AND subjects.deleted_at is NULL", dbModels.SubjectContextColumn(subjectName))
+			} else if subjectName != dbModels.ModeSubjectGlobal {
+				versionsTx = versionsTx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).
+					Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
+					Where("subjects.name = ? AND subjects.deleted_at is NULL", subjectName)
+			}
+
+			err := versionsTx.Count(&versions).Error
+			if err != nil {
+				return fmt.Errorf("error counting existing versions: %w", err)
+			}
+
+			if versions > 0 {
+				return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("cannot import since found existing subjects"))
+			}
+		}
+
+		mode, err := dbModels.GetMode(tx, subjectName)
+		if err != nil {
+			return err
+		}
+
+		// if mode is nil, create it
+		if mode == nil {
+			mode = &dbModels.Mode{
+				ID:      uuid.New(),
+				Subject: subjectName,
+			}
+		}
+
+		mode.Mode = data.Mode
+		if err := tx.Save(mode).Error; err != nil {
+			return fmt.Errorf("error saving mode: %w", err)
+		}
+
+		if err := publisher.Publish(tx, events.Event{Kind: events.KindMode, Subject: subjectName}); err != nil {
+			return err
+		}
+
+		resp.Mode = mode.Mode
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
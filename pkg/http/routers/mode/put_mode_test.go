@@ -0,0 +1,134 @@
+package mode
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRequestPutModeBind(t *testing.T) {
+	data := &RequestPutMode{Mode: "readonly"}
+	assert.NoError(t, data.Bind(nil))
+	assert.Equal(t, dbModels.SubjectModeReadOnly, data.Mode)
+
+	data = &RequestPutMode{Mode: "bad"}
+	err := data.Bind(nil)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42204, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+}
+
+func TestPutMode(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	publisher := events.NewPublisher(configuration.Default().Events)
+
+	// import on an empty registry
+	resp, err := putMode(db, publisher, dbModels.ModeSubjectGlobal, &RequestPutMode{Mode: dbModels.SubjectModeImport}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeImport, resp.Mode)
+
+	mode, err := dbModels.GetSubjectMode(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeImport, mode)
+
+	// subject mode overrides global
+	resp, err = putMode(db, publisher, "one", &RequestPutMode{Mode: dbModels.SubjectModeReadOnly}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, resp.Mode)
+
+	mode, err = dbModels.GetSubjectMode(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, mode)
+
+	// global read only override wins over subject mode
+	_, err = putMode(db, publisher, dbModels.ModeSubjectGlobal, &RequestPutMode{Mode: dbModels.SubjectModeReadOnlyOverride}, false)
+	assert.NoError(t, err)
+	_, err = putMode(db, publisher, "one", &RequestPutMode{Mode: dbModels.SubjectModeReadWrite}, false)
+	assert.NoError(t, err)
+
+	mode, err = dbModels.GetSubjectMode(db, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeReadOnly, mode)
+
+	// insert subject, schema & version
+	err = db.Transaction(func(tx *gorm.DB) error {
+		subject := &dbModels.Subject{
+			ID:   uuid.New(),
+			Name: "two",
+		}
+		if err := tx.Create(subject).Error; err != nil {
+			return fmt.Errorf("error creating subject: %w", err)
+		}
+
+		schema := &dbModels.Schema{
+			ID:         uuid.New(),
+			GlobalID:   1,
+			Schema:     "", // schema and hash doesn't matter for this test
+			Hash:       "",
+			SchemaType: dbModels.SchemaTypeAvro,
+		}
+		if err := tx.Create(schema).Error; err != nil {
+			return fmt.Errorf("error creating schema: %w", err)
+		}
+
+		subjectVersion := &dbModels.SubjectVersion{
+			ID:        uuid.New(),
+			SubjectID: subject.ID,
+			SchemaID:  schema.ID,
+			Version:   1,
+		}
+		if err := tx.Create(subjectVersion).Error; err != nil {
+			return fmt.Errorf("error creating subject version: %w", err)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// can't import into a subject with versions
+	resp, err = putMode(db, publisher, "two", &RequestPutMode{Mode: dbModels.SubjectModeImport}, false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// or globally
+	resp, err = putMode(db, publisher, dbModels.ModeSubjectGlobal, &RequestPutMode{Mode: dbModels.SubjectModeImport}, false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// unless forced
+	resp, err = putMode(db, publisher, "two", &RequestPutMode{Mode: dbModels.SubjectModeImport}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeImport, resp.Mode)
+
+	// empty subjects can still be imported into
+	resp, err = putMode(db, publisher, "three", &RequestPutMode{Mode: dbModels.SubjectModeImport}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, dbModels.SubjectModeImport, resp.Mode)
+}
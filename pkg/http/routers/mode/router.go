@@ -0,0 +1,140 @@
+package mode
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer, publisher *events.Publisher) *chi.Mux {
+	chiRouter := chi.NewRouter()
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--mode
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		var v render.Renderer
+		v, err := getGlobalMode(db)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting global mode: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#put--mode
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationMode)).Put("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		data := &RequestPutMode{}
+
+		// Whether to allow IMPORT mode when schemas are already registered
+		forceRaw := request.URL.Query().Get("force")
+		force, _ := strconv.ParseBool(forceRaw)
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		if v == nil {
+			var err error
+			v, err = putMode(db, publisher, dbModels.ModeSubjectGlobal, data, force)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving global mode: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--mode-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+
+		// Whether to return the global mode when the subject has no mode
+		defaultToGlobalRaw := request.URL.Query().Get("defaultToGlobal")
+		defaultToGlobal, _ := strconv.ParseBool(defaultToGlobalRaw)
+
+		var v render.Renderer
+		v, err := getSubjectMode(db, subjectName, defaultToGlobal)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting subject mode: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#put--mode-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationMode)).Put("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+		data := &RequestPutMode{}
+
+		// Whether to allow IMPORT mode when schemas are already registered
+		forceRaw := request.URL.Query().Get("force")
+		force, _ := strconv.ParseBool(forceRaw)
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		if v == nil {
+			var err error
+			v, err = putMode(db, publisher, subjectName, data, force)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving subject mode: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#delete--mode-(string-%20subject)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationMode)).Delete("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+
+		var v render.Renderer
+		v, err := deleteSubjectMode(db, publisher, subjectName)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting subject mode: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
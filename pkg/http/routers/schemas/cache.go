@@ -0,0 +1,62 @@
+package schemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/cache"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+)
+
+// cachedSchema is a schema looked up by its global id along with its references
+type cachedSchema struct {
+	id       uuid.UUID
+	response ResponseGetSchema
+}
+
+// Cache holds what the schemas router looked up, caches sized zero are disabled
+type Cache struct {
+	// schemas holds schemas keyed by their global id
+	schemas *cache.Cache[int32, *cachedSchema]
+	// names holds the names of the types and fields of schemas keyed by the schema id
+	names *cache.Cache[uuid.UUID, []string]
+}
+
+// NewCache sizes the caches used by the schemas router
+func NewCache(config configuration.CacheConfiguration) (*Cache, error) {
+	schemaCache, err := cache.New[int32, *cachedSchema]("schemas", config.Schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	// a schema's names are as big as the parsed schema so they're sized the same
+	namesCache, err := cache.New[uuid.UUID, []string]("schema_names", config.ParsedSchemas)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		schemas: schemaCache,
+		names:   namesCache,
+	}, nil
+}
+
+// Invalidate evicts the schemas and their names used by the versions the event deleted
+func (c *Cache) Invalidate(event events.Event) {
+	if !event.Deleted || len(event.SchemaIDs) == 0 {
+		return
+	}
+
+	schemaIDs := make(map[uuid.UUID]struct{}, len(event.SchemaIDs))
+	for _, schemaID := range event.SchemaIDs {
+		schemaIDs[schemaID] = struct{}{}
+	}
+
+	c.schemas.RemoveFunc(func(globalID int32, schema *cachedSchema) bool {
+		_, ok := schemaIDs[schema.id]
+		return ok
+	})
+
+	for schemaID := range schemaIDs {
+		c.names.Remove(schemaID)
+	}
+}
@@ -0,0 +1,66 @@
+package schemas
+
+import (
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+func getSchema(db *gorm.DB, schemaCache *Cache, id string, subjectName string) (*ResponseGetSchema, error) {
+	response := &ResponseGetSchema{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// schemas and their references never change so they can be cached,
+		// the subject still needs to be checked as versions come and go
+		if cached, ok := getCachedSchema(schemaCache, id); ok {
+			if len(subjectName) > 0 {
+				subjectVersions, err := getSubjectVersionsBySchemaID(tx, &dbModels.Schema{ID: cached.id, GlobalID: parseGlobalID(id)}, subjectName, false)
+				if err != nil {
+					return err
+				}
+
+				if len(subjectVersions) == 0 {
+					return routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema %s not found", id))
+				}
+			}
+
+			*response = cached.response
+			response.References = append([]SchemaReference(nil), cached.response.References...)
+			return nil
+		}
+
+		schema, err := getSchemaForSubject(tx, id, subjectName)
+		if err != nil {
+			return err
+		}
+
+		response.References, err = getSchemaReferences(tx, schema)
+		if err != nil {
+			return err
+		}
+
+		response.Schema = schema.Schema
+		response.SchemaType = schemas.SchemaType(schema.SchemaType)
+
+		if response.SchemaType == schemas.SchemaTypeAvro {
+			// set to empty string when avro for compatibility
+			response.SchemaType = ""
+		}
+
+		cached := &cachedSchema{id: schema.ID, response: *response}
+		cached.response.References = append([]SchemaReference(nil), response.References...)
+		schemaCache.schemas.Add(schema.GlobalID, cached)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
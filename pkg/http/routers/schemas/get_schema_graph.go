@@ -0,0 +1,47 @@
+package schemas
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+// getSchemaGraph builds the reference graph around every version the schema is registered as
+func getSchemaGraph(db *gorm.DB, limits configuration.LimitsConfiguration, id string, subjectName string, options graph.Options) (*graph.Graph, error) {
+	var response *graph.Graph
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		schema, err := getSchemaByGlobalID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		subjectVersions, err := getSubjectVersionsBySchemaID(tx, schema, subjectName, options.IncludeDeleted)
+		if err != nil {
+			return err
+		}
+
+		if len(subjectVersions) == 0 {
+			return routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema %s not found", id))
+		}
+
+		roots := make([]uuid.UUID, 0, len(subjectVersions))
+		for _, subjectVersion := range subjectVersions {
+			roots = append(roots, subjectVersion.ID)
+		}
+
+		response, err = graph.Build(tx, roots, options, limits)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
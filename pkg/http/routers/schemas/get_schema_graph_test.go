@@ -0,0 +1,75 @@
+package schemas
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetSchemaGraph(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	options := graph.Options{Direction: graph.DirectionBoth, MaxDepth: 5}
+
+	// try to get the graph on empty db
+	resp, err := getSchemaGraph(db, configuration.DefaultLimits(), "1", "", options)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+
+	// one is also registered under copy, two references one
+	err = db.Transaction(func(tx *gorm.DB) error {
+		one, err := insertSchemaVersion(tx, "one", 1, dbModels.SchemaTypeAvro, `{"type": "string"}`, nil)
+		if err != nil {
+			return err
+		}
+
+		copyVersion, err := insertSchemaVersion(tx, "copy", 1, dbModels.SchemaTypeAvro, `{"type": "long"}`, nil)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&dbModels.SubjectVersion{ID: uuid.New(), SubjectID: copyVersion.SubjectID, SchemaID: one.SchemaID, Version: 2}).Error; err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "two", 1, dbModels.SchemaTypeAvro, `{"type": "record", "name": "two", "fields": [{"name": "one", "type": "one"}]}`, map[string]*dbModels.SubjectVersion{"one": one})
+		return err
+	})
+	assert.NoError(t, err)
+
+	// every version of the schema is a root
+	resp, err = getSchemaGraph(db, configuration.DefaultLimits(), "1", "", options)
+	assert.NoError(t, err)
+	assert.Equal(t, []graph.Node{
+		{Key: "copy/versions/2", Subject: "copy", Version: 2, ID: 1, Direction: graph.DirectionRoot},
+		{Key: "one/versions/1", Subject: "one", Version: 1, ID: 1, Direction: graph.DirectionRoot},
+		{Key: "two/versions/1", Subject: "two", Version: 1, ID: 3, Direction: graph.DirectionDownstream, Depth: 1},
+	}, resp.Nodes)
+	assert.Equal(t, []graph.Edge{{From: "two/versions/1", To: "one/versions/1", Name: "one"}}, resp.Edges)
+
+	// only start from the versions of the subject
+	resp, err = getSchemaGraph(db, configuration.DefaultLimits(), "1", "copy", options)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Nodes, 1)
+
+	resp, err = getSchemaGraph(db, configuration.DefaultLimits(), "1", "two", options)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+}
@@ -0,0 +1,17 @@
+package schemas
+
+import (
+	"gorm.io/gorm"
+)
+
+func getSchemaSchema(db *gorm.DB, schemaCache *Cache, id string, subjectName string) (*ResponseGetSchemaSchema, error) {
+
+	resp, err := getSchema(db, schemaCache, id, subjectName)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := ResponseGetSchemaSchema(resp.Schema)
+
+	return &schema, nil
+}
@@ -0,0 +1,272 @@
+package schemas
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/ids"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newTestCache creates the caches of the schemas router, sizes of zero disable them
+func newTestCache(t *testing.T, config configuration.CacheConfiguration) *Cache {
+	schemaCache, err := NewCache(config)
+	assert.NoError(t, err)
+
+	return schemaCache
+}
+
+// insertSchemaVersion creates a schema with a single version under the subject, creating the subject if needed
+func insertSchemaVersion(tx *gorm.DB, subjectName string, version int32, schemaType dbModels.SchemaType, rawSchema string, references map[string]*dbModels.SubjectVersion) (*dbModels.SubjectVersion, error) {
+	subject := &dbModels.Subject{}
+	err := tx.Unscoped().Where("name = ?", subjectName).First(subject).Error
+	if err != nil {
+		subject = &dbModels.Subject{
+			ID:   uuid.New(),
+			Name: subjectName,
+		}
+		if err := tx.Create(subject).Error; err != nil {
+			return nil, fmt.Errorf("error creating subject: %w", err)
+		}
+	}
+
+	// without a db the allocator reserves in tx because sqlite doesn't allow multiple write transactions at once
+	globalID, err := ids.NewAllocator(nil, dbModels.SequenceNameSchemaIDs, configuration.Default().SchemaIDs).Next(tx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting next schema id: %w", err)
+	}
+
+	schema := &dbModels.Schema{
+		ID:         uuid.New(),
+		GlobalID:   globalID,
+		Schema:     rawSchema,
+		Hash:       fmt.Sprintf("%s-%d", subjectName, version),
+		SchemaType: schemaType,
+	}
+	if err := tx.Create(schema).Error; err != nil {
+		return nil, fmt.Errorf("error creating schema: %w", err)
+	}
+
+	for name, reference := range references {
+		schemaReference := &dbModels.SchemaReference{
+			ID:               uuid.New(),
+			SchemaID:         schema.ID,
+			SubjectVersionID: reference.ID,
+			Name:             name,
+		}
+		if err := tx.Create(schemaReference).Error; err != nil {
+			return nil, fmt.Errorf("error creating schema reference: %w", err)
+		}
+	}
+
+	subjectVersion := &dbModels.SubjectVersion{
+		ID:        uuid.New(),
+		SubjectID: subject.ID,
+		SchemaID:  schema.ID,
+		Version:   version,
+	}
+	if err := tx.Create(subjectVersion).Error; err != nil {
+		return nil, fmt.Errorf("error creating subject version: %w", err)
+	}
+
+	return subjectVersion, nil
+}
+
+func TestGetSchema(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	schemaCache := newTestCache(t, configuration.CacheConfiguration{})
+
+	// try to get schema on empty db
+	resp, err := getSchema(db, schemaCache, "1", "")
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// try to get schema with a bad id
+	resp, err = getSchema(db, schemaCache, "a", "")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		one, err := insertSchemaVersion(tx, "one", 1, dbModels.SchemaTypeAvro, `{"type": "string"}`, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "two", 1, dbModels.SchemaTypeJSON, `{"type": "object"}`, map[string]*dbModels.SubjectVersion{
+			"one": one,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+
+	// get avro schema
+	resp, err = getSchema(db, schemaCache, "1", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, `{"type": "string"}`, resp.Schema)
+	assert.Equal(t, schemas.SchemaType(""), resp.SchemaType)
+	assert.Empty(t, resp.References)
+
+	// get schema with references
+	resp, err = getSchema(db, schemaCache, "2", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, `{"type": "object"}`, resp.Schema)
+	assert.Equal(t, schemas.SchemaTypeJSON, resp.SchemaType)
+	assert.Equal(t, []SchemaReference{{Name: "one", Subject: "one", Version: 1}}, resp.References)
+
+	// get schema with subject
+	resp, err = getSchema(db, schemaCache, "1", "one")
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, `{"type": "string"}`, resp.Schema)
+
+	// get schema with a subject that it isn't registered under
+	resp, err = getSchema(db, schemaCache, "1", "two")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+
+	// get raw schema
+	schemaResp, err := getSchemaSchema(db, schemaCache, "2", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, schemaResp)
+	assert.Equal(t, ResponseGetSchemaSchema(`{"type": "object"}`), *schemaResp)
+
+	// soft delete the subject version
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&dbModels.SubjectVersion{}).Error
+	})
+	assert.NoError(t, err)
+
+	// schema by id is still returned
+	resp, err = getSchema(db, schemaCache, "1", "")
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	// but not when restricted to a subject
+	resp, err = getSchema(db, schemaCache, "1", "one")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+}
+
+func TestGetSchemaCached(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	schemaCache := newTestCache(t, configuration.CacheConfiguration{Schemas: 10})
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		one, err := insertSchemaVersion(tx, "one", 1, dbModels.SchemaTypeAvro, `{"type": "string"}`, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "two", 1, dbModels.SchemaTypeJSON, `{"type": "object"}`, map[string]*dbModels.SubjectVersion{
+			"one": one,
+		})
+		return err
+	})
+	assert.NoError(t, err)
+
+	resp, err := getSchema(db, schemaCache, "2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, schemaCache.schemas.Len())
+
+	// change the stored schema, which never happens, to make sure the cached one is returned
+	assert.NoError(t, db.Model(&dbModels.Schema{}).Where("global_id = ?", 2).Update("schema", `{"type": "array"}`).Error)
+
+	cachedResp, err := getSchema(db, schemaCache, "2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, resp, cachedResp)
+	assert.Equal(t, []SchemaReference{{Name: "one", Subject: "one", Version: 1}}, cachedResp.References)
+
+	// changing the response doesn't change the cache
+	cachedResp.References[0].Name = "changed"
+	cachedResp, err = getSchema(db, schemaCache, "2", "two")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type": "object"}`, cachedResp.Schema)
+	assert.Equal(t, "one", cachedResp.References[0].Name)
+
+	// the subject is still checked
+	_, err = getSchema(db, schemaCache, "2", "one")
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+}
+
+func TestInvalidateCache(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	schemaCache := newTestCache(t, configuration.CacheConfiguration{Schemas: 10})
+
+	subjectVersions := make([]*dbModels.SubjectVersion, 0)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, subjectName := range []string{"one", "two"} {
+			subjectVersion, err := insertSchemaVersion(tx, subjectName, 1, dbModels.SchemaTypeAvro, fmt.Sprintf(`{"type": "enum", "name": "%s", "symbols": ["A"]}`, subjectName), nil)
+			if err != nil {
+				return err
+			}
+			subjectVersions = append(subjectVersions, subjectVersion)
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	for _, globalID := range []string{"1", "2"} {
+		_, err := getSchema(db, schemaCache, globalID, "")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, schemaCache.schemas.Len())
+
+	// only deletes evict schemas
+	schemaCache.Invalidate(events.Event{Kind: events.KindSubjectVersion, Subject: "one", Versions: []int32{1}, SchemaIDs: []uuid.UUID{subjectVersions[0].SchemaID}})
+	assert.Equal(t, 2, schemaCache.schemas.Len())
+
+	schemaCache.Invalidate(events.Event{Kind: events.KindSubject, Subject: "one", Deleted: true, Versions: []int32{1}, SchemaIDs: []uuid.UUID{subjectVersions[0].SchemaID}})
+	assert.Equal(t, 1, schemaCache.schemas.Len())
+	_, ok := schemaCache.schemas.Get(2)
+	assert.True(t, ok)
+}
@@ -0,0 +1,15 @@
+package schemas
+
+import (
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+)
+
+func getSchemaTypes() *ResponseGetSchemaTypes {
+	schemaTypes := ResponseGetSchemaTypes{
+		schemas.SchemaTypeAvro,
+		schemas.SchemaTypeJSON,
+		schemas.SchemaTypeProtobuf,
+	}
+
+	return &schemaTypes
+}
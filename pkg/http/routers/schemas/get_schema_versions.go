@@ -0,0 +1,67 @@
+package schemas
+
+import (
+	"context"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"gorm.io/gorm"
+)
+
+func getSchemaVersions(db *gorm.DB, id string, subjectName string, includeDeleted bool) (*ResponseGetSchemaVersions, error) {
+	response := ResponseGetSchemaVersions{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		schema, err := getSchemaByGlobalID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		subjectVersions, err := getSubjectVersionsBySchemaID(tx, schema, subjectName, includeDeleted)
+		if err != nil {
+			return err
+		}
+
+		for _, subjectVersion := range subjectVersions {
+			response = append(response, SubjectVersion{
+				Subject: subjectVersion.Subject.Name,
+				Version: subjectVersion.Version,
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// filterSchemaVersions removes the versions of subjects the principal of the context can't read
+func filterSchemaVersions(ctx context.Context, authorizer *acl.Authorizer, versions *ResponseGetSchemaVersions) (*ResponseGetSchemaVersions, error) {
+	subjectNames := make([]string, 0, len(*versions))
+	for _, version := range *versions {
+		subjectNames = append(subjectNames, version.Subject)
+	}
+
+	readable, err := authorizer.FilterSubjects(ctx, dbModels.ACLOperationRead, subjectNames)
+	if err != nil {
+		return nil, err
+	}
+
+	readableSubjects := make(map[string]struct{}, len(readable))
+	for _, subjectName := range readable {
+		readableSubjects[subjectName] = struct{}{}
+	}
+
+	filtered := ResponseGetSchemaVersions{}
+	for _, version := range *versions {
+		if _, ok := readableSubjects[version.Subject]; ok {
+			filtered = append(filtered, version)
+		}
+	}
+
+	return &filtered, nil
+}
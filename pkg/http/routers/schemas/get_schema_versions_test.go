@@ -0,0 +1,87 @@
+package schemas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetSchemaVersions(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// try to get versions on empty db
+	resp, err := getSchemaVersions(db, "1", "", false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// same schema registered under two subjects
+	err = db.Transaction(func(tx *gorm.DB) error {
+		one, err := insertSchemaVersion(tx, "one", 1, dbModels.SchemaTypeAvro, `{"type": "string"}`, nil)
+		if err != nil {
+			return err
+		}
+
+		two, err := insertSchemaVersion(tx, "two", 1, dbModels.SchemaTypeAvro, `{"type": "long"}`, nil)
+		if err != nil {
+			return err
+		}
+
+		subjectVersion := &dbModels.SubjectVersion{
+			ID:        uuid.New(),
+			SubjectID: two.SubjectID,
+			SchemaID:  one.SchemaID,
+			Version:   2,
+		}
+		return tx.Create(subjectVersion).Error
+	})
+	assert.NoError(t, err)
+
+	resp, err = getSchemaVersions(db, "1", "", false)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, ResponseGetSchemaVersions{{Subject: "one", Version: 1}, {Subject: "two", Version: 2}}, *resp)
+
+	// filter by subject
+	resp, err = getSchemaVersions(db, "1", "two", false)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, ResponseGetSchemaVersions{{Subject: "two", Version: 2}}, *resp)
+
+	// soft delete version two
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return tx.Where("version = ?", 2).Delete(&dbModels.SubjectVersion{}).Error
+	})
+	assert.NoError(t, err)
+
+	resp, err = getSchemaVersions(db, "1", "", false)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, ResponseGetSchemaVersions{{Subject: "one", Version: 1}}, *resp)
+
+	// include deleted
+	resp, err = getSchemaVersions(db, "1", "", true)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, ResponseGetSchemaVersions{{Subject: "one", Version: 1}, {Subject: "two", Version: 2}}, *resp)
+}
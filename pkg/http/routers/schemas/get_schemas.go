@@ -0,0 +1,329 @@
+package schemas
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+type listOptions struct {
+	// subjectPrefix only lists schemas of subjects starting with the prefix
+	subjectPrefix  string
+	includeDeleted bool
+	// latestOnly only lists the schema of the latest version of each subject
+	latestOnly bool
+	// search only lists schemas with a type or field with the name, see schemas.HasName
+	search string
+	offset int
+	// limit is the maximum number of schemas returned, zero or less is unlimited
+	limit int
+	// readable scopes the schemas to the ones of subjects the principal can read before paging, nil is every subject
+	readable func(tx *gorm.DB) *gorm.DB
+}
+
+// parseListOptions reads the list options from the query parameters, invalid values are ignored
+func parseListOptions(request *http.Request) listOptions {
+	query := request.URL.Query()
+
+	options := listOptions{
+		subjectPrefix: query.Get("subjectPrefix"),
+		search:        query.Get("search"),
+	}
+	options.includeDeleted, _ = strconv.ParseBool(query.Get("deleted"))
+	options.latestOnly, _ = strconv.ParseBool(query.Get("latestOnly"))
+	options.offset, _ = strconv.Atoi(query.Get("offset"))
+	options.limit, _ = strconv.Atoi(query.Get("limit"))
+
+	if options.offset < 0 {
+		options.offset = 0
+	}
+
+	return options
+}
+
+// schemaVersion is a schema used by a subject version
+type schemaVersion struct {
+	SubjectName string
+	Version     int32
+	SchemaID    uuid.UUID
+	GlobalID    int32
+	SchemaType  dbModels.SchemaType
+	Schema      string
+}
+
+// searchBatchSize is how many versions a search loads and parses at a time
+const searchBatchSize = 100
+
+func getSchemas(db *gorm.DB, schemaCache *Cache, limits configuration.LimitsConfiguration, options listOptions) (*ResponseGetSchemas, error) {
+	response := ResponseGetSchemas{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Unscoped().Model(&dbModels.SubjectVersion{}).
+			Select("subjects.name AS subject_name, subject_versions.version, schemas.id AS schema_id, schemas.global_id, schemas.schema_type, schemas.schema").
+			Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
+			Joins("JOIN schemas ON schemas.id = subject_versions.schema_id")
+		query = routers.ScopeSubjectPrefix(query, options.subjectPrefix)
+		if options.readable != nil {
+			query = options.readable(query)
+		}
+
+		latestQuery := "SELECT MAX(latest.version) FROM subject_versions latest WHERE latest.subject_id = subject_versions.subject_id"
+		if !options.includeDeleted {
+			query = query.Where("subject_versions.deleted_at IS NULL AND subjects.deleted_at IS NULL")
+			latestQuery += " AND latest.deleted_at IS NULL"
+		}
+
+		if options.latestOnly {
+			query = query.Where(fmt.Sprintf("subject_versions.version = (%s)", latestQuery))
+		}
+		query = query.Order("subjects.name asc").Order("subject_versions.version asc")
+
+		var versions []schemaVersion
+		var err error
+		if len(options.search) > 0 {
+			// searching needs the schemas parsed so the page is taken while searching
+			versions, err = searchSchemaVersions(tx, schemaCache, query.Session(&gorm.Session{}), options, limits.MaxSearchScan)
+			if err != nil {
+				return err
+			}
+		} else {
+			if options.offset > 0 {
+				query = query.Offset(options.offset)
+			}
+			if options.limit > 0 {
+				query = query.Limit(options.limit)
+			}
+
+			versions = make([]schemaVersion, 0)
+			if err := query.Scan(&versions).Error; err != nil {
+				return fmt.Errorf("error listing schemas: %w", err)
+			}
+		}
+
+		schemaIDs := make([]uuid.UUID, 0, len(versions))
+		for _, version := range versions {
+			schemaIDs = append(schemaIDs, version.SchemaID)
+		}
+		references, err := getSchemasReferences(tx, schemaIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			subjectSchema := SubjectSchema{
+				Subject:    version.SubjectName,
+				Version:    version.Version,
+				ID:         version.GlobalID,
+				SchemaType: schemas.SchemaType(version.SchemaType),
+				References: references[version.SchemaID],
+				Schema:     version.Schema,
+			}
+
+			if subjectSchema.SchemaType == schemas.SchemaTypeAvro {
+				// set to empty string when avro for compatibility
+				subjectSchema.SchemaType = ""
+			}
+
+			response = append(response, subjectSchema)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// getSchemasReferences returns the references of each of the schemas in one query
+func getSchemasReferences(tx *gorm.DB, schemaIDs []uuid.UUID) (map[uuid.UUID][]SchemaReference, error) {
+	references := make(map[uuid.UUID][]SchemaReference, len(schemaIDs))
+	if len(schemaIDs) == 0 {
+		return references, nil
+	}
+
+	schemaReferences := make([]dbModels.SchemaReference, 0)
+	err := tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_schema_id")).
+		Joins("SubjectVersion").Joins("SubjectVersion.Subject").
+		Where("schema_references.schema_id IN ?", schemaIDs).
+		Order("schema_references.name asc").
+		Find(&schemaReferences).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding references of schemas: %w", err)
+	}
+
+	for _, schemaReference := range schemaReferences {
+		references[schemaReference.SchemaID] = append(references[schemaReference.SchemaID], SchemaReference{
+			Name:    schemaReference.Name,
+			Subject: schemaReference.SubjectVersion.Subject.Name,
+			Version: schemaReference.SubjectVersion.Version,
+		})
+	}
+
+	return references, nil
+}
+
+// searchSchemaVersions returns the page of versions whose schema has a type or field with the name, versions are
+// loaded and parsed a batch at a time until the page is full. It fails after parsing maxScan versions, zero is unlimited
+func searchSchemaVersions(tx *gorm.DB, schemaCache *Cache, query *gorm.DB, options listOptions, maxScan int) ([]schemaVersion, error) {
+	found := make([]schemaVersion, 0)
+	skip := options.offset
+
+	for scanned := 0; ; {
+		batchSize := searchBatchSize
+		if maxScan > 0 && maxScan-scanned < batchSize {
+			// one more than what's left to know if there are more versions than the limit
+			batchSize = maxScan - scanned + 1
+		}
+
+		versions := make([]schemaVersion, 0, batchSize)
+		err := query.Offset(scanned).Limit(batchSize).Scan(&versions).Error
+		if err != nil {
+			return nil, fmt.Errorf("error listing schemas: %w", err)
+		}
+
+		hitLimit := maxScan > 0 && scanned+len(versions) > maxScan
+		if hitLimit {
+			versions = versions[:maxScan-scanned]
+		}
+		scanned += len(versions)
+
+		names, err := getSchemaNames(tx, schemaCache, versions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range versions {
+			if !schemas.HasName(names[version.SchemaID], options.search) {
+				continue
+			}
+
+			if skip > 0 {
+				skip--
+				continue
+			}
+
+			found = append(found, version)
+			if options.limit > 0 && len(found) >= options.limit {
+				return found, nil
+			}
+		}
+
+		if hitLimit {
+			return nil, routers.NewAPIError(http.StatusConflict, 40902, fmt.Errorf("hit schema search limit, more than %d schemas have to be searched, narrow the search with subjectPrefix or latestOnly", maxScan))
+		}
+
+		if len(versions) < batchSize {
+			return found, nil
+		}
+	}
+}
+
+// getSchemaNames returns the names of the types and fields of the schemas of the versions, they're cached as
+// schemas never change. The references of the schemas that aren't cached are loaded together
+func getSchemaNames(tx *gorm.DB, schemaCache *Cache, versions []schemaVersion) (map[uuid.UUID][]string, error) {
+	names := make(map[uuid.UUID][]string, len(versions))
+	uncached := make([]schemaVersion, 0)
+	for _, version := range versions {
+		if _, ok := names[version.SchemaID]; ok {
+			continue
+		}
+
+		if cachedNames, ok := schemaCache.names.Get(version.SchemaID); ok {
+			names[version.SchemaID] = cachedNames
+			continue
+		}
+
+		names[version.SchemaID] = nil
+		uncached = append(uncached, version)
+	}
+
+	if len(uncached) == 0 {
+		return names, nil
+	}
+
+	schemaIDs := make([]uuid.UUID, 0, len(uncached))
+	for _, version := range uncached {
+		schemaIDs = append(schemaIDs, version.SchemaID)
+	}
+	referenceGraph, err := getSchemaReferenceGraph(tx, schemaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range uncached {
+		references := make([]string, 0)
+		referenceNames := make([]string, 0)
+		collectSchemaReferences(referenceGraph, version.SchemaID, make(map[uuid.UUID]struct{}), &references, &referenceNames)
+
+		parsedSchema, err := schemas.ParseSchema(version.Schema, schemas.SchemaType(version.SchemaType), references, referenceNames)
+		if err != nil {
+			return nil, routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error parsing existing: %w", err))
+		}
+
+		names[version.SchemaID] = parsedSchema.Names()
+		schemaCache.names.Add(version.SchemaID, names[version.SchemaID])
+	}
+
+	return names, nil
+}
+
+// getSchemaReferenceGraph returns the references of the schemas and of every schema they reference keyed by the
+// referencing schema, the references of all the schemas at the same depth are loaded in one query
+func getSchemaReferenceGraph(tx *gorm.DB, schemaIDs []uuid.UUID) (map[uuid.UUID][]dbModels.SchemaReference, error) {
+	referenceGraph := make(map[uuid.UUID][]dbModels.SchemaReference)
+	loaded := make(map[uuid.UUID]struct{}, len(schemaIDs))
+	for _, schemaID := range schemaIDs {
+		loaded[schemaID] = struct{}{}
+	}
+
+	for len(schemaIDs) > 0 {
+		schemaReferences := make([]dbModels.SchemaReference, 0)
+		err := tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_schema_id")).
+			Joins("SubjectVersion").Joins("SubjectVersion.Schema").
+			Where("schema_references.schema_id IN ?", schemaIDs).
+			Find(&schemaReferences).Error
+		if err != nil {
+			return nil, fmt.Errorf("error finding references of schemas: %w", err)
+		}
+
+		schemaIDs = make([]uuid.UUID, 0)
+		for _, schemaReference := range schemaReferences {
+			referenceGraph[schemaReference.SchemaID] = append(referenceGraph[schemaReference.SchemaID], schemaReference)
+
+			referencedSchemaID := schemaReference.SubjectVersion.Schema.ID
+			if _, ok := loaded[referencedSchemaID]; !ok {
+				loaded[referencedSchemaID] = struct{}{}
+				schemaIDs = append(schemaIDs, referencedSchemaID)
+			}
+		}
+	}
+
+	return referenceGraph, nil
+}
+
+// collectSchemaReferences adds the schemas referenced by the schema, referenced schemas come before the ones
+// referencing them so they can be parsed in order. References only go to earlier schemas so there are no cycles
+func collectSchemaReferences(referenceGraph map[uuid.UUID][]dbModels.SchemaReference, schemaID uuid.UUID, seen map[uuid.UUID]struct{}, references *[]string, referenceNames *[]string) {
+	for _, schemaReference := range referenceGraph[schemaID] {
+		referencedSchema := schemaReference.SubjectVersion.Schema
+		if _, ok := seen[referencedSchema.ID]; ok {
+			continue
+		}
+		seen[referencedSchema.ID] = struct{}{}
+
+		collectSchemaReferences(referenceGraph, referencedSchema.ID, seen, references, referenceNames)
+
+		*references = append(*references, referencedSchema.Schema)
+		*referenceNames = append(*referenceNames, schemaReference.Name)
+	}
+}
@@ -0,0 +1,165 @@
+package schemas
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetSchemas(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	schemaCache := newTestCache(t, configuration.CacheConfiguration{})
+
+	// get schemas on empty db
+	resp, err := getSchemas(db, schemaCache, configuration.DefaultLimits(), listOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, *resp)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		address, err := insertSchemaVersion(tx, "address", 1, dbModels.SchemaTypeAvro,
+			`{"type": "record", "name": "Address", "fields": [{"name": "street", "type": "string"}]}`, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "customer", 1, dbModels.SchemaTypeAvro,
+			`{"type": "record", "name": "Customer", "fields": [{"name": "name", "type": "string"}]}`, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "customer", 2, dbModels.SchemaTypeAvro,
+			`{"type": "record", "name": "Customer", "fields": [{"name": "name", "type": "string"}, {"name": "customer_email", "type": "string", "default": ""}, {"name": "address", "type": "Address"}]}`,
+			map[string]*dbModels.SubjectVersion{"Address": address})
+		if err != nil {
+			return err
+		}
+
+		_, err = insertSchemaVersion(tx, "orders", 1, dbModels.SchemaTypeJSON,
+			`{"type": "object", "properties": {"customer": {"type": "object", "properties": {"customer_email": {"type": "string"}}}}}`, nil)
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("version = ?", 1).Where("schema_id IN (?)", tx.Model(&dbModels.Schema{}).Select("id").Where("global_id = ?", 2)).
+			Delete(&dbModels.SubjectVersion{}).Error
+	})
+	assert.NoError(t, err)
+
+	type subjectVersion struct {
+		subject string
+		version int32
+	}
+
+	tests := []struct {
+		name     string
+		options  listOptions
+		expected []subjectVersion
+	}{
+		{"all", listOptions{}, []subjectVersion{{"address", 1}, {"customer", 2}, {"orders", 1}}},
+		{"deleted", listOptions{includeDeleted: true}, []subjectVersion{{"address", 1}, {"customer", 1}, {"customer", 2}, {"orders", 1}}},
+		{"prefix", listOptions{subjectPrefix: "cust", includeDeleted: true}, []subjectVersion{{"customer", 1}, {"customer", 2}}},
+		{"latest only", listOptions{latestOnly: true, includeDeleted: true}, []subjectVersion{{"address", 1}, {"customer", 2}, {"orders", 1}}},
+		{"page", listOptions{offset: 1, limit: 1}, []subjectVersion{{"customer", 2}}},
+		{"search field", listOptions{search: "customer_email"}, []subjectVersion{{"customer", 2}, {"orders", 1}}},
+		{"search path", listOptions{search: "customer.customer_email"}, []subjectVersion{{"orders", 1}}},
+		{"search referenced field", listOptions{search: "address.street"}, []subjectVersion{{"customer", 2}}},
+		{"search record", listOptions{search: "Address"}, []subjectVersion{{"address", 1}, {"customer", 2}}},
+		{"search page", listOptions{search: "customer_email", offset: 1, limit: 5}, []subjectVersion{{"orders", 1}}},
+		{"search no match", listOptions{search: "missing"}, []subjectVersion{}},
+		// pages only have schemas of readable subjects
+		{"readable page", listOptions{limit: 2, readable: func(tx *gorm.DB) *gorm.DB { return tx.Where("subjects.name <> ?", "address") }}, []subjectVersion{{"customer", 2}, {"orders", 1}}},
+		{"readable search page", listOptions{search: "Address", limit: 1, readable: func(tx *gorm.DB) *gorm.DB { return tx.Where("subjects.name <> ?", "address") }}, []subjectVersion{{"customer", 2}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := getSchemas(db, schemaCache, configuration.DefaultLimits(), test.options)
+			assert.NoError(t, err)
+
+			versions := make([]subjectVersion, 0)
+			for _, subjectSchema := range *resp {
+				versions = append(versions, subjectVersion{subjectSchema.Subject, subjectSchema.Version})
+			}
+			assert.Equal(t, test.expected, versions)
+		})
+	}
+
+	// searches stop once the page is full and fail when they'd parse more than the limit
+	resp, err = getSchemas(db, schemaCache, configuration.LimitsConfiguration{MaxSearchScan: 2}, listOptions{search: "customer_email", limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, *resp, 1)
+
+	_, err = getSchemas(db, schemaCache, configuration.LimitsConfiguration{MaxSearchScan: 2}, listOptions{search: "customer_email"})
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 40902, apiError.ErrorCode)
+
+	_, err = getSchemas(db, schemaCache, configuration.LimitsConfiguration{MaxSearchScan: 3}, listOptions{search: "customer_email"})
+	assert.NoError(t, err)
+
+	// schemas come with their references
+	resp, err = getSchemas(db, schemaCache, configuration.DefaultLimits(), listOptions{subjectPrefix: "customer", latestOnly: true})
+	assert.NoError(t, err)
+	assert.Len(t, *resp, 1)
+	assert.Equal(t, int32(3), (*resp)[0].ID)
+	assert.Equal(t, []SchemaReference{{Name: "Address", Subject: "address", Version: 1}}, (*resp)[0].References)
+}
+
+func TestGetSchemasSearchBatches(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	schemaCache := newTestCache(t, configuration.CacheConfiguration{})
+
+	// every tenth subject has the field, there are more subjects than a batch
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for index := 0; index < searchBatchSize*2+50; index++ {
+			field := "name"
+			if index%10 == 0 {
+				field = "customer_email"
+			}
+
+			_, err := insertSchemaVersion(tx, fmt.Sprintf("subject-%03d", index), 1, dbModels.SchemaTypeAvro,
+				fmt.Sprintf(`{"type": "record", "name": "Record%d", "fields": [{"name": "%s", "type": "string"}]}`, index, field), nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	resp, err := getSchemas(db, schemaCache, configuration.DefaultLimits(), listOptions{search: "customer_email", offset: 8, limit: 4})
+	assert.NoError(t, err)
+
+	subjects := make([]string, 0)
+	for _, subjectSchema := range *resp {
+		subjects = append(subjects, subjectSchema.Subject)
+	}
+	assert.Equal(t, []string{"subject-080", "subject-090", "subject-100", "subject-110"}, subjects)
+
+	resp, err = getSchemas(db, schemaCache, configuration.DefaultLimits(), listOptions{search: "customer_email"})
+	assert.NoError(t, err)
+	assert.Len(t, *resp, 25)
+}
@@ -0,0 +1,62 @@
+package schemas
+
+import (
+	"net/http"
+
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+)
+
+type ResponseGetSchemaTypes []schemas.SchemaType
+
+func (r ResponseGetSchemaTypes) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type SchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int32  `json:"version"`
+}
+
+type ResponseGetSchema struct {
+	Schema     string             `json:"schema"`
+	SchemaType schemas.SchemaType `json:"schemaType,omitempty"`
+	References []SchemaReference  `json:"references,omitempty"`
+}
+
+func (r *ResponseGetSchema) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type ResponseGetSchemaSchema string
+
+func (r ResponseGetSchemaSchema) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type SubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int32  `json:"version"`
+}
+
+type ResponseGetSchemaVersions []SubjectVersion
+
+func (r ResponseGetSchemaVersions) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+// SubjectSchema is the schema of a subject version
+type SubjectSchema struct {
+	Subject    string             `json:"subject"`
+	Version    int32              `json:"version"`
+	ID         int32              `json:"id"`
+	SchemaType schemas.SchemaType `json:"schemaType,omitempty"`
+	References []SchemaReference  `json:"references,omitempty"`
+	Schema     string             `json:"schema"`
+}
+
+type ResponseGetSchemas []SubjectSchema
+
+func (r ResponseGetSchemas) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
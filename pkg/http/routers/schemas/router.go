@@ -1,32 +1,149 @@
 package schemas
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
 )
 
-func NewRouter() *chi.Mux {
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer, schemaCache *Cache, limits configuration.LimitsConfiguration) *chi.Mux {
 	chiRouter := chi.NewRouter()
 
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--schemas
+	chiRouter.Get("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		// subjectPrefix, deleted, latestOnly, search, offset and limit
+		options := parseListOptions(request)
+
+		var v render.Renderer
+		// only list the schemas of subjects the principal can read
+		var err error
+		options.readable, err = authorizer.ScopeSubjects(request.Context(), dbModels.ACLOperationRead)
+		if err == nil {
+			v, err = getSchemas(db, schemaCache, limits, options)
+		}
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing schemas: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--schemas-types-
 	chiRouter.Get("/types", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
 
+		render.Render(writer, request, getSchemaTypes())
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--schemas-ids-int-%20id
-	chiRouter.Get("/ids/{id}", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Schema(dbModels.ACLOperationRead)).Get("/ids/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		id := chi.URLParam(request, "id")
+
+		// Only return the schema if it is registered under this subject
+		subjectName := request.URL.Query().Get("subject")
 
+		var v render.Renderer
+		v, err := getSchema(db, schemaCache, id, subjectName)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting schema: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--schemas-ids-int-%20id-schema
-	chiRouter.Get("/ids/{id}/schema", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Schema(dbModels.ACLOperationRead)).Get("/ids/{id}/schema", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		id := chi.URLParam(request, "id")
+
+		// Only return the schema if it is registered under this subject
+		subjectName := request.URL.Query().Get("subject")
 
+		var v render.Renderer
+		v, err := getSchemaSchema(db, schemaCache, id, subjectName)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting schema: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--schemas-ids-int-%20id-versions
 	chiRouter.Get("/ids/{id}/versions", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		id := chi.URLParam(request, "id")
+
+		// Only return versions for this subject
+		subjectName := request.URL.Query().Get("subject")
+
+		// Whether to included soft deleted versions
+		deletedRaw := request.URL.Query().Get("deleted")
+		deleted, _ := strconv.ParseBool(deletedRaw)
+
+		var v render.Renderer
+		versions, err := getSchemaVersions(db, id, subjectName, deleted)
+		if err == nil {
+			// only list the versions of subjects the principal can read
+			versions, err = filterSchemaVersions(request.Context(), authorizer, versions)
+			v = versions
+		}
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing schema versions: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// the transitive reference graph around the versions a schema is registered as, as json or graphviz dot
+	chiRouter.With(authorizer.Schema(dbModels.ACLOperationRead)).Get("/ids/{id}/graph", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		id := chi.URLParam(request, "id")
+
+		// Only start from the versions of this subject
+		subjectName := request.URL.Query().Get("subject")
+
+		// direction, depth, deleted and format
+		options := graph.ParseOptions(request, limits)
+
+		var v render.Renderer
+		schemaGraph, err := getSchemaGraph(db, limits, id, subjectName, options)
+		if err == nil {
+			// only include the versions of subjects the principal can read
+			var readable []string
+			readable, err = authorizer.FilterSubjects(request.Context(), dbModels.ACLOperationRead, schemaGraph.Subjects())
+			v = schemaGraph.Filter(readable)
+		}
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting schema graph: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
 
+		graph.Respond(writer, request, options, v)
 	})
 
 	return chiRouter
@@ -0,0 +1,118 @@
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+// parseGlobalID returns the global id of a schema, invalid ids are 0 which no schema has
+func parseGlobalID(id string) int32 {
+	globalID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int32(globalID)
+}
+
+// getCachedSchema returns the schema with the global id when it's in the cache
+func getCachedSchema(schemaCache *Cache, id string) (*cachedSchema, bool) {
+	globalID := parseGlobalID(id)
+	if globalID == 0 {
+		return nil, false
+	}
+
+	return schemaCache.schemas.Get(globalID)
+}
+
+func getSchemaByGlobalID(tx *gorm.DB, id string) (*dbModels.Schema, error) {
+	globalID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return nil, routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema %s not found", id))
+	}
+
+	schema := &dbModels.Schema{}
+	err = tx.Clauses(dbModels.ForceIndexHint("idx_schemas_global_id")).Where("global_id = ?", globalID).First(schema).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema %s not found", id))
+		}
+		return nil, fmt.Errorf("error finding schema %s: %w", id, err)
+	}
+
+	return schema, nil
+}
+
+func getSubjectVersionsBySchemaID(tx *gorm.DB, schema *dbModels.Schema, subjectName string, includeDeleted bool) ([]dbModels.SubjectVersion, error) {
+	subjectVersions := make([]dbModels.SubjectVersion, 0)
+
+	query := tx.Clauses(dbModels.ForceIndexHint("idx_subject_id_schema_id")).Joins("Subject").
+		Where("subject_versions.schema_id = ?", schema.ID)
+	if len(subjectName) > 0 {
+		query = query.Where("\"Subject\".\"name\" = ?", subjectName)
+	}
+
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+
+	err := query.Order("\"Subject\".\"name\" asc").Order("subject_versions.version asc").Find(&subjectVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding versions for schema %d: %w", schema.GlobalID, err)
+	}
+
+	return subjectVersions, nil
+}
+
+// getSchemaForSubject gets a schema by its global id, when a subject name is given
+// the schema must also be registered under that subject
+func getSchemaForSubject(tx *gorm.DB, id string, subjectName string) (*dbModels.Schema, error) {
+	schema, err := getSchemaByGlobalID(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subjectName) > 0 {
+		subjectVersions, err := getSubjectVersionsBySchemaID(tx, schema, subjectName, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(subjectVersions) == 0 {
+			return nil, routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema %s not found", id))
+		}
+	}
+
+	return schema, nil
+}
+
+// getSchemaReferences returns the direct references of the schema, the same as what was given when it was registered.
+// References to soft deleted versions are still returned as the schema still depends on them
+func getSchemaReferences(tx *gorm.DB, schema *dbModels.Schema) ([]SchemaReference, error) {
+	schemaReferences := make([]dbModels.SchemaReference, 0)
+	err := tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_schema_id")).
+		Joins("SubjectVersion").Joins("SubjectVersion.Subject").
+		Where("schema_references.schema_id = ?", schema.ID).
+		Order("schema_references.name asc").
+		Find(&schemaReferences).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding references for schema %d: %w", schema.GlobalID, err)
+	}
+
+	var references []SchemaReference
+	for _, schemaReference := range schemaReferences {
+		references = append(references, SchemaReference{
+			Name:    schemaReference.Name,
+			Subject: schemaReference.SubjectVersion.Subject.Name,
+			Version: schemaReference.SubjectVersion.Version,
+		})
+	}
+
+	return references, nil
+}
@@ -0,0 +1,51 @@
+package routers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubjectParam returns the subject url parameter, subjects qualified with the default context are unqualified
+// so they're the same subject as without it
+func SubjectParam(request *http.Request) string {
+	return dbModels.NormalizeSubjectName(chi.URLParam(request, "subject"))
+}
+
+// likePrefixReplacer escapes the LIKE wildcards in a prefix
+var likePrefixReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// globPrefixReplacer escapes the GLOB wildcards in a prefix
+var globPrefixReplacer = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+
+// ScopeSubjectPrefix only includes subjects starting with the prefix in the query, the subjects table has to be in it.
+// Subjects are from the context of the prefix and the context wildcard includes subjects from every context
+func ScopeSubjectPrefix(tx *gorm.DB, subjectPrefix string) *gorm.DB {
+	if subjectPrefix == dbModels.ContextWildcard {
+		return tx
+	}
+
+	subjectPrefix = dbModels.NormalizeSubjectName(subjectPrefix)
+	tx = tx.Where("subjects.context = ?", dbModels.SubjectContextColumn(subjectPrefix))
+
+	if len(subjectPrefix) > 0 {
+		tx = tx.Where(SubjectNamePrefix(tx, subjectPrefix))
+	}
+
+	return tx
+}
+
+// SubjectNamePrefix is the condition of subject names starting with the prefix, the subjects table has to be in the query
+func SubjectNamePrefix(tx *gorm.DB, prefix string) clause.Expr {
+	// the prefix has to match byte for byte, postgres compares text with the database collation so the name
+	// is compared with the C collation and sqlite's LIKE ignores case so GLOB is used there instead
+	if tx.Dialector.Name() == "sqlite" {
+		return gorm.Expr("subjects.name GLOB ?", globPrefixReplacer.Replace(prefix)+"*")
+	}
+
+	return gorm.Expr(`subjects.name COLLATE "C" LIKE ? ESCAPE '\'`, likePrefixReplacer.Replace(prefix)+"%")
+}
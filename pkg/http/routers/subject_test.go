@@ -0,0 +1,64 @@
+package routers
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var errRollback = errors.New("rollback")
+
+func testScopeSubjectPrefix(t *testing.T, db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range []string{"orders-key", "Orders-key", "orders_key", "ordersXkey", "orders%", "ordersX", "orders*", "orders[x]", "ordérs", "ordz", ":.team-a:orders"} {
+			assert.NoError(t, tx.Create(&dbModels.Subject{ID: uuid.New(), Name: name, Context: dbModels.SubjectContextColumn(name)}).Error)
+		}
+
+		tests := []struct {
+			prefix   string
+			expected []string
+		}{
+			{"orders-", []string{"orders-key"}},
+			{"Orders", []string{"Orders-key"}},
+			{"orders_", []string{"orders_key"}},
+			{"orders%", []string{"orders%"}},
+			{"orders*", []string{"orders*"}},
+			{"orders[", []string{"orders[x]"}},
+			{"ord", []string{"orders%", "orders*", "orders-key", "ordersX", "ordersXkey", "orders[x]", "orders_key", "ordz", "ordérs"}},
+			{"ordé", []string{"ordérs"}},
+			{":.team-a:ord", []string{":.team-a:orders"}},
+		}
+
+		for _, test := range tests {
+			var names []string
+			err := ScopeSubjectPrefix(tx.Model(&dbModels.Subject{}), test.prefix).Order("name").Pluck("name", &names).Error
+			assert.NoError(t, err, test.prefix)
+			assert.ElementsMatch(t, test.expected, names, test.prefix)
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+}
+
+func TestScopeSubjectPrefix(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	testScopeSubjectPrefix(t, db)
+}
+
+func TestScopeSubjectPrefixPostgres(t *testing.T) {
+	testScopeSubjectPrefix(t, testdb.PostgresDatabase(t))
+}
@@ -0,0 +1,16 @@
+package subjects
+
+import (
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+)
+
+// InvalidateCache evicts the parsed schemas of the versions the event deleted
+func (r *Registry) InvalidateCache(event events.Event) {
+	if !event.Deleted {
+		return
+	}
+
+	for _, schemaID := range event.SchemaIDs {
+		r.parsedSchemaCache.Remove(schemaID)
+	}
+}
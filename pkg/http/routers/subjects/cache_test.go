@@ -0,0 +1,143 @@
+package subjects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSubjectVersionSchemaCached(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	cfg := configuration.Default()
+	cfg.Cache.ParsedSchemas = 10
+	registry := newTestRegistry(t, db, cfg)
+
+	for index, subjectName := range []string{"one", "two"} {
+		requestPostSubject := &RequestPostSubjectVersion{
+			Schema: fmt.Sprintf(`{"type": "record", "name": "test", "fields": [{"name": "field%d", "type": "string"}]}`, index),
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, subjectName, requestPostSubject)
+		assert.NoError(t, err)
+	}
+
+	subjectVersions := make([]dbModels.SubjectVersion, 0)
+	assert.NoError(t, db.Joins("Schema").Order("\"Schema\".\"global_id\"").Find(&subjectVersions).Error)
+	assert.Len(t, subjectVersions, 2)
+
+	parsedSchema, err := parseSubjectVersionSchema(db, registry, subjectVersions[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, registry.parsedSchemaCache.Len())
+
+	// the cached schema is returned even though the stored schema no longer parses
+	subjectVersions[0].Schema.Schema = "not a schema"
+	cachedParsedSchema, err := parseSubjectVersionSchema(db, registry, subjectVersions[0])
+	assert.NoError(t, err)
+	assert.Same(t, parsedSchema, cachedParsedSchema)
+
+	subjectVersions[1].Schema.Schema = "not a schema"
+	_, err = parseSubjectVersionSchema(db, registry, subjectVersions[1])
+	assert.Error(t, err)
+	assert.Equal(t, 1, registry.parsedSchemaCache.Len())
+}
+
+func TestInvalidateCache(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	cfg := configuration.Default()
+	cfg.Cache.ParsedSchemas = 10
+	registry := newTestRegistry(t, db, cfg)
+
+	for _, subjectName := range []string{"one", "two"} {
+		requestPostSubject := &RequestPostSubjectVersion{
+			Schema: fmt.Sprintf(`{"type": "record", "name": "%s", "fields": [{"name": "field", "type": "string"}]}`, subjectName),
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, subjectName, requestPostSubject)
+		assert.NoError(t, err)
+	}
+
+	subjectVersions := make([]dbModels.SubjectVersion, 0)
+	assert.NoError(t, db.Joins("Schema").Order("\"Schema\".\"global_id\"").Find(&subjectVersions).Error)
+	assert.Len(t, subjectVersions, 2)
+
+	for _, subjectVersion := range subjectVersions {
+		_, err := parseSubjectVersionSchema(db, registry, subjectVersion)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, registry.parsedSchemaCache.Len())
+
+	// registering a version doesn't evict anything
+	registry.InvalidateCache(events.Event{Kind: events.KindSubjectVersion, Subject: "one", Versions: []int32{1}, SchemaIDs: []uuid.UUID{subjectVersions[0].SchemaID}})
+	assert.Equal(t, 2, registry.parsedSchemaCache.Len())
+
+	// deleting a version evicts its schema
+	_, err := deleteSubjectVersion(db, registry, "one", "1", false)
+	assert.NoError(t, err)
+	registry.InvalidateCache(events.Event{Kind: events.KindSubjectVersion, Subject: "one", Deleted: true, Versions: []int32{1}, SchemaIDs: []uuid.UUID{subjectVersions[0].SchemaID}})
+	assert.Equal(t, 1, registry.parsedSchemaCache.Len())
+	_, ok := registry.parsedSchemaCache.Get(subjectVersions[1].SchemaID)
+	assert.True(t, ok)
+}
+
+func TestDeletePublishesEvents(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	for index := 0; index < 2; index++ {
+		requestPostSubject := &RequestPostSubjectVersion{
+			Schema: fmt.Sprintf(`{"type": "record", "name": "test", "fields": [{"name": "field%d", "type": "string", "default": ""}]}`, index),
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+		assert.NoError(t, err)
+	}
+
+	_, err := deleteSubject(db, registry, "one", false)
+	assert.NoError(t, err)
+
+	outboxEvents := make([]dbModels.Event, 0)
+	assert.NoError(t, db.Order("created_at").Find(&outboxEvents).Error)
+	assert.Len(t, outboxEvents, 3)
+
+	subjectVersions := make([]dbModels.SubjectVersion, 0)
+	assert.NoError(t, db.Unscoped().Order("version").Find(&subjectVersions).Error)
+	assert.Len(t, subjectVersions, 2)
+
+	event := events.Event{}
+	assert.NoError(t, json.Unmarshal([]byte(outboxEvents[2].Payload), &event))
+	assert.Equal(t, events.KindSubject, event.Kind)
+	assert.Equal(t, "one", event.Subject)
+	assert.True(t, event.Deleted)
+	assert.False(t, event.Permanent)
+	assert.ElementsMatch(t, []int32{1, 2}, event.Versions)
+	assert.ElementsMatch(t, []uuid.UUID{subjectVersions[0].SchemaID, subjectVersions[1].SchemaID}, event.SchemaIDs)
+}
@@ -0,0 +1,137 @@
+package subjects
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+// getSubjectVersionsForCompatibility returns the versions of the subject that a new schema needs to be checked against
+func getSubjectVersionsForCompatibility(tx *gorm.DB, subjectID uuid.UUID, compatibility dbModels.SubjectCompatibility) ([]dbModels.SubjectVersion, error) {
+	existingSchemaVersions := make([]dbModels.SubjectVersion, 0)
+	query := tx.Clauses(dbModels.ForceIndexHint("idx_subject_versions_subject_id")).Joins("Schema").
+		Where("subject_versions.subject_id = ?", subjectID).Order("subject_versions.version desc")
+
+	// check if we are transitive
+	if !strings.HasSuffix(string(compatibility), "_TRANSITIVE") {
+		// not transitive so we only need the first one
+		query = query.Limit(1)
+	} else {
+		// we are transitive, this is most likely a very expensive operation, so it's probably not a good idea to do
+		// this query could return tons of rows and require tons of comparisons
+		// we probably could limit this impact by having a configurable maximum versions per subject
+		query = query.Limit(-1)
+	}
+
+	err := query.Find(&existingSchemaVersions).Error
+	if err != nil {
+		return nil, fmt.Errorf("error finding existing schemas for compatibility checking: %w", err)
+	}
+
+	return existingSchemaVersions, nil
+}
+
+// parseSubjectVersionSchema parses the schema of an existing subject version along with its references
+// parsed schemas are cached as neither a schema nor the subject versions it references change once created
+func parseSubjectVersionSchema(tx *gorm.DB, registry *Registry, subjectVersion dbModels.SubjectVersion) (schemas.ParsedSchema, error) {
+	if parsedSchema, ok := registry.parsedSchemaCache.Get(subjectVersion.Schema.ID); ok {
+		return parsedSchema, nil
+	}
+
+	references := make([]string, 0)
+	referenceNames := make([]string, 0)
+
+	// if it exists it means the original schema passed recursion validation
+	// so let's set it to -1 to offset any weirdness
+	schemaReferences, err := getSchemaReferencesReferencedBySchemaID(tx, registry, subjectVersion.Schema.ID, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schemaReference := range schemaReferences {
+		references = append(references, schemaReference.SubjectVersion.Schema.Schema)
+		referenceNames = append(referenceNames, schemaReference.Name)
+	}
+
+	parsedSchema, err := schemas.ParseSchema(subjectVersion.Schema.Schema, schemas.SchemaType(subjectVersion.Schema.SchemaType), references, referenceNames)
+	if err != nil {
+		return nil, routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error parsing existing: %w", err))
+	}
+
+	registry.parsedSchemaCache.Add(subjectVersion.Schema.ID, parsedSchema)
+
+	return parsedSchema, nil
+}
+
+// checkCompatibility checks the parsed schema against the existing subject versions
+// returning the reasons why it isn't compatible, if there are none the schema is compatible
+func checkCompatibility(tx *gorm.DB, registry *Registry, compatibility dbModels.SubjectCompatibility, schemaType schemas.SchemaType, parsedSchema schemas.ParsedSchema, existingSchemaVersions []dbModels.SubjectVersion) ([]string, error) {
+	reasons := make([]string, 0)
+
+	if compatibility == dbModels.SubjectCompatibilityNone {
+		return reasons, nil
+	}
+
+	timer := metrics.NewCompatibilityCheckTimer(string(schemaType))
+	defer timer.ObserveDuration()
+
+	for _, existingSchemaVersion := range existingSchemaVersions {
+		if schemas.SchemaType(existingSchemaVersion.Schema.SchemaType) != schemaType {
+			reasons = append(reasons, fmt.Sprintf("version %d: schema type changed from %s to %s", existingSchemaVersion.Version, existingSchemaVersion.Schema.SchemaType, schemaType))
+			continue
+		}
+
+		existingParsedSchema, err := parseSubjectVersionSchema(tx, registry, existingSchemaVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		checks := make([]func() (bool, []string, error), 0)
+		switch compatibility {
+		case dbModels.SubjectCompatibilityBackward, dbModels.SubjectCompatibilityBackwardTransitive:
+			checks = append(checks, func() (bool, []string, error) {
+				return parsedSchema.IsBackwardsCompatible(existingParsedSchema)
+			})
+		case dbModels.SubjectCompatibilityForward, dbModels.SubjectCompatibilityForwardTransitive:
+			checks = append(checks, func() (bool, []string, error) {
+				return existingParsedSchema.IsBackwardsCompatible(parsedSchema)
+			})
+		case dbModels.SubjectCompatibilityFull, dbModels.SubjectCompatibilityFullTransitive:
+			checks = append(checks, func() (bool, []string, error) {
+				return parsedSchema.IsBackwardsCompatible(existingParsedSchema)
+			}, func() (bool, []string, error) {
+				return existingParsedSchema.IsBackwardsCompatible(parsedSchema)
+			})
+		default:
+			return nil, fmt.Errorf("unknown compatibility: %s", compatibility)
+		}
+
+		for _, check := range checks {
+			isCompatible, checkReasons, err := check()
+			if err != nil {
+				return nil, routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
+			}
+
+			if isCompatible {
+				continue
+			}
+
+			if len(checkReasons) == 0 {
+				checkReasons = []string{"schema is incompatible"}
+			}
+
+			for _, reason := range checkReasons {
+				reasons = append(reasons, fmt.Sprintf("version %d: %s", existingSchemaVersion.Version, reason))
+			}
+		}
+	}
+
+	return reasons, nil
+}
@@ -0,0 +1,105 @@
+package subjects
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/google/uuid"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// resolveDataContract returns the metadata and rule set a schema registered to the subject gets. A schema without its
+// own keeps the ones of the latest version, the config defaults are merged under them and the config overrides over them
+func resolveDataContract(tx *gorm.DB, subjectName string, subjectID uuid.UUID, metadata *dbModels.Metadata, ruleSet *dbModels.RuleSet) (*dbModels.Metadata, *dbModels.RuleSet, error) {
+	if metadata == nil || ruleSet == nil {
+		latestVersion, err := getSubjectVersionBySubjectID(tx, subjectID, "latest", false)
+		if err != nil && errors.Is(err, gorm.ErrRecordNotFound) == false {
+			return nil, nil, fmt.Errorf("error finding latest version for subject %s: %w", subjectName, err)
+		}
+
+		if latestVersion != nil {
+			if metadata == nil {
+				metadata, err = dbModels.DecodeMetadata(latestVersion.Metadata)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			if ruleSet == nil {
+				ruleSet, err = dbModels.DecodeRuleSet(latestVersion.RuleSet)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	contract, err := dbModels.GetSubjectDataContract(tx, subjectName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding data contract config for subject %s: %w", subjectName, err)
+	}
+
+	metadata = dbModels.MergeMetadata(contract.DefaultMetadata, metadata, contract.OverrideMetadata)
+	ruleSet = dbModels.MergeRuleSets(contract.DefaultRuleSet, ruleSet, contract.OverrideRuleSet)
+
+	return metadata, ruleSet, nil
+}
+
+// dataContractHash returns the hash of the metadata and rule set sent with a schema, it's empty when neither was sent.
+// Only what's sent is hashed so inherited data contracts and config changes don't change which schema it is
+func dataContractHash(metadata *dbModels.Metadata, ruleSet *dbModels.RuleSet) (string, error) {
+	if metadata == nil && ruleSet == nil {
+		return "", nil
+	}
+
+	rawMetadata, err := dbModels.EncodeMetadata(metadata)
+	if err != nil {
+		return "", err
+	}
+
+	rawRuleSet, err := dbModels.EncodeRuleSet(ruleSet)
+	if err != nil {
+		return "", err
+	}
+
+	hash128 := fnv.New128a()
+	for _, value := range []string{rawMetadata, rawRuleSet} {
+		if _, err := hash128.Write([]byte(value)); err != nil {
+			return "", fmt.Errorf("error calculating hash of data contract: %w", err)
+		}
+		// separate the values so they can't run into each other
+		if _, err := hash128.Write([]byte{0}); err != nil {
+			return "", fmt.Errorf("error calculating hash of data contract: %w", err)
+		}
+	}
+
+	return hex.EncodeToString(hash128.Sum(nil)), nil
+}
+
+// findSubjectSchema returns the schema of a version of the subject that matches the schema with its data contract.
+// A schema sent without metadata and without a rule set matches the latest version with the same schema whatever its
+// data contract is, otherwise the metadata and rule set sent have to be the ones it was registered with
+func findSubjectSchema(tx *gorm.DB, subjectID uuid.UUID, subjectName string, hash string, metadata *dbModels.Metadata, ruleSet *dbModels.RuleSet) (*dbModels.Schema, error) {
+	schemaTx := tx.Clauses(dbModels.ForceIndexHint("idx_schemas_context_hash")).
+		Joins("JOIN subject_versions ON subject_versions.schema_id = schemas.id AND subject_versions.deleted_at IS NULL").
+		Where("subject_versions.subject_id = ? AND schemas.context = ? AND schemas.hash = ?", subjectID, dbModels.SubjectContextColumn(subjectName), hash)
+
+	if metadata != nil || ruleSet != nil {
+		contractHash, err := dataContractHash(metadata, ruleSet)
+		if err != nil {
+			return nil, err
+		}
+		schemaTx = schemaTx.Where("schemas.contract_hash = ?", contractHash)
+	}
+
+	schema := &dbModels.Schema{}
+	err := schemaTx.Order("subject_versions.version desc").Take(schema).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
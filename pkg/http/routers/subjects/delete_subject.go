@@ -5,19 +5,25 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/google/uuid"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-func deleteSubject(db *gorm.DB, subjectName string, permanent bool) (*ResponseDeleteSubjectVersions, error) {
+func deleteSubject(db *gorm.DB, registry *Registry, subjectName string, permanent bool) (*ResponseDeleteSubjectVersions, error) {
 
 	var subjectVersions []dbModels.SubjectVersion
 
 	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := getWritableSubjectMode(tx, subjectName); err != nil {
+			return err
+		}
+
 		subject := &dbModels.Subject{}
-		err := tx.Unscoped().Clauses(forceIndexHint("idx_subjects_name")).
+		err := tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_subjects_name")).
 			Where("name = ?", subjectName).First(subject).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -30,6 +36,33 @@ func deleteSubject(db *gorm.DB, subjectName string, permanent bool) (*ResponseDe
 			return routers.NewAPIError(http.StatusConflict, 40901, fmt.Errorf("must soft delete first"))
 		}
 
+		// versions of this subject can reference each other as they're all going away
+		versionsTx := tx.Model(&dbModels.SubjectVersion{}).Clauses(dbModels.ForceIndexHint("idx_subject_versions_subject_id")).Where("subject_id = ?", subject.ID)
+		if permanent {
+			versionsTx = versionsTx.Unscoped()
+		}
+		var versionsToDelete []dbModels.SubjectVersion
+		err = versionsTx.Order("version").Find(&versionsToDelete).Error
+		if err != nil {
+			return fmt.Errorf("error finding subject versions: %w", err)
+		}
+
+		versionIDs := make([]uuid.UUID, len(versionsToDelete))
+		for index, versionToDelete := range versionsToDelete {
+			versionIDs[index] = versionToDelete.ID
+		}
+
+		referencingSchemaIDs, err := getReferencingSchemaIDs(tx, versionIDs, &subject.ID, permanent)
+		if err != nil {
+			return fmt.Errorf("error finding references to subject %s: %w", subjectName, err)
+		}
+
+		for _, versionToDelete := range versionsToDelete {
+			if len(referencingSchemaIDs[versionToDelete.ID]) > 0 {
+				return newReferenceExistsError(subjectName, versionToDelete.Version, referencingSchemaIDs[versionToDelete.ID])
+			}
+		}
+
 		deleteVersionsTx := tx
 		if permanent {
 			deleteVersionsTx = deleteVersionsTx.Unscoped()
@@ -48,7 +81,31 @@ func deleteSubject(db *gorm.DB, subjectName string, permanent bool) (*ResponseDe
 			return fmt.Errorf("error deleting subject versions: %w", err)
 		}
 
-		return nil
+		if permanent {
+			// subject is gone so the subject level config and mode go with it
+			err = tx.Where("subject = ?", subject.Name).Delete(&dbModels.Config{}).Error
+			if err != nil {
+				return fmt.Errorf("error deleting subject config: %w", err)
+			}
+
+			err = tx.Where("subject = ?", subject.Name).Delete(&dbModels.Mode{}).Error
+			if err != nil {
+				return fmt.Errorf("error deleting subject mode: %w", err)
+			}
+		}
+
+		event := events.Event{
+			Kind:      events.KindSubject,
+			Subject:   subject.Name,
+			Deleted:   true,
+			Permanent: permanent,
+		}
+		for _, subjectVersion := range subjectVersions {
+			event.Versions = append(event.Versions, subjectVersion.Version)
+			event.SchemaIDs = append(event.SchemaIDs, subjectVersion.SchemaID)
+		}
+
+		return registry.publisher.Publish(tx, event)
 	})
 
 	if err != nil {
@@ -9,14 +9,16 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
 func TestDeleteSubject(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -24,8 +26,10 @@ func TestDeleteSubject(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to delete subject empty db
-	resp, err := deleteSubject(db, "unknown", false)
+	resp, err := deleteSubject(db, registry, "unknown", false)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -38,23 +42,22 @@ func TestDeleteSubject(t *testing.T) {
 	// insert subject, schema & version
 	err = db.Transaction(func(tx *gorm.DB) error {
 		subject := &dbModels.Subject{
-			ID:            uuid.New(),
-			Name:          "one",
-			Compatibility: dbModels.SubjectCompatibilityBackward,
+			ID:   uuid.New(),
+			Name: "one",
 		}
 		if err := tx.Create(subject).Error; err != nil {
 			return fmt.Errorf("error creating subject: %w", err)
 		}
 
 		// tx because sqlite doesn't allow multiple write transactions at once
-		globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+		globalID, err := registry.schemaIDs.Next(tx)
 		if err != nil {
-			return fmt.Errorf("error getting next sequence id: %w", err)
+			return fmt.Errorf("error getting next schema id: %w", err)
 		}
 
 		schema := &dbModels.Schema{
 			ID:         uuid.New(),
-			GlobalID:   int32(globalID),
+			GlobalID:   globalID,
 			Schema:     "", // schema and hash doesn't matter for this test
 			Hash:       "",
 			SchemaType: dbModels.SchemaTypeAvro,
@@ -73,12 +76,21 @@ func TestDeleteSubject(t *testing.T) {
 			return fmt.Errorf("error creating subject version: %w", err)
 		}
 
+		config := &dbModels.Config{
+			ID:            uuid.New(),
+			Subject:       subject.Name,
+			Compatibility: dbModels.SubjectCompatibilityNone,
+		}
+		if err := tx.Create(config).Error; err != nil {
+			return fmt.Errorf("error creating config: %w", err)
+		}
+
 		return nil
 	})
 	assert.NoError(t, err)
 
 	// try and hard delete the subject
-	resp, err = deleteSubject(db, "one", true)
+	resp, err = deleteSubject(db, registry, "one", true)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -89,7 +101,7 @@ func TestDeleteSubject(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
 
 	// soft delete subject
-	resp, err = deleteSubject(db, "one", false)
+	resp, err = deleteSubject(db, registry, "one", false)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []int32{1}, *resp)
 	subject := &dbModels.Subject{}
@@ -98,9 +110,119 @@ func TestDeleteSubject(t *testing.T) {
 	assert.True(t, subject.DeletedAt.Valid)
 
 	// hard delete subject
-	resp, err = deleteSubject(db, "one", true)
+	resp, err = deleteSubject(db, registry, "one", true)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []int32{1}, *resp)
 	err = db.Unscoped().Where(&dbModels.Subject{Name: "one"}).First(&dbModels.Subject{}).Error
 	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// subject config is removed with the subject
+	config, err := dbModels.GetConfig(db, "one")
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+}
+
+func TestDeleteSubjectReferenced(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "long"}]}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_one",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+
+	// can't soft delete a subject with referenced versions
+	resp, err := deleteSubject(db, registry, "one", false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42206, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "version 1 of subject one, referenced by schema ids: 2")
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	resp, err = deleteSubject(db, registry, "two", false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1}, *resp)
+
+	resp, err = deleteSubject(db, registry, "one", false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1}, *resp)
+
+	// soft deleted referencing versions still block the permanent delete
+	resp, err = deleteSubject(db, registry, "one", true)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42206, apiError.ErrorCode)
+
+	resp, err = deleteSubject(db, registry, "two", true)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1}, *resp)
+
+	resp, err = deleteSubject(db, registry, "one", true)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1}, *resp)
+
+	// versions referencing each other in the same subject don't block deleting the subject
+	err = db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "three",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error
+	assert.NoError(t, err)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_three", "fields": [{"name": "field1", "type": "long"}]}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err = postSubjectVersion(db, registry, "three", requestPostSubject)
+	assert.NoError(t, err)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_four", "fields": [{"name": "field1", "type": "schema_three"}]}`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_three",
+				Subject: "three",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err = postSubjectVersion(db, registry, "three", requestPostSubject)
+	assert.NoError(t, err)
+
+	resp, err = deleteSubject(db, registry, "three", false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1, 2}, *resp)
+
+	resp, err = deleteSubject(db, registry, "three", true)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1, 2}, *resp)
 }
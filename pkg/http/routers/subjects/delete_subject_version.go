@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"gorm.io/gorm"
 )
 
-func deleteSubjectVersion(db *gorm.DB, subjectName string, version string, permanent bool) (*ResponseDeleteSubjectVersion, error) {
+func deleteSubjectVersion(db *gorm.DB, registry *Registry, subjectName string, version string, permanent bool) (*ResponseDeleteSubjectVersion, error) {
 	var resp ResponseDeleteSubjectVersion
 
 	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := getWritableSubjectMode(tx, subjectName); err != nil {
+			return err
+		}
+
 		subject, err := getSubjectByName(tx, subjectName, false)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -37,6 +43,16 @@ func deleteSubjectVersion(db *gorm.DB, subjectName string, version string, perma
 			return routers.NewAPIError(http.StatusConflict, 40901, fmt.Errorf("must soft delete version %d first", versionModel.Version))
 		}
 
+		// soft deleted versions can still be read so only allow the permanent delete once nothing references it at all
+		referencingSchemaIDs, err := getReferencingSchemaIDs(tx, []uuid.UUID{versionModel.ID}, nil, permanent)
+		if err != nil {
+			return fmt.Errorf("error finding references to version %s for subject %s: %w", version, subjectName, err)
+		}
+
+		if len(referencingSchemaIDs[versionModel.ID]) > 0 {
+			return newReferenceExistsError(subjectName, versionModel.Version, referencingSchemaIDs[versionModel.ID])
+		}
+
 		deleteTx := tx
 		if permanent {
 			deleteTx = deleteTx.Unscoped()
@@ -48,6 +64,18 @@ func deleteSubjectVersion(db *gorm.DB, subjectName string, version string, perma
 			return fmt.Errorf("error deleting version %s for subject %s: %w", version, subjectName, err)
 		}
 
+		err = registry.publisher.Publish(tx, events.Event{
+			Kind:      events.KindSubjectVersion,
+			Subject:   subjectName,
+			Deleted:   true,
+			Permanent: permanent,
+			Versions:  []int32{versionModel.Version},
+			SchemaIDs: []uuid.UUID{versionModel.SchemaID},
+		})
+		if err != nil {
+			return err
+		}
+
 		resp = ResponseDeleteSubjectVersion(versionModel.Version)
 
 		return nil
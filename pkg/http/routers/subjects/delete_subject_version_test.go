@@ -10,14 +10,16 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
 func TestDeleteSubjectVersion(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -25,8 +27,10 @@ func TestDeleteSubjectVersion(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to delete subject version empty db
-	resp, err := deleteSubjectVersion(db, "unknown", "1", false)
+	resp, err := deleteSubjectVersion(db, registry, "unknown", "1", false)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -39,9 +43,8 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	// insert subject, schema & version
 	err = db.Transaction(func(tx *gorm.DB) error {
 		subject := &dbModels.Subject{
-			ID:            uuid.New(),
-			Name:          "one",
-			Compatibility: dbModels.SubjectCompatibilityBackward,
+			ID:   uuid.New(),
+			Name: "one",
 		}
 		if err := tx.Create(subject).Error; err != nil {
 			return fmt.Errorf("error creating subject: %w", err)
@@ -49,14 +52,14 @@ func TestDeleteSubjectVersion(t *testing.T) {
 
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     "", // schema and hash doesn't matter for this test
 				Hash:       strconv.Itoa(i),
 				SchemaType: dbModels.SchemaTypeAvro,
@@ -81,7 +84,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.NoError(t, err)
 
 	// try and delete a version that doesn't exist
-	resp, err = deleteSubjectVersion(db, "one", "1000", false)
+	resp, err = deleteSubjectVersion(db, registry, "one", "1000", false)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -92,7 +95,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	// try and hard delete before soft delete
-	resp, err = deleteSubjectVersion(db, "one", "1", true)
+	resp, err = deleteSubjectVersion(db, registry, "one", "1", true)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -103,7 +106,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
 
 	// hard delete latest version
-	resp, err = deleteSubjectVersion(db, "one", "latest", true)
+	resp, err = deleteSubjectVersion(db, registry, "one", "latest", true)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -114,7 +117,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 
 	// hard delete -1 version
-	resp, err = deleteSubjectVersion(db, "one", "-1", true)
+	resp, err = deleteSubjectVersion(db, registry, "one", "-1", true)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -125,7 +128,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 
 	// delete latest version
-	resp, err = deleteSubjectVersion(db, "one", "latest", false)
+	resp, err = deleteSubjectVersion(db, registry, "one", "latest", false)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, ResponseDeleteSubjectVersion(5), *resp)
@@ -135,7 +138,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.True(t, subjectVersion.DeletedAt.Valid)
 
 	// delete -1 version
-	resp, err = deleteSubjectVersion(db, "one", "-1", false)
+	resp, err = deleteSubjectVersion(db, registry, "one", "-1", false)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, ResponseDeleteSubjectVersion(4), *resp)
@@ -145,7 +148,7 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.True(t, subjectVersion.DeletedAt.Valid)
 
 	// soft delete 3
-	resp, err = deleteSubjectVersion(db, "one", "3", false)
+	resp, err = deleteSubjectVersion(db, registry, "one", "3", false)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, ResponseDeleteSubjectVersion(3), *resp)
@@ -155,10 +158,81 @@ func TestDeleteSubjectVersion(t *testing.T) {
 	assert.True(t, subjectVersion.DeletedAt.Valid)
 
 	// hard delete 3
-	resp, err = deleteSubjectVersion(db, "one", "3", true)
+	resp, err = deleteSubjectVersion(db, registry, "one", "3", true)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, ResponseDeleteSubjectVersion(3), *resp)
 	err = db.Unscoped().Where(&dbModels.SubjectVersion{Version: 3}).First(&dbModels.SubjectVersion{}).Error
 	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
 }
+
+func TestDeleteSubjectVersionReferenced(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "long"}]}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	postResp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), postResp.ID)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_one",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	postResp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), postResp.ID)
+
+	// can't soft delete a referenced version
+	resp, err := deleteSubjectVersion(db, registry, "one", "1", false)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42206, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "referenced by schema ids: 2")
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	// soft deleting the referencing version allows the soft delete
+	resp, err = deleteSubjectVersion(db, registry, "two", "1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), int32(*resp))
+
+	resp, err = deleteSubjectVersion(db, registry, "one", "1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), int32(*resp))
+
+	// but the soft deleted referencing version still blocks the permanent delete
+	resp, err = deleteSubjectVersion(db, registry, "one", "1", true)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42206, apiError.ErrorCode)
+
+	resp, err = deleteSubjectVersion(db, registry, "two", "1", true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), int32(*resp))
+
+	resp, err = deleteSubjectVersion(db, registry, "one", "1", true)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), int32(*resp))
+}
@@ -43,6 +43,14 @@ func getSubjectVersion(db *gorm.DB, subjectName string, version string) (*Respon
 		response.Version = versionModel.Version
 		response.SchemaType = schemas.SchemaType(schema.SchemaType)
 		response.Schema = schema.Schema
+		response.Metadata, err = dbModels.DecodeMetadata(versionModel.Metadata)
+		if err != nil {
+			return err
+		}
+		response.RuleSet, err = dbModels.DecodeRuleSet(versionModel.RuleSet)
+		if err != nil {
+			return err
+		}
 
 		if response.SchemaType == schemas.SchemaTypeAvro {
 			// set to empty string when avro for compatibility
@@ -0,0 +1,47 @@
+package subjects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"gorm.io/gorm"
+)
+
+func getSubjectVersionGraph(db *gorm.DB, registry *Registry, subjectName string, version string, options graph.Options) (*graph.Graph, error) {
+	var response *graph.Graph
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		subject, err := getSubjectByName(tx, subjectName, options.IncludeDeleted)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+			}
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+
+		versionModel, err := getSubjectVersionBySubjectID(tx, subject.ID, version, options.IncludeDeleted)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40402, fmt.Errorf("version not found"))
+			}
+			apiError := &routers.APIError{}
+			if errors.As(err, &apiError) {
+				return err
+			}
+			return fmt.Errorf("error finding version %s for subject %s: %w", version, subjectName, err)
+		}
+
+		response, err = graph.Build(tx, []uuid.UUID{versionModel.ID}, options, registry.limits)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
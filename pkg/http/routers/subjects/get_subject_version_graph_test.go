@@ -0,0 +1,83 @@
+package subjects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSubjectVersionGraph(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	options := graph.Options{Direction: graph.DirectionBoth, MaxDepth: 5}
+
+	// try to get the graph on empty db
+	resp, err := getSubjectVersionGraph(db, registry, "unknown", "1", options)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// three references two which references one
+	for _, subjectName := range []string{"one", "two", "three"} {
+		data := &RequestPostSubjectVersion{
+			Schema: `{"type": "record", "name": "one", "fields": [{"name": "a", "type": "string"}]}`,
+		}
+		switch subjectName {
+		case "two":
+			data.Schema = `{"type": "record", "name": "two", "fields": [{"name": "one", "type": "one"}]}`
+			data.References = []SubjectReference{{Name: "one", Subject: "one", Version: 1}}
+		case "three":
+			data.Schema = `{"type": "record", "name": "three", "fields": [{"name": "two", "type": "two"}]}`
+			data.References = []SubjectReference{{Name: "two", Subject: "two", Version: 1}}
+		}
+
+		assert.NoError(t, data.Bind(nil))
+		_, err := postSubjectVersion(db, registry, subjectName, data)
+		assert.NoError(t, err)
+	}
+
+	resp, err = getSubjectVersionGraph(db, registry, "one", "2", options)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40402, apiError.ErrorCode)
+
+	resp, err = getSubjectVersionGraph(db, registry, "one", "invalid", options)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42202, apiError.ErrorCode)
+
+	resp, err = getSubjectVersionGraph(db, registry, "two", "latest", options)
+	assert.NoError(t, err)
+	assert.Equal(t, []graph.Node{
+		{Key: "two/versions/1", Subject: "two", Version: 1, ID: 2, Direction: graph.DirectionRoot},
+		{Key: "one/versions/1", Subject: "one", Version: 1, ID: 1, Direction: graph.DirectionUpstream, Depth: 1},
+		{Key: "three/versions/1", Subject: "three", Version: 1, ID: 3, Direction: graph.DirectionDownstream, Depth: 1},
+	}, resp.Nodes)
+	assert.Equal(t, []graph.Edge{
+		{From: "two/versions/1", To: "one/versions/1", Name: "one"},
+		{From: "three/versions/1", To: "two/versions/1", Name: "two"},
+	}, resp.Edges)
+}
@@ -31,7 +31,7 @@ func getSubjectVersionReferencedBy(db *gorm.DB, subjectName string, version stri
 		}
 
 		schemaReferences := make([]dbModels.SchemaReference, 0)
-		err = tx.Clauses(forceIndexHint("idx_subject_version_id")).Joins("Schema").Where("schema_references.subject_version_id = ?", versionModel.ID).Find(&schemaReferences).Error
+		err = tx.Clauses(dbModels.ForceIndexHint("idx_subject_version_id")).Joins("Schema").Where("schema_references.subject_version_id = ?", versionModel.ID).Find(&schemaReferences).Error
 		if err != nil {
 			return fmt.Errorf("error finding references: %w", err)
 		}
@@ -9,14 +9,16 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
 func TestGetSubjectVersionReferencedBy(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -24,6 +26,8 @@ func TestGetSubjectVersionReferencedBy(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to get version on empty db
 	resp, err := getSubjectVersionReferencedBy(db, "unknown", "1")
 	apiError := &routers.APIError{}
@@ -36,9 +40,8 @@ func TestGetSubjectVersionReferencedBy(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	subjectOne := &dbModels.Subject{
-		ID:            uuid.New(),
-		Name:          "one",
-		Compatibility: dbModels.SubjectCompatibilityBackward,
+		ID:   uuid.New(),
+		Name: "one",
 	}
 	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(subjectOne).Error; err != nil {
@@ -47,14 +50,14 @@ func TestGetSubjectVersionReferencedBy(t *testing.T) {
 
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     "", // schema and hash doesn't matter for this test
 				Hash:       fmt.Sprintf("one-%d", i),
 				SchemaType: dbModels.SchemaTypeAvro,
@@ -79,9 +82,8 @@ func TestGetSubjectVersionReferencedBy(t *testing.T) {
 	assert.NoError(t, err)
 
 	subjectTwo := &dbModels.Subject{
-		ID:            uuid.New(),
-		Name:          "two",
-		Compatibility: dbModels.SubjectCompatibilityBackward,
+		ID:   uuid.New(),
+		Name: "two",
 	}
 	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(subjectTwo).Error; err != nil {
@@ -89,14 +91,14 @@ func TestGetSubjectVersionReferencedBy(t *testing.T) {
 		}
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     "", // schema and hash doesn't matter for this test
 				Hash:       fmt.Sprintf("two-%d", i),
 				SchemaType: dbModels.SchemaTypeAvro,
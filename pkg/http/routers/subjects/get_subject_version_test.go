@@ -10,7 +10,9 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 	"github.com/stretchr/testify/assert"
@@ -18,7 +20,7 @@ import (
 )
 
 func TestGetSubjectVersionTest(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -26,6 +28,8 @@ func TestGetSubjectVersionTest(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to get version on empty db
 	resp, err := getSubjectVersion(db, "unknown", "1")
 	apiError := &routers.APIError{}
@@ -40,9 +44,8 @@ func TestGetSubjectVersionTest(t *testing.T) {
 	// insert subject, schema & version
 	err = db.Transaction(func(tx *gorm.DB) error {
 		subject := &dbModels.Subject{
-			ID:            uuid.New(),
-			Name:          "one",
-			Compatibility: dbModels.SubjectCompatibilityBackward,
+			ID:   uuid.New(),
+			Name: "one",
 		}
 		if err := tx.Create(subject).Error; err != nil {
 			return fmt.Errorf("error creating subject: %w", err)
@@ -50,14 +53,14 @@ func TestGetSubjectVersionTest(t *testing.T) {
 
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     "", // schema and hash doesn't matter for this test
 				Hash:       strconv.Itoa(i),
 				SchemaType: dbModels.SchemaTypeAvro,
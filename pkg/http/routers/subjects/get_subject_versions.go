@@ -9,20 +9,18 @@ import (
 	"gorm.io/gorm"
 )
 
-func getSubjectVersions(db *gorm.DB, subjectName string, includeDeleted bool) (*ResponseGetSubjectVersions, error) {
+func getSubjectVersions(db *gorm.DB, subjectName string, options listOptions) (*ResponseGetSubjectVersions, error) {
 
 	var subjectVersions []dbModels.SubjectVersion
 
-	subjectVersionsDB := db
-	if includeDeleted {
-		subjectVersionsDB = subjectVersionsDB.Unscoped()
+	subjectVersionsDB := func(options listOptions) *gorm.DB {
+		return options.scope(db.Model(&dbModels.SubjectVersion{}), "subject_versions.deleted_at").
+			Clauses(dbModels.ForceIndexHint("idx_subjects_name")).
+			Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
+			Where("subjects.name = ? AND subjects.deleted_at is NULL", subjectName)
 	}
-	err := subjectVersionsDB.Model(&dbModels.SubjectVersion{}).
-		Clauses(forceIndexHint("idx_subjects_name")).
-		Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
-		Where("subjects.name = ? AND subjects.deleted_at is NULL", subjectName).
-		Order("subject_versions.version asc").Find(&subjectVersions).Error
 
+	err := subjectVersionsDB(options).Order("subject_versions.version asc").Find(&subjectVersions).Error
 	if err != nil {
 		return nil, err
 	}
@@ -30,6 +28,19 @@ func getSubjectVersions(db *gorm.DB, subjectName string, includeDeleted bool) (*
 	if len(subjectVersions) == 0 {
 		// TODO: no versions, so we should check if the subject actually exists, if it does return a empty list
 
+		// the page may be past the last version so check if there are versions at all
+		if options.offset > 0 {
+			var count int64
+			err := subjectVersionsDB(listOptions{includeDeleted: options.includeDeleted, deletedOnly: options.deletedOnly}).Count(&count).Error
+			if err != nil {
+				return nil, err
+			}
+
+			if count > 0 {
+				return &ResponseGetSubjectVersions{}, nil
+			}
+		}
+
 		return nil, routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
 	}
 
@@ -0,0 +1,105 @@
+package subjects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+// getSubjectVersionsDiff returns the semantic changes from one version of the subject to another
+func getSubjectVersionsDiff(db *gorm.DB, registry *Registry, subjectName string, version string, otherVersion string) (*ResponseGetSubjectVersionsDiff, error) {
+	response := &ResponseGetSubjectVersionsDiff{
+		Subject:            subjectName,
+		Changes:            make([]SubjectVersionDiffChange, 0),
+		BackwardCompatible: true,
+		ForwardCompatible:  true,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		subject, err := getSubjectByName(tx, subjectName, false)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+			}
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+
+		parsedSchemas := make([]schemas.ParsedSchema, 0, 2)
+		schemaTypes := make([]schemas.SchemaType, 0, 2)
+		for index, v := range []string{version, otherVersion} {
+			versionModel, err := getSubjectVersionBySubjectID(tx, subject.ID, v, false)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return routers.NewAPIError(http.StatusNotFound, 40402, fmt.Errorf("version %s not found", v))
+				}
+				apiError := &routers.APIError{}
+				if errors.As(err, &apiError) {
+					return err
+				}
+				return fmt.Errorf("error finding version %s for subject %s: %w", v, subjectName, err)
+			}
+
+			err = tx.Where("id = ?", versionModel.SchemaID).First(&versionModel.Schema).Error
+			if err != nil {
+				return fmt.Errorf("error finding schema for version %s for subject %s: %w", v, subjectName, err)
+			}
+
+			parsedSchema, err := parseSubjectVersionSchema(tx, registry, *versionModel)
+			if err != nil {
+				return err
+			}
+
+			diffVersion := SubjectVersionDiffVersion{Version: versionModel.Version, ID: versionModel.Schema.GlobalID}
+			if index == 0 {
+				response.From = diffVersion
+			} else {
+				response.To = diffVersion
+			}
+
+			parsedSchemas = append(parsedSchemas, parsedSchema)
+			schemaTypes = append(schemaTypes, schemas.SchemaType(versionModel.Schema.SchemaType))
+		}
+
+		// versions of a subject share a type, but schemas of different types have nothing in common to compare
+		if schemaTypes[0] != schemaTypes[1] {
+			return routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("cannot diff a %s schema with a %s schema", schemaTypes[0], schemaTypes[1]))
+		}
+
+		response.SchemaType = schemaTypes[0]
+		if response.SchemaType == schemas.SchemaTypeAvro {
+			// set to empty string when avro for compatibility
+			response.SchemaType = ""
+		}
+
+		changes, err := parsedSchemas[1].Diff(parsedSchemas[0])
+		if err != nil {
+			return fmt.Errorf("error diffing versions %s and %s for subject %s: %w", version, otherVersion, subjectName, err)
+		}
+
+		for _, change := range changes {
+			response.Changes = append(response.Changes, SubjectVersionDiffChange{
+				Type:               change.Kind,
+				Path:               change.Path,
+				Constraint:         change.Constraint,
+				Previous:           change.Previous,
+				Current:            change.Current,
+				BackwardCompatible: change.Backward,
+				ForwardCompatible:  change.Forward,
+			})
+			response.BackwardCompatible = response.BackwardCompatible && change.Backward
+			response.ForwardCompatible = response.ForwardCompatible && change.Forward
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
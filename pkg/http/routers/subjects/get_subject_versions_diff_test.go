@@ -0,0 +1,92 @@
+package subjects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSubjectVersionsDiff(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// try to diff on empty db
+	resp, err := getSubjectVersionsDiff(db, registry, "unknown", "1", "2")
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// the versions don't have to be compatible to be diffed
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "one",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+
+	for _, schema := range []string{
+		`{"type": "record", "name": "one", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "int"}]}`,
+		`{"type": "record", "name": "one", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "long"}, {"name": "c", "type": "string", "default": ""}]}`,
+	} {
+		data := &RequestPostSubjectVersion{
+			Schema: schema,
+		}
+		assert.NoError(t, data.Bind(nil))
+		_, err := postSubjectVersion(db, registry, "one", data)
+		assert.NoError(t, err)
+	}
+
+	resp, err = getSubjectVersionsDiff(db, registry, "one", "1", "3")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40402, apiError.ErrorCode)
+
+	resp, err = getSubjectVersionsDiff(db, registry, "one", "invalid", "1")
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42202, apiError.ErrorCode)
+
+	resp, err = getSubjectVersionsDiff(db, registry, "one", "1", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, &ResponseGetSubjectVersionsDiff{
+		Subject: "one",
+		From:    SubjectVersionDiffVersion{Version: 1, ID: 1},
+		To:      SubjectVersionDiffVersion{Version: 2, ID: 2},
+		Changes: []SubjectVersionDiffChange{
+			{Type: schemas.ChangeTypeChanged, Path: "b", Previous: "int", Current: "long", BackwardCompatible: true, ForwardCompatible: false},
+			{Type: schemas.ChangeFieldAdded, Path: "c", Current: "string", BackwardCompatible: true, ForwardCompatible: true},
+		},
+		BackwardCompatible: true,
+		ForwardCompatible:  false,
+	}, resp)
+
+	// the same version has no changes
+	resp, err = getSubjectVersionsDiff(db, registry, "one", "2", "2")
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Changes)
+	assert.True(t, resp.BackwardCompatible)
+	assert.True(t, resp.ForwardCompatible)
+}
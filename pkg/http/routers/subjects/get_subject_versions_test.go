@@ -9,14 +9,16 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
 func TestGetSubjectVersions(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -24,8 +26,10 @@ func TestGetSubjectVersions(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to get versions on empty db
-	resp, err := getSubjectVersions(db, "unknown", false)
+	resp, err := getSubjectVersions(db, "unknown", listOptions{})
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -38,23 +42,22 @@ func TestGetSubjectVersions(t *testing.T) {
 	// insert subject, schema & version
 	err = db.Transaction(func(tx *gorm.DB) error {
 		subject := &dbModels.Subject{
-			ID:            uuid.New(),
-			Name:          "one",
-			Compatibility: dbModels.SubjectCompatibilityBackward,
+			ID:   uuid.New(),
+			Name: "one",
 		}
 		if err := tx.Create(subject).Error; err != nil {
 			return fmt.Errorf("error creating subject: %w", err)
 		}
 
 		// tx because sqlite doesn't allow multiple write transactions at once
-		globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+		globalID, err := registry.schemaIDs.Next(tx)
 		if err != nil {
-			return fmt.Errorf("error getting next sequence id: %w", err)
+			return fmt.Errorf("error getting next schema id: %w", err)
 		}
 
 		schema := &dbModels.Schema{
 			ID:         uuid.New(),
-			GlobalID:   int32(globalID),
+			GlobalID:   globalID,
 			Schema:     "", // schema and hash doesn't matter for this test
 			Hash:       "",
 			SchemaType: dbModels.SchemaTypeAvro,
@@ -78,7 +81,7 @@ func TestGetSubjectVersions(t *testing.T) {
 	assert.NoError(t, err)
 
 	// try to get versions again
-	resp, err = getSubjectVersions(db, "one", false)
+	resp, err = getSubjectVersions(db, "one", listOptions{})
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.NotEmpty(t, resp)
@@ -91,7 +94,7 @@ func TestGetSubjectVersions(t *testing.T) {
 	assert.NoError(t, err)
 
 	// subject versions should not be found
-	resp, err = getSubjectVersions(db, "one", false)
+	resp, err = getSubjectVersions(db, "one", listOptions{})
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
 	assert.Equal(t, 40401, apiError.ErrorCode)
@@ -101,9 +104,51 @@ func TestGetSubjectVersions(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	// subject versions should have soft deleted item
-	resp, err = getSubjectVersions(db, "one", true)
+	resp, err = getSubjectVersions(db, "one", listOptions{includeDeleted: true})
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.NotEmpty(t, resp)
 	assert.ElementsMatch(t, []int32{1}, *resp)
+
+	// deleted only
+	resp, err = getSubjectVersions(db, "one", listOptions{deletedOnly: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1}, []int32(*resp))
+
+	// past the last version is an empty page instead of not found
+	resp, err = getSubjectVersions(db, "one", listOptions{includeDeleted: true, offset: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, *resp)
+
+	resp, err = getSubjectVersions(db, "unknown", listOptions{offset: 1})
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+}
+
+func TestGetSubjectVersionsPaged(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	for i := 0; i < 5; i++ {
+		schema := fmt.Sprintf(`{"type": "record", "name": "test", "fields": [{"name": "field%d", "type": "string", "default": ""}]}`, i)
+		requestPostSubject := &RequestPostSubjectVersion{Schema: schema}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+		assert.NoError(t, err)
+	}
+
+	resp, err := getSubjectVersions(db, "one", listOptions{offset: 1, limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{2, 3}, []int32(*resp))
+
+	resp, err = getSubjectVersions(db, "one", listOptions{offset: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{4, 5}, []int32(*resp))
 }
@@ -2,18 +2,22 @@ package subjects
 
 import (
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"gorm.io/gorm"
 )
 
-func getSubjects(db *gorm.DB, includeDeleted bool) (*ResponseGetSubjects, error) {
+func getSubjects(db *gorm.DB, options listOptions) (*ResponseGetSubjects, error) {
 	var subjects []dbModels.Subject
 
-	subjectsDB := db
-	if includeDeleted {
-		subjectsDB = subjectsDB.Unscoped()
+	subjectsDB := options.scope(db.Clauses(dbModels.ForceIndexHint("idx_subjects_name")), "subjects.deleted_at")
+
+	// subjects are listed from the context of the prefix
+	subjectsDB = routers.ScopeSubjectPrefix(subjectsDB, options.subjectPrefix)
+	if options.readable != nil {
+		subjectsDB = options.readable(subjectsDB)
 	}
 
-	err := subjectsDB.Find(&subjects).Error
+	err := subjectsDB.Order("subjects.name asc").Find(&subjects).Error
 	if err != nil {
 		return nil, err
 	}
@@ -2,44 +2,29 @@ package subjects
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/google/uuid"
-	"github.com/rmb938/franz-schema-registry/pkg/database/migrations"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func TempDatabase(t testing.TB) (*gorm.DB, string) {
-	f, err := os.CreateTemp("", "franz-go-test-")
-	if err != nil {
-		t.Fatal(err)
-	}
-	f.Close()
-
-	var db *gorm.DB
-
-	defer func() {
-		if err != nil {
-			err := os.Remove(f.Name())
-			if err != nil {
-				t.Error("db file remove error while in temp database:", err)
-			}
-		}
-	}()
-
-	db, err = gorm.Open(sqlite.Open(fmt.Sprintf("%s", f.Name())))
+// newTestRegistry creates what the subjects routers share from the configuration
+func newTestRegistry(t *testing.T, db *gorm.DB, cfg *configuration.Configuration) *Registry {
+	registry, err := NewRegistry(db, cfg)
 	assert.NoError(t, err)
-	assert.NoError(t, migrations.RunMigrations(db))
 
-	return db, f.Name()
+	return registry
 }
 
 func TestGetSubjects(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -48,7 +33,7 @@ func TestGetSubjects(t *testing.T) {
 	}()
 
 	// get subjects on empty db
-	resp, err := getSubjects(db, false)
+	resp, err := getSubjects(db, listOptions{})
 	assert.NoError(t, err)
 	assert.Empty(t, resp)
 
@@ -62,9 +47,8 @@ func TestGetSubjects(t *testing.T) {
 
 		for _, subjectName := range subjects {
 			subject := &dbModels.Subject{
-				ID:            uuid.New(),
-				Name:          subjectName,
-				Compatibility: dbModels.SubjectCompatibilityBackward,
+				ID:   uuid.New(),
+				Name: subjectName,
 			}
 			if err := tx.Create(subject).Error; err != nil {
 				return fmt.Errorf("error creating subject: %s: %w", subject.Name, err)
@@ -76,7 +60,7 @@ func TestGetSubjects(t *testing.T) {
 	assert.NoError(t, err)
 
 	// get subjects again and make sure they match
-	resp, err = getSubjects(db, false)
+	resp, err = getSubjects(db, listOptions{})
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, subjects, *resp)
 
@@ -87,12 +71,98 @@ func TestGetSubjects(t *testing.T) {
 	assert.NoError(t, err)
 
 	// getting subjects should be empty
-	resp, err = getSubjects(db, false)
+	resp, err = getSubjects(db, listOptions{})
 	assert.NoError(t, err)
 	assert.Empty(t, resp)
 
 	// getting subjects with include deleted should not be empty
-	resp, err = getSubjects(db, true)
+	resp, err = getSubjects(db, listOptions{includeDeleted: true})
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, subjects, *resp)
 }
+
+func TestGetSubjectsPaged(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// inserted out of order to make sure they're sorted by name
+	for _, subjectName := range []string{"payments-value", "orders-value", "orders-key", "orders", "ordersz", "payments-key"} {
+		assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: subjectName}).Error)
+	}
+	assert.NoError(t, db.Where("name = ?", "payments-key").Delete(&dbModels.Subject{}).Error)
+
+	tests := []struct {
+		name     string
+		options  listOptions
+		expected []string
+	}{
+		{"all", listOptions{}, []string{"orders", "orders-key", "orders-value", "ordersz", "payments-value"}},
+		{"prefix", listOptions{subjectPrefix: "orders-"}, []string{"orders-key", "orders-value"}},
+		{"no match", listOptions{subjectPrefix: "shipments"}, []string{}},
+		{"limit", listOptions{limit: 2}, []string{"orders", "orders-key"}},
+		{"offset", listOptions{offset: 2, limit: 2}, []string{"orders-value", "ordersz"}},
+		{"past the end", listOptions{offset: 10}, []string{}},
+		{"prefix page", listOptions{subjectPrefix: "orders", offset: 1, limit: 2}, []string{"orders-key", "orders-value"}},
+		{"deleted", listOptions{subjectPrefix: "payments-", includeDeleted: true}, []string{"payments-key", "payments-value"}},
+		{"deleted only", listOptions{deletedOnly: true}, []string{"payments-key"}},
+		// pages only have readable subjects
+		{"readable page", listOptions{limit: 2, readable: func(tx *gorm.DB) *gorm.DB { return tx.Where("subjects.name <> ?", "orders") }}, []string{"orders-key", "orders-value"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := getSubjects(db, test.options)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, []string(*resp))
+		})
+	}
+}
+
+func TestGetSubjectsContexts(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	for _, subjectName := range []string{"orders", "payments", ":.team-a:orders", ":.team-a:payments", ":.team-b:orders"} {
+		assert.NoError(t, db.Create(&dbModels.Subject{ID: uuid.New(), Name: subjectName, Context: dbModels.SubjectContextColumn(subjectName)}).Error)
+	}
+
+	tests := []struct {
+		name     string
+		options  listOptions
+		expected []string
+	}{
+		{"default context", listOptions{}, []string{"orders", "payments"}},
+		{"default context prefix", listOptions{subjectPrefix: "ord"}, []string{"orders"}},
+		{"qualified default context", listOptions{subjectPrefix: ":.:ord"}, []string{"orders"}},
+		{"context", listOptions{subjectPrefix: ":.team-a:"}, []string{":.team-a:orders", ":.team-a:payments"}},
+		{"context prefix", listOptions{subjectPrefix: ":.team-a:pay"}, []string{":.team-a:payments"}},
+		{"every context", listOptions{subjectPrefix: dbModels.ContextWildcard}, []string{":.team-a:orders", ":.team-a:payments", ":.team-b:orders", "orders", "payments"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := getSubjects(db, test.options)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, []string(*resp))
+		})
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?subjectPrefix=orders-&deleted=true&deletedOnly=true&offset=10&limit=5", nil)
+	assert.Equal(t, listOptions{subjectPrefix: "orders-", includeDeleted: true, deletedOnly: true, offset: 10, limit: 5}, parseListOptions(req))
+
+	// invalid values are ignored
+	req = httptest.NewRequest(http.MethodGet, "/?deleted=maybe&offset=-1&limit=abc", nil)
+	assert.Equal(t, listOptions{}, parseListOptions(req))
+}
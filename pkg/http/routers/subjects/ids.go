@@ -0,0 +1,42 @@
+package subjects
+
+import (
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/ids"
+	"gorm.io/gorm"
+)
+
+// newSchemaIDAllocators creates the allocator of schema ids and the allocators of contexts with their own range
+func newSchemaIDAllocators(db *gorm.DB, config configuration.SchemaIDsConfiguration) (*ids.Allocator, map[string]*ids.Allocator, error) {
+	contextRanges, err := config.ContextIDRanges()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// sqlite only allows a single writer so the schema id has to come from the registration transaction
+	if db.Dialector.Name() == "sqlite" {
+		db = nil
+	}
+
+	contextSchemaIDs := make(map[string]*ids.Allocator, len(contextRanges))
+	for context, contextRange := range contextRanges {
+		contextConfig := config
+		contextConfig.RangeStart = contextRange.Start
+		contextConfig.RangeEnd = contextRange.End
+		contextSchemaIDs[context] = ids.NewAllocator(db, dbModels.SequenceNameSchemaIDs, contextConfig)
+	}
+
+	return ids.NewAllocator(db, dbModels.SequenceNameSchemaIDs, config), contextSchemaIDs, nil
+}
+
+// schemaIDAllocator returns the allocator of ids for schemas registered to the subject,
+// contexts without their own range share the ids of the default context
+func (r *Registry) schemaIDAllocator(subjectName string) *ids.Allocator {
+	context, _ := dbModels.ParseSubjectName(subjectName)
+	if allocator, ok := r.contextSchemaIDs[context]; ok {
+		return allocator
+	}
+
+	return r.schemaIDs
+}
@@ -0,0 +1,17 @@
+package subjects
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+)
+
+// checkReferencesLimit errors when a schema pulls in more references than allowed
+func (r *Registry) checkReferencesLimit(references int) error {
+	if r.limits.MaxReferences > 0 && references > r.limits.MaxReferences {
+		return routers.NewAPIError(http.StatusConflict, 40902, fmt.Errorf("hit schema references limit, schema has %d references but only %d are allowed", references, r.limits.MaxReferences))
+	}
+
+	return nil
+}
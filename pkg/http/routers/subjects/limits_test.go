@@ -0,0 +1,107 @@
+package subjects
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostSubjectVersionReferencesLimits(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	cfg := configuration.Default()
+	cfg.Limits = configuration.LimitsConfiguration{MaxReferenceDepth: 2, MaxReferences: 2}
+	registry := newTestRegistry(t, db, cfg)
+
+	for _, name := range []string{"one", "other"} {
+		requestPostSubject := &RequestPostSubjectVersion{
+			Schema: fmt.Sprintf(`{"type": "record", "name": "schema_%s", "fields": [{"name": "field1", "type": "long"}]}`, name),
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, name, requestPostSubject)
+		assert.NoError(t, err)
+	}
+
+	// each schema references the one before it
+	previous := "one"
+	for _, name := range []string{"two", "three"} {
+		requestPostSubject := &RequestPostSubjectVersion{
+			Schema: fmt.Sprintf(`{"type": "record", "name": "schema_%s", "fields": [{"name": "field1", "type": "schema_%s"}]}`, name, previous),
+			References: []SubjectReference{
+				{
+					Name:    "schema_" + previous,
+					Subject: previous,
+					Version: int32(1),
+				},
+			},
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		_, err := postSubjectVersion(db, registry, name, requestPostSubject)
+		assert.NoError(t, err)
+		previous = name
+	}
+
+	// chain is too deep
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_four", "fields": [{"name": "field1", "type": "schema_three"}]}`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_three",
+				Subject: "three",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "four", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40902, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "reference chain is too deep")
+
+	// too many references in total
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_four", "fields": [{"name": "field1", "type": "schema_two"}, {"name": "field2", "type": "schema_other"}]}`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_two",
+				Subject: "two",
+				Version: int32(1),
+			},
+			{
+				Name:    "schema_other",
+				Subject: "other",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "four", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40902, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "hit schema references limit")
+
+	// the compatibility api has the same limits
+	compatibilityResp, err := postCompatibilitySubjectVersions(db, registry, "four", &RequestPostCompatibility{
+		Schema:     requestPostSubject.Schema,
+		References: requestPostSubject.References,
+	}, false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, compatibilityResp)
+	assert.Equal(t, 40902, apiError.ErrorCode)
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
 	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 )
 
@@ -46,28 +47,45 @@ type RequestPostSubjectVersion struct {
 	Schema     string             `json:"schema"`
 	SchemaType schemas.SchemaType `json:"schemaType"`
 	References []SubjectReference `json:"references,omitempty"`
+	Metadata   *dbModels.Metadata `json:"metadata,omitempty"`
+	RuleSet    *dbModels.RuleSet  `json:"ruleSet,omitempty"`
 
-	calculatedHash string
+	// ID and Version can only be set when the subject is in IMPORT mode
+	ID      int32 `json:"id,omitempty"`
+	Version int32 `json:"version,omitempty"`
+
+	normalize bool
 }
 
-func calculateSchemaHash(schema string, references []SubjectReference) (string, error) {
+// calculateSchemaHash returns the hash of the schema registered to the subject, references are hashed by the subject
+// they resolve to so every way of naming the same subject gives the same hash
+func calculateSchemaHash(subjectName string, schemaType schemas.SchemaType, schema string, references []SubjectReference) (string, error) {
 	hash128 := fnv.New128a()
 	if _, err := hash128.Write([]byte(schema)); err != nil {
 		return "", fmt.Errorf("error calculating hash of schema: %w", err)
 	}
 
-	foundReferenceNames := map[string]interface{}{}
-	for _, reference := range references {
-		if _, ok := foundReferenceNames[reference.Name]; ok {
-			return "", fmt.Errorf("duplicate reference name %s", reference.Name)
+	// the same schema string can be valid for multiple types so the type makes the schema unique
+	// avro is left out so hashes of schemas from before other types were supported don't change
+	if len(schemaType) > 0 && schemaType != schemas.SchemaTypeAvro {
+		if _, err := hash128.Write([]byte(schemaType)); err != nil {
+			return "", fmt.Errorf("error calculating hash of schema: %w", err)
 		}
-		foundReferenceNames[reference.Name] = nil
+	}
+
+	if err := validateReferences(references); err != nil {
+		return "", err
 	}
 
 	// add references to the hash as references also make the schema unique
 	// sort references by name before we add them so if only the ordering changes the hash is the same
-	sort.Slice(references, func(i, j int) bool {
-		cmp := strings.Compare(references[i].Name, references[j].Name)
+	hashReferences := make([]SubjectReference, 0, len(references))
+	for _, reference := range references {
+		reference.Subject = referenceSubjectName(subjectName, reference.Subject)
+		hashReferences = append(hashReferences, reference)
+	}
+	sort.Slice(hashReferences, func(i, j int) bool {
+		cmp := strings.Compare(hashReferences[i].Name, hashReferences[j].Name)
 
 		if cmp < 0 {
 			return true
@@ -75,7 +93,7 @@ func calculateSchemaHash(schema string, references []SubjectReference) (string,
 
 		return false
 	})
-	for _, reference := range references {
+	for _, reference := range hashReferences {
 		if _, err := hash128.Write([]byte(reference.Name)); err != nil {
 			return "", fmt.Errorf("error calculating hash of schema: %w", err)
 		}
@@ -90,14 +108,58 @@ func calculateSchemaHash(schema string, references []SubjectReference) (string,
 	return hex.EncodeToString(hash128.Sum(nil)), nil
 }
 
+// validateReferences makes sure every reference has its own name
+func validateReferences(references []SubjectReference) error {
+	foundReferenceNames := map[string]interface{}{}
+	for _, reference := range references {
+		if _, ok := foundReferenceNames[reference.Name]; ok {
+			return fmt.Errorf("duplicate reference name %s", reference.Name)
+		}
+		foundReferenceNames[reference.Name] = nil
+	}
+
+	return nil
+}
+
+// validateDataContract validates the metadata and rule set of a schema, either can be nil
+func validateDataContract(metadata *dbModels.Metadata, ruleSet *dbModels.RuleSet) error {
+	if metadata != nil {
+		if err := metadata.Validate(); err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+
+	if ruleSet != nil {
+		if err := ruleSet.Validate(); err != nil {
+			return fmt.Errorf("invalid rule set: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (r *RequestPostSubjectVersion) Bind(request *http.Request) error {
 	if len(r.Schema) == 0 {
 		return fmt.Errorf("schema may not be empty")
 	}
 
-	var err error
-	r.calculatedHash, err = calculateSchemaHash(r.Schema, r.References)
-	if err != nil {
+	if r.ID < 0 {
+		return fmt.Errorf("id may not be negative")
+	}
+
+	if r.Version < 0 {
+		return fmt.Errorf("version may not be negative")
+	}
+
+	if err := validateDataContract(r.Metadata, r.RuleSet); err != nil {
+		return err
+	}
+
+	if request != nil {
+		r.normalize, _ = strconv.ParseBool(request.URL.Query().Get("normalize"))
+	}
+
+	if err := validateReferences(r.References); err != nil {
 		return err
 	}
 
@@ -112,12 +174,62 @@ func (r *ResponsePostSubjectVersion) Render(writer http.ResponseWriter, request
 	return nil
 }
 
+type RequestPostSubjectsBatchEntry struct {
+	Subject string `json:"subject"`
+	RequestPostSubjectVersion
+}
+
+// RequestPostSubjectsBatch registers the entries in order, a reference without a version to a subject
+// registered earlier in the batch is to the version the batch registered
+type RequestPostSubjectsBatch struct {
+	Entries []*RequestPostSubjectsBatchEntry `json:"entries"`
+}
+
+func (r *RequestPostSubjectsBatch) Bind(request *http.Request) error {
+	if len(r.Entries) == 0 {
+		return fmt.Errorf("entries may not be empty")
+	}
+
+	for index, entry := range r.Entries {
+		if entry == nil {
+			return fmt.Errorf("entry %d may not be empty", index)
+		}
+
+		if len(entry.Subject) == 0 {
+			return fmt.Errorf("entry %d: subject may not be empty", index)
+		}
+		entry.Subject = dbModels.NormalizeSubjectName(entry.Subject)
+
+		if err := entry.RequestPostSubjectVersion.Bind(request); err != nil {
+			return newBatchEntryError(index, entry, err)
+		}
+	}
+
+	return nil
+}
+
+type ResponsePostSubjectsBatchEntry struct {
+	Subject string `json:"subject"`
+	ID      int32  `json:"id"`
+	Version int32  `json:"version"`
+}
+
+type ResponsePostSubjectsBatch struct {
+	Entries []ResponsePostSubjectsBatchEntry `json:"entries"`
+}
+
+func (r *ResponsePostSubjectsBatch) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
 type RequestPostSubject struct {
 	Schema     string             `json:"schema"`
 	SchemaType schemas.SchemaType `json:"schemaType"`
 	References []SubjectReference `json:"references,omitempty"`
+	Metadata   *dbModels.Metadata `json:"metadata,omitempty"`
+	RuleSet    *dbModels.RuleSet  `json:"ruleSet,omitempty"`
 
-	calculatedHash string
+	normalize bool
 }
 
 func (r *RequestPostSubject) Bind(request *http.Request) error {
@@ -125,9 +237,15 @@ func (r *RequestPostSubject) Bind(request *http.Request) error {
 		return fmt.Errorf("schema may not be empty")
 	}
 
-	var err error
-	r.calculatedHash, err = calculateSchemaHash(r.Schema, r.References)
-	if err != nil {
+	if err := validateDataContract(r.Metadata, r.RuleSet); err != nil {
+		return err
+	}
+
+	if request != nil {
+		r.normalize, _ = strconv.ParseBool(request.URL.Query().Get("normalize"))
+	}
+
+	if err := validateReferences(r.References); err != nil {
 		return err
 	}
 
@@ -135,10 +253,13 @@ func (r *RequestPostSubject) Bind(request *http.Request) error {
 }
 
 type ResponsePostSubject struct {
-	Subject string `json:"subject"`
-	ID      int32  `json:"id"`
-	Version int32  `json:"version"`
-	Schema  string `json:"schema"`
+	Subject    string             `json:"subject"`
+	ID         int32              `json:"id"`
+	Version    int32              `json:"version"`
+	SchemaType schemas.SchemaType `json:"schemaType,omitempty"`
+	Schema     string             `json:"schema"`
+	Metadata   *dbModels.Metadata `json:"metadata,omitempty"`
+	RuleSet    *dbModels.RuleSet  `json:"ruleSet,omitempty"`
 }
 
 func (r *ResponsePostSubject) Render(writer http.ResponseWriter, request *http.Request) error {
@@ -157,6 +278,8 @@ type ResponseGetSubjectVersion struct {
 	Version    int32              `json:"version"`
 	SchemaType schemas.SchemaType `json:"schemaType,omitempty"`
 	Schema     string             `json:"schema"`
+	Metadata   *dbModels.Metadata `json:"metadata,omitempty"`
+	RuleSet    *dbModels.RuleSet  `json:"ruleSet,omitempty"`
 }
 
 func (r *ResponseGetSubjectVersion) Render(writer http.ResponseWriter, request *http.Request) error {
@@ -168,3 +291,58 @@ type ResponseGetSubjectVersionReferencedBy []int32
 func (r ResponseGetSubjectVersionReferencedBy) Render(writer http.ResponseWriter, request *http.Request) error {
 	return nil
 }
+
+type SubjectVersionDiffVersion struct {
+	Version int32 `json:"version"`
+	ID      int32 `json:"id"`
+}
+
+type SubjectVersionDiffChange struct {
+	Type       schemas.ChangeKind `json:"type"`
+	Path       string             `json:"path"`
+	Constraint string             `json:"constraint,omitempty"`
+	Previous   string             `json:"previous,omitempty"`
+	Current    string             `json:"current,omitempty"`
+	// BackwardCompatible is if data written with the from version can be read with the to version
+	BackwardCompatible bool `json:"backwardCompatible"`
+	// ForwardCompatible is if data written with the to version can be read with the from version
+	ForwardCompatible bool `json:"forwardCompatible"`
+}
+
+type ResponseGetSubjectVersionsDiff struct {
+	Subject    string                     `json:"subject"`
+	SchemaType schemas.SchemaType         `json:"schemaType,omitempty"`
+	From       SubjectVersionDiffVersion  `json:"from"`
+	To         SubjectVersionDiffVersion  `json:"to"`
+	Changes    []SubjectVersionDiffChange `json:"changes"`
+	// BackwardCompatible and ForwardCompatible are only true when every change is
+	BackwardCompatible bool `json:"backwardCompatible"`
+	ForwardCompatible  bool `json:"forwardCompatible"`
+}
+
+func (r *ResponseGetSubjectVersionsDiff) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
+
+type RequestPostCompatibility struct {
+	Schema     string             `json:"schema"`
+	SchemaType schemas.SchemaType `json:"schemaType"`
+	References []SubjectReference `json:"references,omitempty"`
+}
+
+func (r *RequestPostCompatibility) Bind(request *http.Request) error {
+	if len(r.Schema) == 0 {
+		return fmt.Errorf("schema may not be empty")
+	}
+
+	return nil
+}
+
+type ResponsePostCompatibility struct {
+	IsCompatible bool     `json:"is_compatible"`
+	Messages     []string `json:"messages,omitempty"`
+}
+
+func (r *ResponsePostCompatibility) Render(writer http.ResponseWriter, request *http.Request) error {
+	return nil
+}
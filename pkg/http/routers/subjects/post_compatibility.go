@@ -0,0 +1,148 @@
+package subjects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+func parseCompatibilitySchema(tx *gorm.DB, registry *Registry, subjectName string, data *RequestPostCompatibility) (schemas.SchemaType, schemas.ParsedSchema, error) {
+	schemaType, dbSchemaType, err := getSchemaType(data.SchemaType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawReferences := make([]string, 0)
+	rawReferenceNames := make([]string, 0)
+	for _, reference := range data.References {
+		referencesSlice, referencesMap, err := getSubjectVersionsReferencedBySubjectNameAndVersion(tx, registry, reference.Name, referenceSubjectName(subjectName, reference.Subject), reference.Version, dbSchemaType)
+		if err != nil {
+			return "", nil, err
+		}
+
+		for _, name := range referencesSlice {
+			rawReferences = append(rawReferences, referencesMap[name].Schema.Schema)
+			rawReferenceNames = append(rawReferenceNames, name)
+		}
+	}
+
+	if err := registry.checkReferencesLimit(len(rawReferenceNames)); err != nil {
+		return "", nil, err
+	}
+
+	parsedSchema, err := schemas.ParseSchema(data.Schema, schemaType, rawReferences, rawReferenceNames)
+	if err != nil {
+		return "", nil, routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("error parsing schema: %w", err))
+	}
+
+	return schemaType, parsedSchema, nil
+}
+
+func postCompatibilitySubjectVersion(db *gorm.DB, registry *Registry, subjectName string, version string, data *RequestPostCompatibility, verbose bool) (*ResponsePostCompatibility, error) {
+	resp := &ResponsePostCompatibility{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		subject, err := getSubjectByName(tx, subjectName, false)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40401, fmt.Errorf("subject not found"))
+			}
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+
+		versionModel, err := getSubjectVersionBySubjectID(tx, subject.ID, version, false)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return routers.NewAPIError(http.StatusNotFound, 40402, fmt.Errorf("version not found"))
+			}
+			return fmt.Errorf("error finding version %s for subject %s: %w", version, subjectName, err)
+		}
+
+		err = tx.Where("id = ?", versionModel.SchemaID).First(&versionModel.Schema).Error
+		if err != nil {
+			return fmt.Errorf("error finding schema for version %s for subject %s: %w", version, subjectName, err)
+		}
+
+		schemaType, parsedSchema, err := parseCompatibilitySchema(tx, registry, subjectName, data)
+		if err != nil {
+			return err
+		}
+
+		compatibility, err := dbModels.GetSubjectCompatibility(tx, subjectName)
+		if err != nil {
+			return fmt.Errorf("error finding compatibility for subject %s: %w", subjectName, err)
+		}
+
+		reasons, err := checkCompatibility(tx, registry, compatibility, schemaType, parsedSchema, []dbModels.SubjectVersion{*versionModel})
+		if err != nil {
+			return err
+		}
+
+		resp.IsCompatible = len(reasons) == 0
+		if verbose {
+			resp.Messages = reasons
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func postCompatibilitySubjectVersions(db *gorm.DB, registry *Registry, subjectName string, data *RequestPostCompatibility, verbose bool) (*ResponsePostCompatibility, error) {
+	resp := &ResponsePostCompatibility{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		schemaType, parsedSchema, err := parseCompatibilitySchema(tx, registry, subjectName, data)
+		if err != nil {
+			return err
+		}
+
+		subject, err := getSubjectByName(tx, subjectName, false)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// no versions to be incompatible with
+				resp.IsCompatible = true
+				return nil
+			}
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+
+		compatibility, err := dbModels.GetSubjectCompatibility(tx, subjectName)
+		if err != nil {
+			return fmt.Errorf("error finding compatibility for subject %s: %w", subjectName, err)
+		}
+
+		existingSchemaVersions, err := getSubjectVersionsForCompatibility(tx, subject.ID, compatibility)
+		if err != nil {
+			return err
+		}
+
+		reasons, err := checkCompatibility(tx, registry, compatibility, schemaType, parsedSchema, existingSchemaVersions)
+		if err != nil {
+			return err
+		}
+
+		resp.IsCompatible = len(reasons) == 0
+		if verbose {
+			resp.Messages = reasons
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
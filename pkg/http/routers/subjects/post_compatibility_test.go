@@ -0,0 +1,136 @@
+package subjects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostCompatibility(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestCompatibility := &RequestPostCompatibility{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field1", "type": "long"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestCompatibility.Bind(nil))
+
+	// unknown subject has nothing to be incompatible with
+	resp, err := postCompatibilitySubjectVersions(db, registry, "one", requestCompatibility, true)
+	assert.NoError(t, err)
+	assert.True(t, resp.IsCompatible)
+	assert.Empty(t, resp.Messages)
+
+	// unknown subject for a specific version
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "latest", requestCompatibility, true)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40401, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: requestCompatibility.Schema,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+
+	// invalid version
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "abc", requestCompatibility, true)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42202, apiError.ErrorCode)
+
+	// unknown version
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "5", requestCompatibility, true)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40402, apiError.ErrorCode)
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	w = httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	// invalid schema
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "1", &RequestPostCompatibility{Schema: "{"}, true)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+
+	// same schema is compatible
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "1", requestCompatibility, true)
+	assert.NoError(t, err)
+	assert.True(t, resp.IsCompatible)
+	assert.Empty(t, resp.Messages)
+
+	resp, err = postCompatibilitySubjectVersions(db, registry, "one", requestCompatibility, true)
+	assert.NoError(t, err)
+	assert.True(t, resp.IsCompatible)
+	assert.Empty(t, resp.Messages)
+
+	requestCompatibility = &RequestPostCompatibility{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field2", "type": "string"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestCompatibility.Bind(nil))
+
+	// incompatible without verbose has no messages
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "latest", requestCompatibility, false)
+	assert.NoError(t, err)
+	assert.False(t, resp.IsCompatible)
+	assert.Empty(t, resp.Messages)
+
+	// incompatible with verbose includes the reasons
+	resp, err = postCompatibilitySubjectVersion(db, registry, "one", "latest", requestCompatibility, true)
+	assert.NoError(t, err)
+	assert.False(t, resp.IsCompatible)
+	assert.Len(t, resp.Messages, 1)
+	assert.Contains(t, resp.Messages[0], "version 1: ")
+	assert.Contains(t, resp.Messages[0], "errorType:'READER_FIELD_MISSING_DEFAULT_VALUE'")
+
+	resp, err = postCompatibilitySubjectVersions(db, registry, "one", requestCompatibility, true)
+	assert.NoError(t, err)
+	assert.False(t, resp.IsCompatible)
+	assert.Len(t, resp.Messages, 1)
+
+	// checking compatibility doesn't register anything
+	versions, err := getSubjectVersions(db, "one", listOptions{})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int32{1}, *versions)
+}
@@ -11,27 +11,15 @@ import (
 	"gorm.io/gorm"
 )
 
-func postSubject(db *gorm.DB, subjectName string, data *RequestPostSubject) (*ResponsePostSubject, error) {
+func postSubject(db *gorm.DB, registry *Registry, subjectName string, data *RequestPostSubject) (*ResponsePostSubject, error) {
 	resp := &ResponsePostSubject{}
 
-	schemaType := schemas.SchemaTypeAvro
-	dbSchemaType := dbModels.SchemaTypeAvro
-	if len(data.SchemaType) > 0 {
-		schemaType = data.SchemaType
-		switch data.SchemaType {
-		case schemas.SchemaTypeAvro:
-			dbSchemaType = dbModels.SchemaTypeAvro
-		// TODO: uncomment once these other types are supported
-		// case schemas.SchemaTypeJSON:
-		// 	dbSchemaType = dbModels.SchemaTypeJSON
-		// case SchemaTypeProtobuf:
-		// 	dbSchemaType = dbModels.SchemaTypeProtobuf
-		default:
-			return nil, routers.NewAPIError(http.StatusBadRequest, http.StatusBadRequest, fmt.Errorf("unknown schema type: %s", data.SchemaType))
-		}
+	schemaType, dbSchemaType, err := getSchemaType(data.SchemaType)
+	if err != nil {
+		return nil, err
 	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
+	err = db.Transaction(func(tx *gorm.DB) error {
 		subject, err := getSubjectByName(tx, subjectName, false)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -44,7 +32,7 @@ func postSubject(db *gorm.DB, subjectName string, data *RequestPostSubject) (*Re
 		rawReferences := make([]string, 0)
 		rawReferenceNames := make([]string, 0)
 		for _, reference := range data.References {
-			referencesSlice, referencesMap, err := getSubjectVersionsReferencedBySubjectNameAndVersion(tx, reference.Name, reference.Subject, reference.Version, dbSchemaType)
+			referencesSlice, referencesMap, err := getSubjectVersionsReferencedBySubjectNameAndVersion(tx, registry, reference.Name, referenceSubjectName(subjectName, reference.Subject), reference.Version, dbSchemaType)
 			if err != nil {
 				return err
 			}
@@ -56,14 +44,29 @@ func postSubject(db *gorm.DB, subjectName string, data *RequestPostSubject) (*Re
 			}
 		}
 
-		_, err = schemas.ParseSchema(data.Schema, schemaType, rawReferences, rawReferenceNames)
+		parsedSchema, err := schemas.ParseSchema(data.Schema, schemaType, rawReferences, rawReferenceNames)
 		if err != nil {
 			return routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("error parsing schema: %w", err))
 		}
 
-		schema := &dbModels.Schema{}
-		err = tx.Clauses(forceIndexHint("idx_schemas_hash")).
-			Where("hash = ? AND schema_type = ?", data.calculatedHash, schemaType).First(schema).Error
+		if !data.normalize {
+			data.normalize, err = dbModels.GetSubjectNormalize(tx, subjectName)
+			if err != nil {
+				return fmt.Errorf("error finding normalize for subject %s: %w", subjectName, err)
+			}
+		}
+
+		var hash string
+		if data.normalize {
+			data.Schema, hash, err = normalizeSchema(subjectName, schemaType, parsedSchema, data.References)
+		} else {
+			hash, err = calculateSchemaHash(subjectName, schemaType, data.Schema, data.References)
+		}
+		if err != nil {
+			return err
+		}
+
+		schema, err := findSubjectSchema(tx, subject.ID, subjectName, hash, data.Metadata, data.RuleSet)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return routers.NewAPIError(http.StatusNotFound, 40403, fmt.Errorf("schema not found"))
@@ -72,7 +75,7 @@ func postSubject(db *gorm.DB, subjectName string, data *RequestPostSubject) (*Re
 		}
 
 		subjectVersion := &dbModels.SubjectVersion{}
-		err = tx.Clauses(forceIndexHint("idx_subject_id_schema_id")).
+		err = tx.Clauses(dbModels.ForceIndexHint("idx_subject_id_schema_id")).
 			Where("subject_id = ? AND schema_id = ?", subject.ID, schema.ID).First(subjectVersion).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -85,6 +88,19 @@ func postSubject(db *gorm.DB, subjectName string, data *RequestPostSubject) (*Re
 		resp.ID = schema.GlobalID
 		resp.Version = subjectVersion.Version
 		resp.Schema = schema.Schema
+		resp.Metadata, err = dbModels.DecodeMetadata(subjectVersion.Metadata)
+		if err != nil {
+			return err
+		}
+		resp.RuleSet, err = dbModels.DecodeRuleSet(subjectVersion.RuleSet)
+		if err != nil {
+			return err
+		}
+
+		if schemaType != schemas.SchemaTypeAvro {
+			// only set when not avro for compatibility
+			resp.SchemaType = schemaType
+		}
 
 		return nil
 	})
@@ -9,7 +9,9 @@ import (
 
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +19,7 @@ import (
 )
 
 func TestPostSubject(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -25,8 +27,10 @@ func TestPostSubject(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to post on empty db
-	resp, err := postSubject(db, "unknown", &RequestPostSubject{})
+	resp, err := postSubject(db, registry, "unknown", &RequestPostSubject{})
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -37,7 +41,7 @@ func TestPostSubject(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	// try to post bad schema type
-	resp, err = postSubject(db, "unknown", &RequestPostSubject{
+	resp, err = postSubject(db, registry, "unknown", &RequestPostSubject{
 		SchemaType: schemas.SchemaType("bad"),
 	})
 	apiError = &routers.APIError{}
@@ -50,7 +54,7 @@ func TestPostSubject(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 
 	// try to post on empty db good schema type
-	resp, err = postSubject(db, "unknown", &RequestPostSubject{
+	resp, err = postSubject(db, registry, "unknown", &RequestPostSubject{
 		SchemaType: schemas.SchemaTypeAvro,
 	})
 	apiError = &routers.APIError{}
@@ -63,9 +67,8 @@ func TestPostSubject(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	subjectOne := &dbModels.Subject{
-		ID:            uuid.New(),
-		Name:          "one",
-		Compatibility: dbModels.SubjectCompatibilityBackward,
+		ID:   uuid.New(),
+		Name: "one",
 	}
 	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(subjectOne).Error; err != nil {
@@ -74,9 +77,9 @@ func TestPostSubject(t *testing.T) {
 
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schemaString := `
@@ -90,14 +93,14 @@ func TestPostSubject(t *testing.T) {
 `
 			schemaString = fmt.Sprintf(schemaString, i)
 
-			hash, err := calculateSchemaHash(schemaString, nil)
+			hash, err := calculateSchemaHash("one", "", schemaString, nil)
 			if err != nil {
 				return err
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     schemaString,
 				Hash:       hash,
 				SchemaType: dbModels.SchemaTypeAvro,
@@ -122,9 +125,8 @@ func TestPostSubject(t *testing.T) {
 	assert.NoError(t, err)
 
 	subjectTwo := &dbModels.Subject{
-		ID:            uuid.New(),
-		Name:          "two",
-		Compatibility: dbModels.SubjectCompatibilityBackward,
+		ID:   uuid.New(),
+		Name: "two",
 	}
 	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(subjectTwo).Error; err != nil {
@@ -133,9 +135,9 @@ func TestPostSubject(t *testing.T) {
 
 		for i := 1; i <= 5; i++ {
 			// tx because sqlite doesn't allow multiple write transactions at once
-			globalID, err := dbModels.NextSequenceID(tx, dbModels.SequenceNameSchemaIDs)
+			globalID, err := registry.schemaIDs.Next(tx)
 			if err != nil {
-				return fmt.Errorf("error getting next sequence id: %w", err)
+				return fmt.Errorf("error getting next schema id: %w", err)
 			}
 
 			schemaString := `
@@ -149,14 +151,14 @@ func TestPostSubject(t *testing.T) {
 `
 			schemaString = fmt.Sprintf(schemaString, i)
 
-			hash, err := calculateSchemaHash(schemaString, nil)
+			hash, err := calculateSchemaHash("two", "", schemaString, nil)
 			if err != nil {
 				return err
 			}
 
 			schema := &dbModels.Schema{
 				ID:         uuid.New(),
-				GlobalID:   int32(globalID),
+				GlobalID:   globalID,
 				Schema:     schemaString,
 				Hash:       hash,
 				SchemaType: dbModels.SchemaTypeAvro,
@@ -181,7 +183,7 @@ func TestPostSubject(t *testing.T) {
 	assert.NoError(t, err)
 
 	// post subject invalid schema
-	resp, err = postSubject(db, "one", &RequestPostSubject{
+	resp, err = postSubject(db, registry, "one", &RequestPostSubject{
 		Schema: "bad",
 	})
 	apiError = &routers.APIError{}
@@ -194,7 +196,7 @@ func TestPostSubject(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
 
 	// post subject invalid references
-	resp, err = postSubject(db, "one", &RequestPostSubject{
+	resp, err = postSubject(db, registry, "one", &RequestPostSubject{
 		Schema: `{"type": "string"}`,
 		References: []SubjectReference{
 			{
@@ -214,7 +216,7 @@ func TestPostSubject(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
 
 	// post subject good references but schema not found
-	resp, err = postSubject(db, "two", &RequestPostSubject{
+	resp, err = postSubject(db, registry, "two", &RequestPostSubject{
 		Schema: `{"type": "string"}`,
 		References: []SubjectReference{
 			{
@@ -246,7 +248,7 @@ func TestPostSubject(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubject(db, "one", requestPostSubject)
+	resp, err = postSubject(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, "one", resp.Subject)
 	assert.Equal(t, int32(1), resp.ID)
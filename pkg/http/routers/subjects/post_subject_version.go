@@ -3,18 +3,21 @@ package subjects
 import (
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
 	"strings"
 
 	"github.com/google/uuid"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/transaction"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/ids"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
 	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 	"gorm.io/gorm"
 )
 
-func getSubjectVersionsReferencedBySubjectNameAndVersion(tx *gorm.DB, referenceName string, subjectName string, version int32, schemaType dbModels.SchemaType) ([]string, map[string]dbModels.SubjectVersion, error) {
+func getSubjectVersionsReferencedBySubjectNameAndVersion(tx *gorm.DB, registry *Registry, referenceName string, subjectName string, version int32, schemaType dbModels.SchemaType) ([]string, map[string]dbModels.SubjectVersion, error) {
 	referenceNames := make([]string, 0)
 	subjectVersions := make(map[string]dbModels.SubjectVersion)
 
@@ -31,7 +34,7 @@ func getSubjectVersionsReferencedBySubjectNameAndVersion(tx *gorm.DB, referenceN
 		return nil, nil, routers.NewAPIError(http.StatusConflict, 40901, fmt.Errorf("cannot reference schema with a different type"))
 	}
 
-	subReferences, err := getSchemaReferencesReferencedBySchemaID(tx, subjectVersion.Schema.ID, 0)
+	subReferences, err := getSchemaReferencesReferencedBySchemaID(tx, registry, subjectVersion.Schema.ID, 0)
 	if err != nil {
 		apiError := &routers.APIError{}
 		if errors.As(err, &apiError) {
@@ -51,16 +54,15 @@ func getSubjectVersionsReferencedBySubjectNameAndVersion(tx *gorm.DB, referenceN
 	return referenceNames, subjectVersions, nil
 }
 
-func getSchemaReferencesReferencedBySchemaID(tx *gorm.DB, schemaID uuid.UUID, recursions int) ([]dbModels.SchemaReference, error) {
-	if recursions >= 5 {
+func getSchemaReferencesReferencedBySchemaID(tx *gorm.DB, registry *Registry, schemaID uuid.UUID, recursions int) ([]dbModels.SchemaReference, error) {
+	if recursions >= registry.limits.MaxReferenceDepth {
 		// we need something here to stop long reference chains as the longer the chain the more db queries and the longer it'll take
 		// too long of a chain will eventually take things down
-		// TODO: make this configurable with a sane default
 		return nil, routers.NewAPIError(http.StatusConflict, 40902, fmt.Errorf("hit recursive schema limit, reference chain is too deep"))
 	}
 
 	schemaReferences := make([]dbModels.SchemaReference, 0)
-	err := tx.Clauses(forceIndexHint("idx_schema_id")).Joins("SubjectVersion").Joins("SubjectVersion.Schema").
+	err := tx.Clauses(dbModels.ForceIndexHint("idx_schema_id")).Joins("SubjectVersion").Joins("SubjectVersion.Schema").
 		Where("schema_references.schema_id = ?", schemaID).
 		Find(&schemaReferences).Error
 	if err != nil {
@@ -70,7 +72,7 @@ func getSchemaReferencesReferencedBySchemaID(tx *gorm.DB, schemaID uuid.UUID, re
 	totalSchemaReferences := make([]dbModels.SchemaReference, 0)
 
 	for _, schemaReference := range schemaReferences {
-		subReferences, err := getSchemaReferencesReferencedBySchemaID(tx, schemaReference.SubjectVersion.Schema.ID, recursions+1)
+		subReferences, err := getSchemaReferencesReferencedBySchemaID(tx, registry, schemaReference.SubjectVersion.Schema.ID, recursions+1)
 		if err != nil {
 			apiError := &routers.APIError{}
 			if errors.As(err, &apiError) {
@@ -83,301 +85,349 @@ func getSchemaReferencesReferencedBySchemaID(tx *gorm.DB, schemaID uuid.UUID, re
 		totalSchemaReferences = append(totalSchemaReferences, schemaReference)
 	}
 
-	// TODO: we probably also want to limit the size of totalSchemaReferences for the same reasons we limit the reference chain size
-
 	return totalSchemaReferences, nil
 }
 
-func postSubjectVersion(db *gorm.DB, nextSequenceTx *gorm.DB, subjectName string, data *RequestPostSubjectVersion) (*ResponsePostSubjectVersion, error) {
-	resp := &ResponsePostSubjectVersion{}
-
-	schemaType := schemas.SchemaTypeAvro
-	dbSchemaType := dbModels.SchemaTypeAvro
-	if len(data.SchemaType) > 0 {
-		schemaType = data.SchemaType
-		switch data.SchemaType {
-		case schemas.SchemaTypeAvro:
-			dbSchemaType = dbModels.SchemaTypeAvro
-		// TODO: uncomment once these other types are supported
-		// case schemas.SchemaTypeJSON:
-		// 	dbSchemaType = dbModels.SchemaTypeJSON
-		// case SchemaTypeProtobuf:
-		// 	dbSchemaType = dbModels.SchemaTypeProtobuf
-		default:
-			return nil, routers.NewAPIError(http.StatusBadRequest, http.StatusBadRequest, fmt.Errorf("unknown schema type: %s", data.SchemaType))
+// registration is the outcome of registering a schema as a version of a subject
+type registration struct {
+	id         int32
+	version    int32
+	schemaType schemas.SchemaType
+	outcome    metrics.RegistrationOutcome
+	// allocatedID is the id handed out for a new schema by allocator, it's given back when the transaction fails
+	allocatedID int32
+	allocator   *ids.Allocator
+}
+
+// releaseID gives back the id handed out for a new schema
+func (r *registration) releaseID() {
+	if r.allocatedID != 0 {
+		r.allocator.Release(r.allocatedID)
+	}
+}
+
+// registerSubjectVersion registers the schema as a version of the subject in the transaction, reg is filled in
+// as it goes so the allocated id is known even when it fails
+func registerSubjectVersion(tx *gorm.DB, registry *Registry, subjectName string, data *RequestPostSubjectVersion, reg *registration) error {
+	schemaType, dbSchemaType, err := getSchemaType(data.SchemaType)
+	if err != nil {
+		return err
+	}
+
+	reg.schemaType = schemaType
+	// the schema already existed unless we end up creating it
+	reg.outcome = metrics.RegistrationOutcomeExistingSchema
+
+	mode, err := getWritableSubjectMode(tx, subjectName)
+	if err != nil {
+		return err
+	}
+
+	if mode != dbModels.SubjectModeImport && (data.ID != 0 || data.Version != 0) {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("schema id and version can only be set when subject %s is in IMPORT mode", subjectName))
+	}
+
+	subjectVersionReferences := make(map[string]dbModels.SubjectVersion)
+	newRawReferences := make([]string, 0)
+	rawReferenceNames := make([]string, 0)
+	for _, reference := range data.References {
+		referencesSlice, referencesMap, err := getSubjectVersionsReferencedBySubjectNameAndVersion(tx, registry, reference.Name, referenceSubjectName(subjectName, reference.Subject), reference.Version, dbSchemaType)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range referencesSlice {
+			subjectVersionReferences[name] = referencesMap[name]
+			newRawReferences = append(newRawReferences, referencesMap[name].Schema.Schema)
+			rawReferenceNames = append(rawReferenceNames, name)
 		}
 	}
 
-	err := db.Transaction(func(tx *gorm.DB) error {
-		if nextSequenceTx == nil {
-			nextSequenceTx = tx
+	if err := registry.checkReferencesLimit(len(rawReferenceNames)); err != nil {
+		return err
+	}
+
+	parsedSchema, err := schemas.ParseSchema(data.Schema, schemaType, newRawReferences, rawReferenceNames)
+	if err != nil {
+		metrics.ObserveSchemaRegistration(string(schemaType), metrics.RegistrationOutcomeParseError)
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("error parsing schema: %w", err))
+	}
+
+	if !data.normalize {
+		data.normalize, err = dbModels.GetSubjectNormalize(tx, subjectName)
+		if err != nil {
+			return fmt.Errorf("error finding normalize for subject %s: %w", subjectName, err)
 		}
+	}
 
-		subjectVersionReferences := make(map[string]dbModels.SubjectVersion)
-		newRawReferences := make([]string, 0)
-		rawReferenceNames := make([]string, 0)
-		for _, reference := range data.References {
-			referencesSlice, referencesMap, err := getSubjectVersionsReferencedBySubjectNameAndVersion(tx, reference.Name, reference.Subject, reference.Version, dbSchemaType)
-			if err != nil {
-				return err
-			}
+	var hash string
+	if data.normalize {
+		data.Schema, hash, err = normalizeSchema(subjectName, schemaType, parsedSchema, data.References)
+	} else {
+		hash, err = calculateSchemaHash(subjectName, schemaType, data.Schema, data.References)
+	}
+	if err != nil {
+		return err
+	}
 
-			for _, name := range referencesSlice {
-				subjectVersionReferences[name] = referencesMap[name]
-				newRawReferences = append(newRawReferences, referencesMap[name].Schema.Schema)
-				rawReferenceNames = append(rawReferenceNames, name)
-			}
+	subject, err := getSubjectByName(tx, subjectName, true)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) == false {
+			return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
+		}
+		subject = nil
+	}
+
+	// if subject is nil, create it
+	if subject == nil {
+		subject = &dbModels.Subject{
+			ID:      uuid.New(),
+			Name:    subjectName,
+			Context: dbModels.SubjectContextColumn(subjectName),
 		}
+		if err := tx.Create(subject).Error; err != nil {
+			return fmt.Errorf("error creating subject: %s: %w", subjectName, err)
+		}
+	}
 
-		parsedSchema, err := schemas.ParseSchema(data.Schema, schemaType, newRawReferences, rawReferenceNames)
+	// subject was soft deleted and now we want it back
+	if subject.DeletedAt.Valid {
+		err := tx.Unscoped().Model(subject).Update("deleted_at", nil).Error
 		if err != nil {
-			return routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("error parsing schema: %w", err))
+			return fmt.Errorf("error unsoft deleting subject: %s: %w", subjectName, err)
 		}
+	}
 
-		subject, err := getSubjectByName(tx, subjectName, true)
+	compatibility, err := dbModels.GetSubjectCompatibility(tx, subjectName)
+	if err != nil {
+		return fmt.Errorf("error finding compatibility for subject %s: %w", subjectName, err)
+	}
+
+	// checking compatibility, importing skips this as the schemas were already checked by the registry they came from
+	if mode != dbModels.SubjectModeImport && compatibility != dbModels.SubjectCompatibilityNone {
+		existingSchemaVersions, err := getSubjectVersionsForCompatibility(tx, subject.ID, compatibility)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) == false {
-				return fmt.Errorf("error finding subject: %s: %w", subjectName, err)
-			}
-			subject = nil
+			return err
 		}
 
-		// if subject is nil, create it
-		if subject == nil {
-			subject = &dbModels.Subject{
-				ID:            uuid.New(),
-				Name:          subjectName,
-				Compatibility: dbModels.SubjectCompatibilityBackward,
-			}
-			if err := tx.Create(subject).Error; err != nil {
-				return fmt.Errorf("error creating subject: %s: %w", subjectName, err)
-			}
+		reasons, err := checkCompatibility(tx, registry, compatibility, schemaType, parsedSchema, existingSchemaVersions)
+		if err != nil {
+			return err
 		}
 
-		// subject was soft deleted and now we want it back
-		if subject.DeletedAt.Valid {
-			err := tx.Unscoped().Model(subject).Update("deleted_at", nil).Error
-			if err != nil {
-				return fmt.Errorf("error unsoft deleting subject: %s: %w", subjectName, err)
-			}
+		if len(reasons) > 0 {
+			metrics.ObserveSchemaRegistration(string(schemaType), metrics.RegistrationOutcomeIncompatible)
+			return routers.NewAPIError(http.StatusConflict, http.StatusConflict, fmt.Errorf("schema is incompatible with an earlier schema: %s", strings.Join(reasons, "; ")))
 		}
+	}
 
-		// checking compatibility
-		if subject.Compatibility != dbModels.SubjectCompatibilityNone {
-
-			existingSchemaVersions := make([]dbModels.SubjectVersion, 0)
-			query := tx.Joins("Schema").Where("subject_versions.subject_id = ?", subject.ID).Order("subject_versions.version desc").Find(&existingSchemaVersions)
-
-			// check if we are transitive
-			if !strings.HasSuffix(string(subject.Compatibility), "_TRANSITIVE") {
-				// not transitive so we only need the first one
-				query = query.Limit(1)
-			} else {
-				// we are transitive, this is most likely a very expensive operation, so it's probably not a good idea to do
-				// this query could return tons of rows and require tons of comparisons
-				// we probably could limit this impact by having a configurable maximum versions per subject
-				query = query.Limit(-1)
-			}
+	metadata, ruleSet, err := resolveDataContract(tx, subjectName, subject.ID, data.Metadata, data.RuleSet)
+	if err != nil {
+		return err
+	}
 
-			err := query.Find(&existingSchemaVersions).Error
-			if err != nil {
-				return fmt.Errorf("error finding existing schemas for compatibility checking: %w", err)
-			}
+	rawMetadata, err := dbModels.EncodeMetadata(metadata)
+	if err != nil {
+		return err
+	}
 
-			var existingParsedSchemas []schemas.ParsedSchema
-			for _, existingSchemaVersion := range existingSchemaVersions {
-				references := make([]string, 0)
-				referenceNames := make([]string, 0)
-
-				// if it exists it means the original schema passed recursion validation
-				// so let's set it to -1 to offset any weirdness
-				schemaReferences, err := getSchemaReferencesReferencedBySchemaID(tx, existingSchemaVersion.Schema.ID, -1)
-				if err != nil {
-					return err
-				}
-
-				for _, schemaReference := range schemaReferences {
-					references = append(references, schemaReference.SubjectVersion.Schema.Schema)
-					referenceNames = append(referenceNames, schemaReference.Name)
-				}
-
-				existingParsedSchema, err := schemas.ParseSchema(existingSchemaVersion.Schema.Schema, schemaType, references, referenceNames)
-				if err != nil {
-					return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error parsing existing: %w", err))
-				}
-
-				existingParsedSchemas = append(existingParsedSchemas, existingParsedSchema)
-			}
+	rawRuleSet, err := dbModels.EncodeRuleSet(ruleSet)
+	if err != nil {
+		return err
+	}
 
-			compatible := true
-			switch subject.Compatibility {
-			case dbModels.SubjectCompatibilityBackward:
-				fallthrough
-			case dbModels.SubjectCompatibilityBackwardTransitive:
-				for _, existingParsedSchema := range existingParsedSchemas {
-					isBackwardsCompatible, err := parsedSchema.IsBackwardsCompatible(existingParsedSchema)
-					if err != nil {
-						return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
-					}
-
-					if isBackwardsCompatible == false {
-						compatible = false
-						break
-					}
-				}
-				break
-			case dbModels.SubjectCompatibilityForward:
-				fallthrough
-			case dbModels.SubjectCompatibilityForwardTransitive:
-				for _, existingParsedSchema := range existingParsedSchemas {
-					isBackwardsCompatible, err := existingParsedSchema.IsBackwardsCompatible(parsedSchema)
-					if err != nil {
-						return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
-					}
-
-					if isBackwardsCompatible == false {
-						compatible = false
-						break
-					}
-				}
-				break
-			case dbModels.SubjectCompatibilityFull:
-				fallthrough
-			case dbModels.SubjectCompatibilityFullTransitive:
-				for _, existingParsedSchema := range existingParsedSchemas {
-					isBackwardsCompatible, err := parsedSchema.IsBackwardsCompatible(existingParsedSchema)
-					if err != nil {
-						return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
-					}
-
-					if isBackwardsCompatible == false {
-						compatible = false
-						break
-					}
-
-					isBackwardsCompatible, err = existingParsedSchema.IsBackwardsCompatible(parsedSchema)
-					if err != nil {
-						return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
-					}
-
-					if isBackwardsCompatible == false {
-						compatible = false
-						break
-					}
-				}
-				break
-			}
+	// the data contract sent is part of the schema so the same schema with different metadata or rules gets its own id
+	contractHash, err := dataContractHash(data.Metadata, data.RuleSet)
+	if err != nil {
+		return err
+	}
+	context := dbModels.SubjectContextColumn(subjectName)
 
-			if compatible == false {
-				return routers.NewAPIError(http.StatusConflict, http.StatusConflict, fmt.Errorf("schema is incompatible with an earlier schema"))
-			}
+	// a version of the subject that already has the schema is registered again
+	schema, err := findSubjectSchema(tx, subject.ID, subjectName, hash, data.Metadata, data.RuleSet)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) == false {
+			return fmt.Errorf("error finding schema for subject %s: %w", subjectName, err)
 		}
 
-		schema := &dbModels.Schema{}
-		err = tx.Clauses(forceIndexHint("idx_schemas_hash")).
-			Where("hash = ?", data.calculatedHash).First(schema).Error
+		schema = &dbModels.Schema{}
+		err = tx.Clauses(dbModels.ForceIndexHint("idx_schemas_context_hash")).
+			Where("context = ? AND hash = ? AND contract_hash = ?", context, hash, contractHash).First(schema).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) == false {
 				return fmt.Errorf("error finding schema for subject %s: %w", subjectName, err)
 			}
 			schema = nil
 		}
+	}
+
+	if schema != nil && data.ID != 0 && schema.GlobalID != data.ID {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("schema is already registered with id %d instead of %d", schema.GlobalID, data.ID))
+	}
 
-		// if schema is nil, create it
-		if schema == nil {
-			// get the next sequence, use the normal db as we don't want a nested transaction
-			nextId, err := dbModels.NextSequenceID(nextSequenceTx, dbModels.SequenceNameSchemaIDs)
+	// if schema is nil, create it
+	if schema == nil {
+		reg.outcome = metrics.RegistrationOutcomeNewSchema
+
+		allocator := registry.schemaIDAllocator(subjectName)
+
+		var nextId int32
+		if data.ID != 0 {
+			err = tx.Clauses(dbModels.ForceIndexHint("idx_schemas_global_id")).
+				Where("global_id = ?", data.ID).First(&dbModels.Schema{}).Error
+			if err == nil {
+				return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("overwrite new schema with id %d is not permitted", data.ID))
+			}
+			if errors.Is(err, gorm.ErrRecordNotFound) == false {
+				return fmt.Errorf("error finding schema with id %d: %w", data.ID, err)
+			}
+
+			// make sure the sequence never hands out the imported id
+			if err := allocator.Ensure(tx, data.ID); err != nil {
+				return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error updating schema id sequence: %w", err))
+			}
+
+			nextId = data.ID
+		} else {
+			nextId, err = allocator.Next(tx)
 			if err != nil {
 				return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error generating next schema id: %w", err))
 			}
+			reg.allocatedID = nextId
+			reg.allocator = allocator
+		}
 
-			if nextId > math.MaxInt32 {
-				return routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("too many schemas registered next schema id is greater than int32"))
-			}
+		// create it
+		schema = &dbModels.Schema{
+			ID:           uuid.New(),
+			GlobalID:     nextId,
+			Schema:       data.Schema,
+			Hash:         hash,
+			Context:      context,
+			ContractHash: contractHash,
+			SchemaType:   dbSchemaType,
+		}
+		if err := tx.Create(schema).Error; err != nil {
+			return fmt.Errorf("error creating schema for subject: %s: %w", subjectName, err)
+		}
 
-			// create it
-			schema = &dbModels.Schema{
-				ID:         uuid.New(),
-				GlobalID:   int32(nextId),
-				Schema:     data.Schema,
-				Hash:       data.calculatedHash,
-				SchemaType: dbSchemaType,
+		for _, reference := range data.References {
+			dbReference := &dbModels.SchemaReference{
+				ID:               uuid.New(),
+				SchemaID:         schema.ID,                                   // The schema that we are creating
+				SubjectVersionID: subjectVersionReferences[reference.Name].ID, // The subject version that we are referencing
+				Name:             reference.Name,
 			}
-			if err := tx.Create(schema).Error; err != nil {
-				return fmt.Errorf("error creating schema for subject: %s: %w", subjectName, err)
+			if err := tx.Create(dbReference).Error; err != nil {
+				return fmt.Errorf("error creating schema reference for subject: %s: %w", subjectName, err)
 			}
+		}
+	}
+	reg.id = schema.GlobalID
 
-			for _, reference := range data.References {
-				dbReference := &dbModels.SchemaReference{
-					ID:               uuid.New(),
-					SchemaID:         schema.ID,                                   // The schema that we are creating
-					SubjectVersionID: subjectVersionReferences[reference.Name].ID, // The subject version that we are referencing
-					Name:             reference.Name,
-				}
-				if err := tx.Create(dbReference).Error; err != nil {
-					return fmt.Errorf("error creating schema reference for subject: %s: %w", subjectName, err)
-				}
-			}
+	subjectVersion := &dbModels.SubjectVersion{}
+	err = tx.Clauses(dbModels.ForceIndexHint("idx_subject_id_schema_id")).
+		Where("subject_id = ? AND schema_id = ?", subject.ID, schema.ID).First(subjectVersion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) == false {
+			return fmt.Errorf("error finding subject version for subject %s: %w", subjectName, err)
 		}
-		resp.ID = schema.GlobalID
+		subjectVersion = nil
+	}
+
+	if subjectVersion != nil && data.Version != 0 && subjectVersion.Version != data.Version {
+		return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("schema is already registered as version %d instead of %d", subjectVersion.Version, data.Version))
+	}
 
-		subjectVersion := &dbModels.SubjectVersion{}
-		err = tx.Clauses(forceIndexHint("idx_subject_id_schema_id")).
-			Where("subject_id = ? AND schema_id = ?", subject.ID, schema.ID).First(subjectVersion).Error
+	// if subject version is nil create it
+	if subjectVersion == nil {
+		latestVersion := &dbModels.SubjectVersion{}
+		latestVersionNum := int32(1)
+		// unscoped because we need to include soft deleted and skip that version if it's soft deleted
+		err = tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_subject_versions_subject_id")).
+			Order("version desc").Where("subject_id = ?", subject.ID).First(latestVersion).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) == false {
-				return fmt.Errorf("error finding subject version for subject %s: %w", subjectName, err)
+				return fmt.Errorf("error finding latest version for subject %s: %w", subjectName, err)
 			}
-			subjectVersion = nil
+			latestVersion = nil
 		}
 
-		// if subject version is nil create it
-		if subjectVersion == nil {
-			latestVersion := &dbModels.SubjectVersion{}
-			latestVersionNum := int32(1)
-			// unscoped because we need to include soft deleted and skip that version if it's soft deleted
-			err = tx.Unscoped().Clauses(forceIndexHint("idx_subject_versions_subject_id")).
-				Order("version desc").Where("subject_id = ?", subject.ID).First(latestVersion).Error
+		if latestVersion != nil {
+			latestVersionNum = latestVersion.Version + 1
+
+			latestSchema := &dbModels.Schema{}
+			err = tx.Where("id = ?", latestVersion.SchemaID).First(latestSchema).Error
 			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) == false {
-					return fmt.Errorf("error finding latest version for subject %s: %w", subjectName, err)
-				}
-				latestVersion = nil
+				return fmt.Errorf("error finding schema for subject latest version %s: %w", subjectName, err)
 			}
 
-			if latestVersion != nil {
-				latestVersionNum = latestVersion.Version + 1
-
-				latestSchema := &dbModels.Schema{}
-				err = tx.Where("id = ?", latestVersion.SchemaID).First(latestSchema).Error
-				if err != nil {
-					return fmt.Errorf("error finding schema for subject latest version %s: %w", subjectName, err)
-				}
-
-				// TODO: match the error message from confluent schema registry
-				// TODO: this takes into account deleted versions, does confluent do that?
-				if latestSchema.SchemaType != schema.SchemaType {
-					return routers.NewAPIError(http.StatusConflict, http.StatusConflict, fmt.Errorf("cannot add version of a different schema type"))
-				}
+			// TODO: match the error message from confluent schema registry
+			// TODO: this takes into account deleted versions, does confluent do that?
+			if latestSchema.SchemaType != schema.SchemaType {
+				return routers.NewAPIError(http.StatusConflict, http.StatusConflict, fmt.Errorf("cannot add version of a different schema type"))
 			}
+		}
 
-			subjectVersion = &dbModels.SubjectVersion{
-				ID:        uuid.New(),
-				SubjectID: subject.ID,
-				SchemaID:  schema.ID,
-				Version:   latestVersionNum,
+		if data.Version != 0 {
+			// unscoped because soft deleted versions still own their version number
+			err = tx.Unscoped().Clauses(dbModels.ForceIndexHint("idx_subject_id_version")).
+				Where("subject_id = ? AND version = ?", subject.ID, data.Version).First(&dbModels.SubjectVersion{}).Error
+			if err == nil {
+				return routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("overwrite version %d for subject %s is not permitted", data.Version, subjectName))
 			}
-			if err := tx.Create(subjectVersion).Error; err != nil {
-				return fmt.Errorf("error creating version for subject: %s: %w", subjectName, err)
+			if errors.Is(err, gorm.ErrRecordNotFound) == false {
+				return fmt.Errorf("error finding version %d for subject %s: %w", data.Version, subjectName, err)
 			}
+
+			latestVersionNum = data.Version
 		}
 
-		return nil
+		subjectVersion = &dbModels.SubjectVersion{
+			ID:        uuid.New(),
+			SubjectID: subject.ID,
+			SchemaID:  schema.ID,
+			Version:   latestVersionNum,
+			Metadata:  rawMetadata,
+			RuleSet:   rawRuleSet,
+		}
+		if err := tx.Create(subjectVersion).Error; err != nil {
+			return fmt.Errorf("error creating version for subject: %s: %w", subjectName, err)
+		}
+
+		err = registry.publisher.Publish(tx, events.Event{
+			Kind:      events.KindSubjectVersion,
+			Subject:   subjectName,
+			Versions:  []int32{subjectVersion.Version},
+			SchemaIDs: []uuid.UUID{schema.ID},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	reg.version = subjectVersion.Version
+
+	return nil
+}
+
+func postSubjectVersion(db *gorm.DB, registry *Registry, subjectName string, data *RequestPostSubjectVersion) (*ResponsePostSubjectVersion, error) {
+	reg := &registration{}
+
+	// concurrent registrations to the same subject conflict on the version so the loser runs again
+	err := transaction.Run(db, func(tx *gorm.DB) error {
+		reg.releaseID()
+		*reg = registration{}
+
+		return registerSubjectVersion(tx, registry, subjectName, data, reg)
 	})
 
 	if err != nil {
+		reg.releaseID()
 		return nil, err
 	}
 
-	return resp, nil
+	metrics.ObserveSchemaRegistration(string(reg.schemaType), reg.outcome)
+
+	return &ResponsePostSubjectVersion{ID: reg.id}, nil
 }
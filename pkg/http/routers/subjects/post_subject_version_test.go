@@ -1,19 +1,25 @@
 package subjects
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -21,8 +27,10 @@ func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// try to post on empty db
-	resp, err := postSubjectVersion(db, nil, "unknown", &RequestPostSubjectVersion{})
+	resp, err := postSubjectVersion(db, registry, "unknown", &RequestPostSubjectVersion{})
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -33,7 +41,7 @@ func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
 
 	// try to post bad schema type
-	resp, err = postSubjectVersion(db, nil, "unknown", &RequestPostSubjectVersion{
+	resp, err = postSubjectVersion(db, registry, "unknown", &RequestPostSubjectVersion{
 		SchemaType: schemas.SchemaType("bad"),
 	})
 	apiError = &routers.APIError{}
@@ -46,7 +54,7 @@ func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
 
 	// try to post on empty db good schema type
-	resp, err = postSubjectVersion(db, nil, "unknown", &RequestPostSubjectVersion{
+	resp, err = postSubjectVersion(db, registry, "unknown", &RequestPostSubjectVersion{
 		SchemaType: schemas.SchemaTypeAvro,
 	})
 	apiError = &routers.APIError{}
@@ -59,7 +67,7 @@ func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
 	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
 
 	// post subject invalid references
-	resp, err = postSubjectVersion(db, nil, "one", &RequestPostSubjectVersion{
+	resp, err = postSubjectVersion(db, registry, "one", &RequestPostSubjectVersion{
 		Schema: `{"type": "string"}`,
 		References: []SubjectReference{
 			{
@@ -80,7 +88,7 @@ func TestPostSubjectVersionBadAndInvalid(t *testing.T) {
 }
 
 func TestPostSubjectVersionAvro(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -88,6 +96,8 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// post good schema
 	requestPostSubject := &RequestPostSubjectVersion{
 		Schema: `
@@ -101,12 +111,12 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err := postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
 	// post the same schema again
-	resp, err = postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
@@ -124,7 +134,7 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(2), resp.ID)
 
@@ -141,7 +151,7 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -152,7 +162,7 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
 
 	// delete subject
-	_, err = deleteSubject(db, "one", false)
+	_, err = deleteSubject(db, registry, "one", false)
 	assert.NoError(t, err)
 
 	// recreate subject
@@ -168,13 +178,13 @@ func TestPostSubjectVersionAvro(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 }
 
 func TestPostSubjectVersionAvroReferences(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -182,6 +192,8 @@ func TestPostSubjectVersionAvroReferences(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	requestPostSubject := &RequestPostSubjectVersion{
 		Schema: `
 {
@@ -194,7 +206,7 @@ func TestPostSubjectVersionAvroReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err := postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
@@ -218,7 +230,7 @@ func TestPostSubjectVersionAvroReferences(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "two", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(2), resp.ID)
 
@@ -235,7 +247,7 @@ func TestPostSubjectVersionAvroReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "two", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -247,7 +259,7 @@ func TestPostSubjectVersionAvroReferences(t *testing.T) {
 }
 
 func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -255,6 +267,8 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	requestPostSubject := &RequestPostSubjectVersion{
 		Schema: `
 {
@@ -267,7 +281,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err := postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
@@ -290,7 +304,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "two", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(2), resp.ID)
 
@@ -313,7 +327,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "three", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "three", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(3), resp.ID)
 
@@ -336,7 +350,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "four", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "four", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(4), resp.ID)
 
@@ -359,7 +373,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "five", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "five", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(5), resp.ID)
 
@@ -382,7 +396,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "six", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "six", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(6), resp.ID)
 
@@ -405,7 +419,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "seven", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "seven", requestPostSubject)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -417,7 +431,7 @@ func TestPostSubjectVersionAvroReferencesLongChain(t *testing.T) {
 }
 
 func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -425,6 +439,8 @@ func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	// create a new schema that references self
 	requestPostSubject := &RequestPostSubjectVersion{
 		Schema: `
@@ -438,7 +454,7 @@ func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err := postSubjectVersion(db, nil, "three", requestPostSubject)
+	resp, err := postSubjectVersion(db, registry, "three", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
@@ -455,7 +471,7 @@ func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "four", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "four", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(2), resp.ID)
 
@@ -472,7 +488,7 @@ func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "five", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "five", requestPostSubject)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -484,7 +500,7 @@ func TestPostSubjectVersionAvroSelfReferences(t *testing.T) {
 }
 
 func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
-	db, dbFile := TempDatabase(t)
+	db, dbFile := testdb.TempDatabase(t)
 	defer func() {
 		err := os.Remove(dbFile)
 		if err != nil {
@@ -492,6 +508,8 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 		}
 	}()
 
+	registry := newTestRegistry(t, db, configuration.Default())
+
 	requestPostSubject := &RequestPostSubjectVersion{
 		Schema: `
 {
@@ -504,7 +522,7 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 `,
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err := postSubjectVersion(db, nil, "one", requestPostSubject)
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), resp.ID)
 
@@ -528,7 +546,7 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "five", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "five", requestPostSubject)
 	apiError := &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -559,7 +577,7 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "five", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "five", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(2), resp.ID)
 
@@ -583,7 +601,7 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "six", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "six", requestPostSubject)
 	apiError = &routers.APIError{}
 	assert.ErrorAs(t, err, &apiError)
 	assert.Nil(t, resp)
@@ -613,15 +631,1345 @@ func TestPostSubjectVersionAvroOverwriteReferences(t *testing.T) {
 		},
 	}
 	assert.NoError(t, requestPostSubject.Bind(nil))
-	resp, err = postSubjectVersion(db, nil, "six", requestPostSubject)
+	resp, err = postSubjectVersion(db, registry, "six", requestPostSubject)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(3), resp.ID)
 }
 
-func TestPostSubjectVersionNewVersionDifferentSchemaTypes(t *testing.T) {
-	// TODO: create a avro subject then try and create a new version that is a json schema type
+func TestPostSubjectVersionCompatibilityConfig(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field1", "type": "long"}
+  ]
 }
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
 
-func TestPostSubjectVersionReferenceDifferentSchemaTypes(t *testing.T) {
-	// TODO: create a avro subject then try and create a new subject that is of a different type that references the first subject
+	// not backward compatible, but the subject doesn't check compatibility
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "one",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+	{"name": "field2", "type": "string"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	// global config is used by other subjects
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       dbModels.ConfigSubjectGlobal,
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_two",
+  "fields": [
+    {"name": "field1", "type": "long"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ID)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_two",
+  "fields": [
+    {"name": "field2", "type": "string"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), resp.ID)
+
+	// subject config overrides global
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "two",
+		Compatibility: dbModels.SubjectCompatibilityBackward,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_two",
+  "fields": [
+    {"name": "field3", "type": "string"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+}
+
+func TestPostSubjectVersionMode(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field1", "type": "long"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// id can't be set when not importing
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "string"}`,
+		ID:     100,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// read only subjects can't have new versions or be deleted
+	assert.NoError(t, db.Create(&dbModels.Mode{
+		ID:      uuid.New(),
+		Subject: "one",
+		Mode:    dbModels.SubjectModeReadOnly,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field1", "type": "long"},
+    {"name": "field2", "type": "long"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	_, err = deleteSubjectVersion(db, registry, "one", "1", false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	_, err = deleteSubject(db, registry, "one", false)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// import keeps the given id and version and skips compatibility
+	assert.NoError(t, db.Create(&dbModels.Mode{
+		ID:      uuid.New(),
+		Subject: "two",
+		Mode:    dbModels.SubjectModeImport,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema:  `{"type": "string"}`,
+		ID:      100,
+		Version: 5,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(100), resp.ID)
+	versionResp, err := getSubjectVersion(db, "two", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), versionResp.Version)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema:  `{"type": "long"}`,
+		ID:      50,
+		Version: 2,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(50), resp.ID)
+
+	// importing the same schema again is fine
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(50), resp.ID)
+
+	// can't reuse an id for a different schema
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "int"}`,
+		ID:     100,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// can't reuse a version for a different schema
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema:  `{"type": "int"}`,
+		Version: 5,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42205, apiError.ErrorCode)
+
+	// new schemas are given ids after the imported ones
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "int"}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "three", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(101), resp.ID)
+}
+
+func TestPostSubjectVersionJSON(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// post invalid schema
+	requestPostSubject := &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema:     `{"type": "bad"}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+
+	// post good schema
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"type": "integer"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// post the same schema again
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// lookup the schema
+	respPostSubject, err := postSubject(db, registry, "one", &RequestPostSubject{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema:     requestPostSubject.Schema,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), respPostSubject.ID)
+	assert.Equal(t, int32(1), respPostSubject.Version)
+	assert.Equal(t, schemas.SchemaTypeJSON, respPostSubject.SchemaType)
+
+	// post new version that is backward compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"type": "integer"},
+    "field2": {"type": "string"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	respGetSubjectVersion, err := getSubjectVersion(db, "one", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), respGetSubjectVersion.Version)
+	assert.Equal(t, schemas.SchemaTypeJSON, respGetSubjectVersion.SchemaType)
+
+	// post a new version that is not backward compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field2": {"type": "string"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+
+	// delete subject
+	_, err = deleteSubject(db, registry, "one", false)
+	assert.NoError(t, err)
+
+	// recreate subject
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"type": "integer"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+}
+
+func TestPostSubjectVersionJSONReferences(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"type": "integer"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// reference to a name that doesn't exist
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"$ref": "unknown.json"}
+  }
+}
+`,
+		References: []SubjectReference{
+			{
+				Name:    "one.json",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+
+	// create new schema that references one
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"$ref": "one.json"}
+  }
+}
+`,
+		References: []SubjectReference{
+			{
+				Name:    "one.json",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	// create new version that changes reference so isn't compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"type": "string"}
+  }
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+}
+
+func TestPostSubjectVersionProtobuf(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// post invalid schema
+	requestPostSubject := &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema:     `syntax = "proto3"; message One {`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+
+	// post good schema
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package one;
+
+import "google/protobuf/timestamp.proto";
+
+message One {
+  int64 field1 = 1;
+  google.protobuf.Timestamp created_at = 2;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// post the same schema again
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// post new version that is backward compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package one;
+
+import "google/protobuf/timestamp.proto";
+
+message One {
+  int64 field1 = 1;
+  google.protobuf.Timestamp created_at = 2;
+  string field2 = 3;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	respGetSubjectVersion, err := getSubjectVersion(db, "one", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), respGetSubjectVersion.Version)
+	assert.Equal(t, schemas.SchemaTypeProtobuf, respGetSubjectVersion.SchemaType)
+
+	// post a new version that is not backward compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package one;
+
+message One {
+  string field1 = 1;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "one.One.field1: field scalar kind changed from int64 to string")
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+}
+
+func TestPostSubjectVersionProtobufReferences(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package one;
+
+message One {
+  int64 field1 = 1;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// import without a reference
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package two;
+
+import "one.proto";
+
+message Two {
+  one.One field1 = 1;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+
+	// create new schema that imports one
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package two;
+
+import "one.proto";
+
+message Two {
+  one.One field1 = 1;
+}
+`,
+		References: []SubjectReference{
+			{
+				Name:    "one.proto",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	// create new version that changes the referenced type so isn't compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeProtobuf,
+		Schema: `
+syntax = "proto3";
+package two;
+
+message Other {
+  int64 field1 = 1;
+}
+
+message Two {
+  Other field1 = 1;
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+}
+
+func TestPostSubjectVersionNormalize(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	rawSchemas := []string{
+		`{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "long"}]}`,
+		`
+{
+  "name": "schema_one",
+  "type": "record",
+  "fields": [
+    {"type": "long", "name": "field1"}
+  ]
+}
+`,
+		`{"fields":[{"name":"field1","type":"long"}],"type":"record","name":"schema_one"}`,
+	}
+
+	// without normalizing whitespace and key ordering creates new schemas
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: rawSchemas[0],
+	}
+	assert.NoError(t, requestPostSubject.Bind(httptest.NewRequest(http.MethodPost, "/", nil)))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: rawSchemas[1],
+	}
+	assert.NoError(t, requestPostSubject.Bind(httptest.NewRequest(http.MethodPost, "/", nil)))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	// normalizing dedupes to the same schema
+	for _, rawSchema := range rawSchemas {
+		requestPostSubject = &RequestPostSubjectVersion{
+			Schema: rawSchema,
+		}
+		assert.NoError(t, requestPostSubject.Bind(httptest.NewRequest(http.MethodPost, "/?normalize=true", nil)))
+		resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), resp.ID)
+	}
+
+	respGetSubjectVersion, err := getSubjectVersion(db, "one", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), respGetSubjectVersion.Version)
+	assert.Equal(t, `{"name":"schema_one","type":"record","fields":[{"name":"field1","type":"long"}]}`, respGetSubjectVersion.Schema)
+
+	// lookup with normalize
+	requestLookup := &RequestPostSubject{
+		Schema: rawSchemas[2],
+	}
+	assert.NoError(t, requestLookup.Bind(httptest.NewRequest(http.MethodPost, "/?normalize=true", nil)))
+	respPostSubject, err := postSubject(db, registry, "one", requestLookup)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), respPostSubject.ID)
+	assert.Equal(t, int32(3), respPostSubject.Version)
+
+	// lookup without normalize doesn't find it
+	requestLookup = &RequestPostSubject{
+		Schema: rawSchemas[2],
+	}
+	assert.NoError(t, requestLookup.Bind(httptest.NewRequest(http.MethodPost, "/", nil)))
+	respPostSubject, err = postSubject(db, registry, "one", requestLookup)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, respPostSubject)
+	assert.Equal(t, 40403, apiError.ErrorCode)
+
+	// normalize from the subject config
+	normalize := true
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:        uuid.New(),
+		Subject:   "two",
+		Normalize: &normalize,
+	}).Error)
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: rawSchemas[2],
+	}
+	assert.NoError(t, requestPostSubject.Bind(httptest.NewRequest(http.MethodPost, "/", nil)))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ID)
+}
+
+func TestPostSubjectVersionNewVersionDifferentSchemaTypes(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "string"}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// json version of an avro subject isn't compatible
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema:     `{"type": "string"}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+
+	// even when the subject doesn't check compatibility
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "one",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	apiError = &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+
+	// the json schema is still registered under a different subject
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+}
+
+func TestPostSubjectVersionReferenceDifferentSchemaTypes(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `
+{
+  "type": "record",
+  "name": "schema_one",
+  "fields": [
+    {"name": "field1", "type": "long"}
+  ]
+}
+`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// json schema referencing an avro schema
+	requestPostSubject = &RequestPostSubjectVersion{
+		SchemaType: schemas.SchemaTypeJSON,
+		Schema: `
+{
+  "type": "object",
+  "properties": {
+    "field1": {"$ref": "schema_one"}
+  }
+}
+`,
+		References: []SubjectReference{
+			{
+				Name:    "schema_one",
+				Subject: "one",
+				Version: int32(1),
+			},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 40901, apiError.ErrorCode)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	assert.NoError(t, render.Render(w, req, apiError))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+}
+
+func TestPostSubjectVersionConcurrent(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// sqlite only allows a single writer so registrations wait for the one connection instead of failing as locked
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	registrations := 20
+	var wg sync.WaitGroup
+	for i := 0; i < registrations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			requestPostSubject := &RequestPostSubjectVersion{
+				Schema: fmt.Sprintf(`{"type": "record", "name": "test", "fields": [{"name": "field%d", "type": "string"}]}`, i),
+			}
+			assert.NoError(t, requestPostSubject.Bind(nil))
+			_, err := postSubjectVersion(db, registry, fmt.Sprintf("subject%d", i), requestPostSubject)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	globalIDs := make([]int32, 0)
+	assert.NoError(t, db.Model(&dbModels.Schema{}).Order("global_id").Pluck("global_id", &globalIDs).Error)
+	assert.Len(t, globalIDs, registrations)
+	for i, globalID := range globalIDs {
+		assert.Equal(t, int32(i+1), globalID)
+	}
+}
+
+func TestPostSubjectVersionConcurrentSameSubject(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "one",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+
+	// registrations conflict with each other and are retried instead of failing
+	registrations := 5
+	var wg sync.WaitGroup
+	for i := 0; i < registrations; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			requestPostSubject := &RequestPostSubjectVersion{
+				Schema: fmt.Sprintf(`{"type": "record", "name": "test", "fields": [{"name": "field%d", "type": "string"}]}`, i),
+			}
+			assert.NoError(t, requestPostSubject.Bind(nil))
+			_, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	versions := make([]int32, 0)
+	assert.NoError(t, db.Model(&dbModels.SubjectVersion{}).Order("version").Pluck("version", &versions).Error)
+	assert.Equal(t, []int32{1, 2, 3, 4, 5}, versions)
+}
+
+func TestPostSubjectVersionContexts(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	cfg := configuration.Default()
+	cfg.SchemaIDs.RangeEnd = 999
+	cfg.SchemaIDs.ContextRanges = []string{".team-b=1000-1999"}
+	registry := newTestRegistry(t, db, cfg)
+
+	schema := `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "long"}]}`
+
+	// the same schema gets its own id in every context
+	ids := make([]int32, 0)
+	for _, subjectName := range []string{"one", ":.team-a:one", ":.:one", ":.team-b:one"} {
+		requestPostSubject := &RequestPostSubjectVersion{Schema: schema}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		resp, err := postSubjectVersion(db, registry, dbModels.NormalizeSubjectName(subjectName), requestPostSubject)
+		assert.NoError(t, err)
+		ids = append(ids, resp.ID)
+	}
+	// the default context can be qualified and contexts with a range take ids from it
+	assert.Equal(t, []int32{1, 2, 1, 1000}, ids)
+
+	subject, err := getSubjectByName(db, ":.team-a:one", false)
+	assert.NoError(t, err)
+	assert.Equal(t, ".team-a", subject.Context)
+
+	// the schema is stored once per context with the same content hash
+	var contextSchemas []dbModels.Schema
+	assert.NoError(t, db.Order("global_id").Find(&contextSchemas).Error)
+	if assert.Len(t, contextSchemas, 3) {
+		assert.Equal(t, []string{"", ".team-a", ".team-b"}, []string{contextSchemas[0].Context, contextSchemas[1].Context, contextSchemas[2].Context})
+		assert.Equal(t, contextSchemas[0].Hash, contextSchemas[1].Hash)
+		assert.Equal(t, contextSchemas[0].Hash, contextSchemas[2].Hash)
+	}
+
+	// references without a context are to subjects in the same context
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+		References: []SubjectReference{
+			{Name: "schema_one", Subject: "one", Version: 1},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, ":.team-a:two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ID)
+
+	var referencedSubjects []string
+	assert.NoError(t, db.Model(&dbModels.SchemaReference{}).
+		Joins("JOIN schemas ON schemas.id = schema_references.schema_id").
+		Joins("JOIN subject_versions ON subject_versions.id = schema_references.subject_version_id").
+		Joins("JOIN subjects ON subjects.id = subject_versions.subject_id").
+		Where("schemas.global_id = ?", resp.ID).Pluck("subjects.name", &referencedSubjects).Error)
+	assert.Equal(t, []string{":.team-a:one"}, referencedSubjects)
+
+	// qualifying the reference with the subject's own context is the same schema
+	for _, subjectName := range []string{":.team-a:two", ":.team-a:three"} {
+		requestPostSubject = &RequestPostSubjectVersion{
+			Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+			References: []SubjectReference{
+				{Name: "schema_one", Subject: ":.team-a:one", Version: 1},
+			},
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		resp, err = postSubjectVersion(db, registry, subjectName, requestPostSubject)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), resp.ID)
+	}
+
+	versions, err := getSubjectVersions(db, ":.team-a:two", listOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseGetSubjectVersions{1}, *versions)
+
+	// and so is qualifying it with the default context in the default context
+	for _, reference := range []string{"one", ":.:one"} {
+		requestPostSubject = &RequestPostSubjectVersion{
+			Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+			References: []SubjectReference{
+				{Name: "schema_one", Subject: reference, Version: 1},
+			},
+		}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(4), resp.ID)
+
+		requestLookup := &RequestPostSubject{
+			Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+			References: []SubjectReference{
+				{Name: "schema_one", Subject: reference, Version: 1},
+			},
+		}
+		assert.NoError(t, requestLookup.Bind(nil))
+		lookupResp, err := postSubject(db, registry, "two", requestLookup)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(4), lookupResp.ID)
+	}
+
+	// references to subjects that only exist in another context aren't found
+	requestPostSubject = &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+		References: []SubjectReference{
+			{Name: "schema_one", Subject: "one", Version: 1},
+		},
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err = postSubjectVersion(db, registry, ":.team-c:two", requestPostSubject)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 40402, apiError.ErrorCode)
+}
+
+func TestPostSubjectVersionDataContract(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	schema := `{"type": "record", "name": "user", "fields": [{"name": "ssn", "type": "string"}]}`
+	metadata := &dbModels.Metadata{
+		Tags:       map[string][]string{"user.ssn": {"PII"}},
+		Properties: map[string]string{"owner": "payments"},
+		Sensitive:  []string{"ssn"},
+	}
+	ruleSet := &dbModels.RuleSet{
+		DomainRules: []dbModels.Rule{{Name: "checkSsn", Kind: dbModels.RuleKindCondition, Mode: dbModels.RuleModeWrite, Type: "CEL", Expr: "size(message.ssn) == 9"}},
+	}
+
+	// invalid rules aren't accepted
+	requestPostSubject := &RequestPostSubjectVersion{Schema: schema, RuleSet: &dbModels.RuleSet{
+		MigrationRules: []dbModels.Rule{{Name: "upgrade", Kind: dbModels.RuleKindTransform, Mode: dbModels.RuleModeWrite, Type: "JSONATA"}},
+	}}
+	assert.ErrorContains(t, requestPostSubject.Bind(nil), "invalid mode")
+
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// the same schema with a data contract is a new schema
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema, Metadata: metadata, RuleSet: ruleSet}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	getResp, err := getSubjectVersion(db, "one", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, getResp.Metadata)
+	assert.Equal(t, ruleSet, getResp.RuleSet)
+
+	getResp, err = getSubjectVersion(db, "one", "1")
+	assert.NoError(t, err)
+	assert.Nil(t, getResp.Metadata)
+	assert.Nil(t, getResp.RuleSet)
+
+	// registering it again without the data contract keeps the one of the latest version
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	// and so does looking it up
+	requestLookup := &RequestPostSubject{Schema: schema}
+	assert.NoError(t, requestLookup.Bind(nil))
+	lookupResp, err := postSubject(db, registry, "one", requestLookup)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), lookupResp.Version)
+	assert.Equal(t, metadata, lookupResp.Metadata)
+
+	// config defaults are merged under the data contract of the schema and overrides over it
+	err = db.Create(&dbModels.Config{
+		ID:               uuid.New(),
+		Subject:          "two",
+		DefaultMetadata:  `{"properties": {"owner": "governance", "team": "platform"}}`,
+		OverrideMetadata: `{"sensitive": ["email"]}`,
+	}).Error
+	assert.NoError(t, err)
+
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema, Metadata: metadata}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ID)
+
+	getResp, err = getSubjectVersion(db, "two", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, &dbModels.Metadata{
+		Tags:       map[string][]string{"user.ssn": {"PII"}},
+		Properties: map[string]string{"owner": "payments", "team": "platform"},
+		Sensitive:  []string{"ssn", "email"},
+	}, getResp.Metadata)
+	assert.Nil(t, getResp.RuleSet)
+}
+
+func TestPostSubjectVersionDataContractLaterVersion(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+	assert.NoError(t, db.Create(&dbModels.Config{ID: uuid.New(), Subject: "one", Compatibility: dbModels.SubjectCompatibilityNone}).Error)
+
+	schemaOne := `{"type": "record", "name": "user", "fields": [{"name": "ssn", "type": "string"}]}`
+	schemaTwo := `{"type": "record", "name": "user", "fields": [{"name": "email", "type": "string"}]}`
+
+	requestPostSubject := &RequestPostSubjectVersion{Schema: schemaOne}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// a later version adds metadata
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schemaTwo, Metadata: &dbModels.Metadata{Properties: map[string]string{"owner": "payments"}}}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	assertFirstVersion := func() {
+		// looking up the first schema without a data contract matches it on the schema alone
+		requestLookup := &RequestPostSubject{Schema: schemaOne}
+		assert.NoError(t, requestLookup.Bind(nil))
+		lookupResp, err := postSubject(db, registry, "one", requestLookup)
+		assert.NoError(t, err)
+		if assert.NotNil(t, lookupResp) {
+			assert.Equal(t, int32(1), lookupResp.ID)
+			assert.Equal(t, int32(1), lookupResp.Version)
+			assert.Nil(t, lookupResp.Metadata)
+		}
+
+		// and registering it again doesn't add a version
+		requestPostSubject := &RequestPostSubjectVersion{Schema: schemaOne}
+		assert.NoError(t, requestPostSubject.Bind(nil))
+		resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+		assert.NoError(t, err)
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, int32(1), resp.ID)
+		}
+
+		versions, err := getSubjectVersions(db, "one", listOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, ResponseGetSubjectVersions{1, 2}, *versions)
+	}
+	assertFirstVersion()
+
+	// config data contracts don't change which schema it is either
+	assert.NoError(t, db.Model(&dbModels.Config{}).Where("subject = ?", "one").
+		Update("override_metadata", `{"properties": {"team": "platform"}}`).Error)
+	assertFirstVersion()
+
+	// looking it up with a data contract it wasn't registered with doesn't match
+	for _, requestLookup := range []*RequestPostSubject{
+		{Schema: schemaOne, Metadata: &dbModels.Metadata{Properties: map[string]string{"owner": "payments"}}},
+		{Schema: schemaOne, RuleSet: &dbModels.RuleSet{}},
+		{Schema: schemaOne, Metadata: &dbModels.Metadata{Properties: map[string]string{"owner": "payments"}}, RuleSet: &dbModels.RuleSet{}},
+	} {
+		assert.NoError(t, requestLookup.Bind(nil))
+		_, err = postSubject(db, registry, "one", requestLookup)
+		apiError := &routers.APIError{}
+		assert.ErrorAs(t, err, &apiError)
+		assert.Equal(t, 40403, apiError.ErrorCode)
+	}
+}
+
+func TestPostSubjectVersionDataContractPartial(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	schema := `{"type": "record", "name": "user", "fields": [{"name": "ssn", "type": "string"}]}`
+	metadataA := &dbModels.Metadata{Properties: map[string]string{"owner": "a"}}
+	metadataB := &dbModels.Metadata{Properties: map[string]string{"owner": "b"}}
+	ruleSet := &dbModels.RuleSet{
+		DomainRules: []dbModels.Rule{{Name: "checkSsn", Kind: dbModels.RuleKindCondition, Mode: dbModels.RuleModeWrite, Type: "CEL", Expr: "size(message.ssn) == 9"}},
+	}
+
+	requestPostSubject := &RequestPostSubjectVersion{Schema: schema, Metadata: metadataA}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err := postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ID)
+
+	// the same schema with only different metadata is a new schema
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema, Metadata: metadataB}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.ID)
+
+	getResp, err := getSubjectVersion(db, "one", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, metadataB, getResp.Metadata)
+
+	getResp, err = getSubjectVersion(db, "one", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, metadataA, getResp.Metadata)
+
+	// and so is the same schema with only a rule set
+	requestPostSubject = &RequestPostSubjectVersion{Schema: schema, RuleSet: ruleSet}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	resp, err = postSubjectVersion(db, registry, "one", requestPostSubject)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ID)
+
+	getResp, err = getSubjectVersion(db, "one", "3")
+	assert.NoError(t, err)
+	assert.Equal(t, metadataB, getResp.Metadata)
+	assert.Equal(t, ruleSet, getResp.RuleSet)
+
+	// registering with the metadata or the rule set it was registered with finds the version again
+	for index, request := range []*RequestPostSubjectVersion{
+		{Schema: schema, Metadata: metadataA},
+		{Schema: schema, Metadata: metadataB},
+		{Schema: schema, RuleSet: ruleSet},
+	} {
+		assert.NoError(t, request.Bind(nil))
+		resp, err = postSubjectVersion(db, registry, "one", request)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(index+1), resp.ID)
+	}
+
+	versions, err := getSubjectVersions(db, "one", listOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseGetSubjectVersions{1, 2, 3}, *versions)
+
+	// looking it up follows the same rules
+	requestLookup := &RequestPostSubject{Schema: schema, Metadata: metadataA}
+	assert.NoError(t, requestLookup.Bind(nil))
+	lookupResp, err := postSubject(db, registry, "one", requestLookup)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), lookupResp.Version)
+	assert.Equal(t, metadataA, lookupResp.Metadata)
+
+	requestLookup = &RequestPostSubject{Schema: schema, RuleSet: ruleSet}
+	assert.NoError(t, requestLookup.Bind(nil))
+	lookupResp, err = postSubject(db, registry, "one", requestLookup)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), lookupResp.Version)
+	assert.Equal(t, ruleSet, lookupResp.RuleSet)
+
+	requestLookup = &RequestPostSubject{Schema: schema, Metadata: &dbModels.Metadata{Properties: map[string]string{"owner": "c"}}}
+	assert.NoError(t, requestLookup.Bind(nil))
+	_, err = postSubject(db, registry, "one", requestLookup)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 40403, apiError.ErrorCode)
 }
@@ -0,0 +1,104 @@
+package subjects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rmb938/franz-schema-registry/pkg/database/transaction"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+// resolveBatchReferences sets the version of references without one to the version registered earlier in the batch
+func resolveBatchReferences(entry *RequestPostSubjectsBatchEntry, registeredVersions map[string]int32) error {
+	for index, reference := range entry.References {
+		if reference.Version != 0 {
+			continue
+		}
+
+		version, ok := registeredVersions[referenceSubjectName(entry.Subject, reference.Subject)]
+		if !ok {
+			return routers.NewAPIError(http.StatusUnprocessableEntity, 42201, fmt.Errorf("reference %s must set a version as subject %s isn't registered earlier in the batch", reference.Name, reference.Subject))
+		}
+
+		entry.References[index].Version = version
+	}
+
+	return nil
+}
+
+// newBatchEntryError adds which entry failed to the error, api errors keep their status and code
+func newBatchEntryError(index int, entry *RequestPostSubjectsBatchEntry, err error) error {
+	apiError := &routers.APIError{}
+	if errors.As(err, &apiError) {
+		return routers.NewAPIError(apiError.HTTPStatusCode(), apiError.ErrorCode, fmt.Errorf("entry %d subject %s: %w", index, entry.Subject, apiError.Unwrap()))
+	}
+
+	return fmt.Errorf("entry %d subject %s: %w", index, entry.Subject, err)
+}
+
+func postSubjectsBatch(db *gorm.DB, registry *Registry, data *RequestPostSubjectsBatch) (*ResponsePostSubjectsBatch, error) {
+	// references are resolved on every attempt so keep the versions the client sent
+	requestedVersions := make([][]int32, len(data.Entries))
+	for index, entry := range data.Entries {
+		for _, reference := range entry.References {
+			requestedVersions[index] = append(requestedVersions[index], reference.Version)
+		}
+	}
+
+	regs := make([]*registration, 0, len(data.Entries))
+	releaseIDs := func() {
+		for _, reg := range regs {
+			reg.releaseID()
+		}
+		regs = regs[:0]
+	}
+
+	// every entry is registered in the one transaction so either all of them are or none are
+	err := transaction.Run(db, func(tx *gorm.DB) error {
+		releaseIDs()
+
+		registeredVersions := make(map[string]int32)
+		for index, entry := range data.Entries {
+			for referenceIndex, version := range requestedVersions[index] {
+				entry.References[referenceIndex].Version = version
+			}
+
+			if err := resolveBatchReferences(entry, registeredVersions); err != nil {
+				return newBatchEntryError(index, entry, err)
+			}
+
+			reg := &registration{}
+			regs = append(regs, reg)
+			if err := registerSubjectVersion(tx, registry, entry.Subject, &entry.RequestPostSubjectVersion, reg); err != nil {
+				return newBatchEntryError(index, entry, err)
+			}
+
+			registeredVersions[entry.Subject] = reg.version
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		releaseIDs()
+		return nil, err
+	}
+
+	resp := &ResponsePostSubjectsBatch{
+		Entries: make([]ResponsePostSubjectsBatchEntry, 0, len(regs)),
+	}
+	for index, reg := range regs {
+		metrics.ObserveSchemaRegistration(string(reg.schemaType), reg.outcome)
+
+		resp.Entries = append(resp.Entries, ResponsePostSubjectsBatchEntry{
+			Subject: data.Entries[index].Subject,
+			ID:      reg.id,
+			Version: reg.version,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,257 @@
+package subjects
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
+	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestPostSubjectsBatchBind(t *testing.T) {
+	data := &RequestPostSubjectsBatch{}
+	assert.ErrorContains(t, data.Bind(nil), "entries may not be empty")
+
+	data = &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{Subject: "one", RequestPostSubjectVersion: RequestPostSubjectVersion{Schema: `{"type": "string"}`}},
+		{RequestPostSubjectVersion: RequestPostSubjectVersion{Schema: `{"type": "string"}`}},
+	}}
+	assert.ErrorContains(t, data.Bind(nil), "entry 1: subject may not be empty")
+
+	data = &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{Subject: "one"},
+	}}
+	assert.ErrorContains(t, data.Bind(nil), "entry 0 subject one: schema may not be empty")
+
+	data = &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{Subject: "one", RequestPostSubjectVersion: RequestPostSubjectVersion{Schema: `{"type": "string"}`}},
+	}}
+	assert.NoError(t, data.Bind(nil))
+}
+
+func TestNewBatchEntryError(t *testing.T) {
+	entry := &RequestPostSubjectsBatchEntry{Subject: "three"}
+
+	// wrapped api errors keep their status and code
+	err := newBatchEntryError(2, entry, fmt.Errorf("error finding reference: %w", routers.NewAPIError(http.StatusNotFound, 40402, fmt.Errorf("version 5 not found"))))
+	apiError := &routers.APIError{}
+	if assert.ErrorAs(t, err, &apiError) {
+		assert.Equal(t, http.StatusNotFound, apiError.HTTPStatusCode())
+		assert.Equal(t, 40402, apiError.ErrorCode)
+		assert.Equal(t, "entry 2 subject three: version 5 not found", apiError.Message)
+	}
+
+	err = newBatchEntryError(2, entry, fmt.Errorf("connection reset"))
+	assert.False(t, errors.As(err, &apiError))
+	assert.EqualError(t, err, "entry 2 subject three: connection reset")
+}
+
+func TestPostSubjectsBatch(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// later entries reference earlier ones without a version
+	data := &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{
+			Subject: "one",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "string"}]}`,
+			},
+		},
+		{
+			Subject: "two",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema:     `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+				References: []SubjectReference{{Name: "schema_one", Subject: "one"}},
+			},
+		},
+	}}
+	assert.NoError(t, data.Bind(nil))
+	resp, err := postSubjectsBatch(db, registry, data)
+	assert.NoError(t, err)
+	assert.Equal(t, &ResponsePostSubjectsBatch{Entries: []ResponsePostSubjectsBatchEntry{
+		{Subject: "one", ID: 1, Version: 1},
+		{Subject: "two", ID: 2, Version: 1},
+	}}, resp)
+
+	schemaReference := &dbModels.SchemaReference{}
+	assert.NoError(t, db.Joins("SubjectVersion").First(schemaReference).Error)
+	assert.Equal(t, "schema_one", schemaReference.Name)
+	assert.Equal(t, int32(1), schemaReference.SubjectVersion.Version)
+
+	// registering the same batch again returns the existing versions
+	data = &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{
+			Subject: "one",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "string"}]}`,
+			},
+		},
+		{
+			Subject: "two",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema:     `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+				References: []SubjectReference{{Name: "schema_one", Subject: "one"}},
+			},
+		},
+	}}
+	assert.NoError(t, data.Bind(nil))
+	resp, err = postSubjectsBatch(db, registry, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []ResponsePostSubjectsBatchEntry{
+		{Subject: "one", ID: 1, Version: 1},
+		{Subject: "two", ID: 2, Version: 1},
+	}, resp.Entries)
+}
+
+func TestPostSubjectsBatchAtomic(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	requestPostSubject := &RequestPostSubjectVersion{
+		Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "string"}]}`,
+	}
+	assert.NoError(t, requestPostSubject.Bind(nil))
+	_, err := postSubjectVersion(db, registry, "two", requestPostSubject)
+	assert.NoError(t, err)
+
+	// the second entry isn't compatible so the first isn't registered either
+	data := &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{
+			Subject: "one",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "string"}]}`,
+			},
+		},
+		{
+			Subject: "two",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema: `{"type": "record", "name": "schema_two", "fields": [{"name": "field2", "type": "string"}]}`,
+			},
+		},
+	}}
+	assert.NoError(t, data.Bind(nil))
+	resp, err := postSubjectsBatch(db, registry, data)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Nil(t, resp)
+	assert.Equal(t, 409, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "entry 1 subject two: schema is incompatible")
+
+	var count int64
+	assert.NoError(t, db.Model(&dbModels.Subject{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, db.Model(&dbModels.Schema{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// the id allocated to the rolled back schema is handed out again
+	assert.NoError(t, db.Create(&dbModels.Config{
+		ID:            uuid.New(),
+		Subject:       "two",
+		Compatibility: dbModels.SubjectCompatibilityNone,
+	}).Error)
+	resp, err = postSubjectsBatch(db, registry, data)
+	assert.NoError(t, err)
+	assert.Equal(t, []ResponsePostSubjectsBatchEntry{
+		{Subject: "one", ID: 2, Version: 1},
+		{Subject: "two", ID: 3, Version: 2},
+	}, resp.Entries)
+}
+
+func TestPostSubjectsBatchUnresolvedReference(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	registry := newTestRegistry(t, db, configuration.Default())
+
+	// references without a version have to be to a subject earlier in the batch
+	data := &RequestPostSubjectsBatch{Entries: []*RequestPostSubjectsBatchEntry{
+		{
+			Subject: "two",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema:     `{"type": "record", "name": "schema_two", "fields": [{"name": "field1", "type": "schema_one"}]}`,
+				References: []SubjectReference{{Name: "schema_one", Subject: "one"}},
+			},
+		},
+		{
+			Subject: "one",
+			RequestPostSubjectVersion: RequestPostSubjectVersion{
+				Schema: `{"type": "record", "name": "schema_one", "fields": [{"name": "field1", "type": "string"}]}`,
+			},
+		},
+	}}
+	assert.NoError(t, data.Bind(nil))
+	_, err := postSubjectsBatch(db, registry, data)
+	apiError := &routers.APIError{}
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, 42201, apiError.ErrorCode)
+	assert.Contains(t, apiError.Message, "entry 0 subject two: reference schema_one must set a version")
+}
+
+func TestBatchRouterErrors(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	router := NewBatchRouter(db, acl.NewAuthorizer(db, configuration.ACLConfiguration{}), newTestRegistry(t, db, configuration.Default()))
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		errorCode  int
+	}{
+		{"invalid entry", `{"entries": [{"subject": "one"}]}`, http.StatusUnprocessableEntity, http.StatusUnprocessableEntity},
+		{"unresolved reference", `{"entries": [{"subject": "two", "schema": "{\"type\": \"record\", \"name\": \"two\", \"fields\": [{\"name\": \"f\", \"type\": \"one\"}]}", "references": [{"name": "one", "subject": "one"}]}]}`, http.StatusUnprocessableEntity, 42201},
+		{"reference not found", `{"entries": [{"subject": "two", "schema": "{\"type\": \"record\", \"name\": \"two\", \"fields\": [{\"name\": \"f\", \"type\": \"one\"}]}", "references": [{"name": "one", "subject": "one", "version": 1}]}]}`, http.StatusNotFound, 40402},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.statusCode, w.Code)
+			apiError := &routers.APIError{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(apiError))
+			assert.Equal(t, test.errorCode, apiError.ErrorCode)
+			assert.Contains(t, apiError.Message, "entry 0 subject")
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package subjects
+
+import (
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/cache"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/events"
+	"github.com/rmb938/franz-schema-registry/pkg/ids"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
+	"gorm.io/gorm"
+)
+
+// Registry is what the subjects, compatibility and batch routers share besides the database
+type Registry struct {
+	// limits bounds how much work resolving schema references can cause
+	limits configuration.LimitsConfiguration
+	// parsedSchemaCache holds existing schemas parsed along with their references, keyed by the schema id
+	parsedSchemaCache *cache.Cache[uuid.UUID, schemas.ParsedSchema]
+	// schemaIDs hands out the global ids of new schemas
+	schemaIDs *ids.Allocator
+	// contextSchemaIDs hands out the global ids of new schemas in contexts with their own range
+	contextSchemaIDs map[string]*ids.Allocator
+	// publisher records the events of registered and deleted subject versions
+	publisher *events.Publisher
+}
+
+// NewRegistry creates the caches, schema id allocators and event publisher used by the routers from the configuration
+func NewRegistry(db *gorm.DB, cfg *configuration.Configuration) (*Registry, error) {
+	parsedSchemaCache, err := cache.New[uuid.UUID, schemas.ParsedSchema]("parsed_schemas", cfg.Cache.ParsedSchemas)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaIDs, contextSchemaIDs, err := newSchemaIDAllocators(db, cfg.SchemaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registry{
+		limits:            cfg.Limits,
+		parsedSchemaCache: parsedSchemaCache,
+		schemaIDs:         schemaIDs,
+		contextSchemaIDs:  contextSchemaIDs,
+		publisher:         events.NewPublisher(cfg.Events),
+	}, nil
+}
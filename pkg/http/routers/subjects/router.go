@@ -1,35 +1,36 @@
 package subjects
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/graph"
+	"github.com/rmb938/franz-schema-registry/pkg/http/acl"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
 	"gorm.io/gorm"
-	"gorm.io/hints"
 )
 
-func forceIndexHint(index string) hints.Hints {
-	forceIndexHint := hints.CommentBefore("where", fmt.Sprintf("FORCE_INDEX = %s", index))
-	forceIndexHint.Prefix = "/*@ "
-	return forceIndexHint
-}
-
-func NewRouter(db *gorm.DB) *chi.Mux {
+func NewRouter(db *gorm.DB, authorizer *acl.Authorizer, registry *Registry) *chi.Mux {
 	chiRouter := chi.NewRouter()
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--subjects
 	chiRouter.Get("/", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		// Whether to included soft deleted subjects
-		deletedRaw := request.URL.Query().Get("deleted")
-		deleted, _ := strconv.ParseBool(deletedRaw)
+		// subjectPrefix, deleted, deletedOnly, offset and limit
+		options := parseListOptions(request)
 
 		var v render.Renderer
-		v, err := getSubjects(db, deleted)
+		// only list the subjects the principal can read
+		var err error
+		options.readable, err = authorizer.ScopeSubjects(request.Context(), dbModels.ACLOperationRead)
+		if err == nil {
+			v, err = getSubjects(db, options)
+		}
 		if err != nil {
 			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing subjects: %w", err))
 			if renderer, ok := err.(render.Renderer); ok {
@@ -41,16 +42,15 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--subjects-(string-%20subject)-versions
-	chiRouter.Get("/{subject}/versions", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 
-		// Whether to included soft deleted versions
-		deletedRaw := request.URL.Query().Get("deleted")
-		deleted, _ := strconv.ParseBool(deletedRaw)
+		// deleted, deletedOnly, offset and limit
+		options := parseListOptions(request)
 
 		var v render.Renderer
-		v, err := getSubjectVersions(db, subjectName, deleted)
+		v, err := getSubjectVersions(db, subjectName, options)
 		if err != nil {
 			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error listing subject versions: %w", err))
 			if renderer, ok := err.(render.Renderer); ok {
@@ -62,15 +62,15 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#delete--subjects-(string-%20subject)
-	chiRouter.Delete("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationDelete)).Delete("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 
 		permanentRaw := request.URL.Query().Get("permanent")
 		permanent, _ := strconv.ParseBool(permanentRaw)
 
 		var v render.Renderer
-		v, err := deleteSubject(db, subjectName, permanent)
+		v, err := deleteSubject(db, registry, subjectName, permanent)
 		if err != nil {
 			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting subject: %w", err))
 			if renderer, ok := err.(render.Renderer); ok {
@@ -82,8 +82,8 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--subjects-(string-%20subject)-versions-(versionId-%20version)
-	chiRouter.Get("/{subject}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
-		subjectName := chi.URLParam(request, "subject")
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
+		subjectName := routers.SubjectParam(request)
 		version := chi.URLParam(request, "version")
 
 		var v render.Renderer
@@ -99,8 +99,8 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--subjects-(string-%20subject)-versions-(versionId-%20version)-schema
-	chiRouter.Get("/{subject}/versions/{version}/schema", func(writer http.ResponseWriter, request *http.Request) {
-		subjectName := chi.URLParam(request, "subject")
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions/{version}/schema", func(writer http.ResponseWriter, request *http.Request) {
+		subjectName := routers.SubjectParam(request)
 		version := chi.URLParam(request, "version")
 
 		var v render.Renderer
@@ -116,9 +116,9 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#post--subjects-(string-%20subject)-versions
-	chiRouter.Post("/{subject}/versions", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationWrite)).Post("/{subject}/versions", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 		data := &RequestPostSubjectVersion{}
 
 		var v render.Renderer
@@ -129,7 +129,7 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 
 		if v == nil {
 			var err error
-			v, err = postSubjectVersion(db, db, subjectName, data)
+			v, err = postSubjectVersion(db, registry, subjectName, data)
 			if err != nil {
 				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving schema: %w", err))
 				if renderer, ok := err.(render.Renderer); ok {
@@ -142,9 +142,9 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#post--subjects-(string-%20subject)
-	chiRouter.Post("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Post("/{subject}", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 		data := &RequestPostSubject{}
 
 		var v render.Renderer
@@ -155,7 +155,7 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 
 		if v == nil {
 			var err error
-			v, err = postSubject(db, subjectName, data)
+			v, err = postSubject(db, registry, subjectName, data)
 			if err != nil {
 				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking schema: %w", err))
 				if renderer, ok := err.(render.Renderer); ok {
@@ -168,16 +168,16 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#delete--subjects-(string-%20subject)-versions-(versionId-%20version)
-	chiRouter.Delete("/{subject}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationDelete)).Delete("/{subject}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 		version := chi.URLParam(request, "version")
 
 		permanentRaw := request.URL.Query().Get("permanent")
 		permanent, _ := strconv.ParseBool(permanentRaw)
 
 		var v render.Renderer
-		v, err := deleteSubjectVersion(db, subjectName, version, permanent)
+		v, err := deleteSubjectVersion(db, registry, subjectName, version, permanent)
 		if err != nil {
 			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error deleting subject version: %w", err))
 			if renderer, ok := err.(render.Renderer); ok {
@@ -189,9 +189,9 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 	})
 
 	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#get--subjects-(string-%20subject)-versions-versionId-%20version-referencedby
-	chiRouter.Get("/{subject}/versions/{version}/referencedby", func(writer http.ResponseWriter, request *http.Request) {
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions/{version}/referencedby", func(writer http.ResponseWriter, request *http.Request) {
 		render.Status(request, http.StatusOK)
-		subjectName := chi.URLParam(request, "subject")
+		subjectName := routers.SubjectParam(request)
 		version := chi.URLParam(request, "version")
 
 		var v render.Renderer
@@ -206,5 +206,167 @@ func NewRouter(db *gorm.DB) *chi.Mux {
 		render.Render(writer, request, v)
 	})
 
+	// the transitive reference graph around a subject version, as json or graphviz dot
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions/{version}/graph", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+		version := chi.URLParam(request, "version")
+
+		// direction, depth, deleted and format
+		options := graph.ParseOptions(request, registry.limits)
+
+		var v render.Renderer
+		versionGraph, err := getSubjectVersionGraph(db, registry, subjectName, version, options)
+		if err == nil {
+			// only include the versions of subjects the principal can read
+			var readable []string
+			readable, err = authorizer.FilterSubjects(request.Context(), dbModels.ACLOperationRead, versionGraph.Subjects())
+			v = versionGraph.Filter(readable)
+		}
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error getting subject version graph: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		graph.Respond(writer, request, options, v)
+	})
+
+	// the semantic changes from one subject version to another along with their compatibility
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Get("/{subject}/versions/{version}/diff/{otherVersion}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+		version := chi.URLParam(request, "version")
+		otherVersion := chi.URLParam(request, "otherVersion")
+
+		var v render.Renderer
+		v, err := getSubjectVersionsDiff(db, registry, subjectName, version, otherVersion)
+		if err != nil {
+			v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error diffing subject versions: %w", err))
+			if renderer, ok := err.(render.Renderer); ok {
+				v = renderer
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
+
+func NewCompatibilityRouter(db *gorm.DB, authorizer *acl.Authorizer, registry *Registry) *chi.Mux {
+	chiRouter := chi.NewRouter()
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#post--compatibility-subjects-(string-%20subject)-versions-(versionId-%20version)
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Post("/subjects/{subject}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+		version := chi.URLParam(request, "version")
+
+		// Whether to include the reasons the schema isn't compatible
+		verboseRaw := request.URL.Query().Get("verbose")
+		verbose, _ := strconv.ParseBool(verboseRaw)
+
+		data := &RequestPostCompatibility{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+		}
+
+		if v == nil {
+			var err error
+			v, err = postCompatibilitySubjectVersion(db, registry, subjectName, version, data, verbose)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	// https://docs.confluent.io/platform/current/schema-registry/develop/api.html#post--compatibility-subjects-(string-%20subject)-versions
+	chiRouter.With(authorizer.Subject(dbModels.ACLOperationRead)).Post("/subjects/{subject}/versions", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+		subjectName := routers.SubjectParam(request)
+
+		// Whether to include the reasons the schema isn't compatible
+		verboseRaw := request.URL.Query().Get("verbose")
+		verbose, _ := strconv.ParseBool(verboseRaw)
+
+		data := &RequestPostCompatibility{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+		}
+
+		if v == nil {
+			var err error
+			v, err = postCompatibilitySubjectVersions(db, registry, subjectName, data, verbose)
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error checking compatibility: %w", err))
+				if renderer, ok := err.(render.Renderer); ok {
+					v = renderer
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
+	return chiRouter
+}
+
+// NewBatchRouter registers schemas to multiple subjects at once, it isn't part of the confluent api
+func NewBatchRouter(db *gorm.DB, authorizer *acl.Authorizer, registry *Registry) *chi.Mux {
+	chiRouter := chi.NewRouter()
+
+	chiRouter.Post("/", func(writer http.ResponseWriter, request *http.Request) {
+		render.Status(request, http.StatusOK)
+
+		data := &RequestPostSubjectsBatch{}
+
+		var v render.Renderer
+
+		if err := render.Bind(request, data); err != nil {
+			v = routers.NewAPIError(http.StatusUnprocessableEntity, http.StatusUnprocessableEntity, fmt.Errorf("error parsing body: %w", err))
+			// entries that aren't valid keep their code
+			apiError := &routers.APIError{}
+			if errors.As(err, &apiError) {
+				v = apiError
+			}
+		}
+
+		if v == nil {
+			var err error
+			// the principal has to be allowed to write to every subject in the batch
+			for _, entry := range data.Entries {
+				if err = authorizer.Authorize(request.Context(), dbModels.ACLOperationWrite, entry.Subject); err != nil {
+					break
+				}
+			}
+
+			if err == nil {
+				v, err = postSubjectsBatch(db, registry, data)
+			}
+			if err != nil {
+				v = routers.NewAPIError(http.StatusInternalServerError, 5001, fmt.Errorf("error saving schemas: %w", err))
+				apiError := &routers.APIError{}
+				if errors.As(err, &apiError) {
+					v = apiError
+				}
+			}
+		}
+
+		render.Render(writer, request, v)
+	})
+
 	return chiRouter
 }
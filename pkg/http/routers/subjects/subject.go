@@ -4,16 +4,18 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
 	"github.com/rmb938/franz-schema-registry/pkg/http/routers"
+	"github.com/rmb938/franz-schema-registry/pkg/schemas"
 	"gorm.io/gorm"
 )
 
 func getSubjectByName(tx *gorm.DB, subjectName string, includeDeleted bool) (*dbModels.Subject, error) {
 	subject := &dbModels.Subject{}
-	tx = tx.Clauses(forceIndexHint("idx_subjects_name")).Where("name = ?", subjectName)
+	tx = tx.Clauses(dbModels.ForceIndexHint("idx_subjects_name")).Where("name = ?", subjectName)
 
 	if includeDeleted {
 		tx = tx.Unscoped()
@@ -30,13 +32,13 @@ func getSubjectByName(tx *gorm.DB, subjectName string, includeDeleted bool) (*db
 func getSubjectVersionBySubjectID(tx *gorm.DB, subjectID uuid.UUID, version string, includeDeleted bool) (*dbModels.SubjectVersion, error) {
 	getVersionTx := tx
 	if version == "-1" || version == "latest" {
-		getVersionTx = getVersionTx.Clauses(forceIndexHint("idx_subject_versions_subject_id")).Where("subject_id = ?", subjectID).Order("version desc").Limit(1)
+		getVersionTx = getVersionTx.Clauses(dbModels.ForceIndexHint("idx_subject_versions_subject_id")).Where("subject_id = ?", subjectID).Order("version desc").Limit(1)
 	} else {
 		versionInt, err := strconv.ParseInt(version, 10, 32)
 		if err != nil {
 			return nil, routers.NewAPIError(http.StatusUnprocessableEntity, 42202, fmt.Errorf("invalid version"))
 		}
-		getVersionTx = getVersionTx.Clauses(forceIndexHint("idx_subject_id_version")).Where("subject_id = ? AND VERSION = ?", subjectID, versionInt)
+		getVersionTx = getVersionTx.Clauses(dbModels.ForceIndexHint("idx_subject_id_version")).Where("subject_id = ? AND VERSION = ?", subjectID, versionInt)
 	}
 
 	if includeDeleted {
@@ -51,3 +53,163 @@ func getSubjectVersionBySubjectID(tx *gorm.DB, subjectID uuid.UUID, version stri
 
 	return versionModel, nil
 }
+
+// getWritableSubjectMode returns the mode of the subject, erroring when the mode does not allow changes
+func getWritableSubjectMode(tx *gorm.DB, subjectName string) (dbModels.SubjectMode, error) {
+	mode, err := dbModels.GetSubjectMode(tx, subjectName)
+	if err != nil {
+		return "", fmt.Errorf("error finding mode for subject %s: %w", subjectName, err)
+	}
+
+	if mode == dbModels.SubjectModeReadOnly {
+		return "", routers.NewAPIError(http.StatusUnprocessableEntity, 42205, fmt.Errorf("subject %s is in read-only mode", subjectName))
+	}
+
+	return mode, nil
+}
+
+// getSchemaType returns the schema type of a request, defaulting to avro when it isn't set
+func getSchemaType(requestSchemaType schemas.SchemaType) (schemas.SchemaType, dbModels.SchemaType, error) {
+	if len(requestSchemaType) == 0 {
+		return schemas.SchemaTypeAvro, dbModels.SchemaTypeAvro, nil
+	}
+
+	switch requestSchemaType {
+	case schemas.SchemaTypeAvro:
+		return requestSchemaType, dbModels.SchemaTypeAvro, nil
+	case schemas.SchemaTypeJSON:
+		return requestSchemaType, dbModels.SchemaTypeJSON, nil
+	case schemas.SchemaTypeProtobuf:
+		return requestSchemaType, dbModels.SchemaTypeProtobuf, nil
+	default:
+		return "", "", routers.NewAPIError(http.StatusBadRequest, http.StatusBadRequest, fmt.Errorf("unknown schema type: %s", requestSchemaType))
+	}
+}
+
+// normalizeSchema returns the canonical form of the schema along with its hash
+func normalizeSchema(subjectName string, schemaType schemas.SchemaType, parsedSchema schemas.ParsedSchema, references []SubjectReference) (string, string, error) {
+	schema, err := parsedSchema.CanonicalString()
+	if err != nil {
+		return "", "", fmt.Errorf("error normalizing schema: %w", err)
+	}
+
+	hash, err := calculateSchemaHash(subjectName, schemaType, schema, references)
+	if err != nil {
+		return "", "", fmt.Errorf("error calculating hash of normalized schema: %w", err)
+	}
+
+	return schema, hash, nil
+}
+
+// referenceSubjectName returns the subject a reference of the subject is to, references that aren't qualified
+// with a context are to a subject in the same context as the referencing subject
+func referenceSubjectName(subjectName string, referenceSubject string) string {
+	if strings.HasPrefix(referenceSubject, ":.") {
+		return dbModels.NormalizeSubjectName(referenceSubject)
+	}
+
+	context, _ := dbModels.ParseSubjectName(subjectName)
+	return dbModels.QualifySubjectName(context, referenceSubject)
+}
+
+// getReferencingSchemaIDs returns the global ids of the schemas used by a subject version that reference any of the
+// given subject versions keyed by the referenced subject version, subject versions of the excluded subject are ignored
+func getReferencingSchemaIDs(tx *gorm.DB, subjectVersionIDs []uuid.UUID, excludeSubjectID *uuid.UUID, includeDeleted bool) (map[uuid.UUID][]int32, error) {
+	schemaIDs := make(map[uuid.UUID][]int32)
+	if len(subjectVersionIDs) == 0 {
+		return schemaIDs, nil
+	}
+
+	referencesTx := tx.Model(&dbModels.SchemaReference{}).
+		Joins("JOIN schemas ON schemas.id = schema_references.schema_id").
+		Joins("JOIN subject_versions ON subject_versions.schema_id = schema_references.schema_id").
+		Where("schema_references.subject_version_id IN ?", subjectVersionIDs)
+
+	if !includeDeleted {
+		referencesTx = referencesTx.Where("subject_versions.deleted_at IS NULL")
+	}
+
+	if excludeSubjectID != nil {
+		referencesTx = referencesTx.Where("subject_versions.subject_id <> ?", *excludeSubjectID)
+	}
+
+	var references []struct {
+		SubjectVersionID uuid.UUID
+		GlobalID         int32
+	}
+	err := referencesTx.Distinct("schema_references.subject_version_id", "schemas.global_id").
+		Order("schemas.global_id").Scan(&references).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reference := range references {
+		schemaIDs[reference.SubjectVersionID] = append(schemaIDs[reference.SubjectVersionID], reference.GlobalID)
+	}
+
+	return schemaIDs, nil
+}
+
+// newReferenceExistsError returns the error for when a subject version can't be deleted because other schemas reference it
+func newReferenceExistsError(subjectName string, version int32, schemaIDs []int32) error {
+	ids := make([]string, len(schemaIDs))
+	for index, schemaID := range schemaIDs {
+		ids[index] = strconv.Itoa(int(schemaID))
+	}
+
+	return routers.NewAPIError(http.StatusUnprocessableEntity, 42206, fmt.Errorf("one or more references exist to version %d of subject %s, referenced by schema ids: %s", version, subjectName, strings.Join(ids, ", ")))
+}
+
+// listOptions are the filters and page of a subject or subject version listing
+type listOptions struct {
+	// subjectPrefix only lists subjects starting with the prefix
+	subjectPrefix  string
+	includeDeleted bool
+	// deletedOnly only lists soft deleted items
+	deletedOnly bool
+	offset      int
+	// limit is the maximum number of items returned, zero or less is unlimited
+	limit int
+	// readable scopes the subjects to the ones the principal can read before paging, nil is every subject
+	readable func(tx *gorm.DB) *gorm.DB
+}
+
+// parseListOptions reads the list options from the query parameters, invalid values are ignored
+func parseListOptions(request *http.Request) listOptions {
+	query := request.URL.Query()
+
+	options := listOptions{
+		subjectPrefix: query.Get("subjectPrefix"),
+	}
+	options.includeDeleted, _ = strconv.ParseBool(query.Get("deleted"))
+	options.deletedOnly, _ = strconv.ParseBool(query.Get("deletedOnly"))
+	options.offset, _ = strconv.Atoi(query.Get("offset"))
+	options.limit, _ = strconv.Atoi(query.Get("limit"))
+
+	if options.offset < 0 {
+		options.offset = 0
+	}
+
+	return options
+}
+
+// scope applies the deleted filters and the page to the query
+func (o listOptions) scope(tx *gorm.DB, deletedAtColumn string) *gorm.DB {
+	if o.includeDeleted || o.deletedOnly {
+		tx = tx.Unscoped()
+	}
+
+	if o.deletedOnly {
+		tx = tx.Where(fmt.Sprintf("%s IS NOT NULL", deletedAtColumn))
+	}
+
+	if o.offset > 0 {
+		tx = tx.Offset(o.offset)
+	}
+
+	if o.limit > 0 {
+		tx = tx.Limit(o.limit)
+	}
+
+	return tx
+}
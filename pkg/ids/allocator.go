@@ -0,0 +1,132 @@
+package ids
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"gorm.io/gorm"
+)
+
+// Allocator hands out schema global ids from blocks it reserves from a sequence, so registering only updates
+// the sequence once per block instead of for every schema.
+// Ids are never handed out twice, a new sequence starts after the schemas already in its range and imported ids
+// push it past them. The unique index on the global id catches ids imported into another replica's reserved block
+type Allocator struct {
+	// db reserves blocks in their own transaction, when it's nil ids are reserved one at a time
+	// in the transaction they're used in
+	db        *gorm.DB
+	sequence  dbModels.SequenceName
+	blockSize int64
+	start     int64
+	end       int64
+
+	mu sync.Mutex
+	// next and last are what's left of the reserved block, it's empty when next is after last
+	next     int64
+	last     int64
+	released []int64
+}
+
+// NewAllocator creates an allocator of ids in the configured range. Each range has its own sequence row so
+// regions with different ranges don't contend with each other, the full range uses the sequence itself
+func NewAllocator(db *gorm.DB, name dbModels.SequenceName, config configuration.SchemaIDsConfiguration) *Allocator {
+	sequence := name
+	if config.RangeStart != 1 || config.RangeEnd != math.MaxInt32 {
+		sequence = dbModels.SequenceName(fmt.Sprintf("%s_%d_%d", name, config.RangeStart, config.RangeEnd))
+	}
+
+	return &Allocator{
+		db:        db,
+		sequence:  sequence,
+		blockSize: int64(config.BlockSize),
+		start:     int64(config.RangeStart),
+		end:       int64(config.RangeEnd),
+		next:      1,
+		last:      0,
+	}
+}
+
+// take returns the next id from a released id, the reserved block or a newly reserved block
+func (a *Allocator) take(tx *gorm.DB) (int64, error) {
+	if a.db == nil {
+		first, _, err := dbModels.ReserveSequenceIDs(tx, a.sequence, 1, a.start, a.end)
+		return first, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.released) > 0 {
+		id := a.released[len(a.released)-1]
+		a.released = a.released[:len(a.released)-1]
+		return id, nil
+	}
+
+	if a.next > a.last {
+		first, last, err := dbModels.ReserveSequenceIDs(a.db, a.sequence, a.blockSize, a.start, a.end)
+		if err != nil {
+			return 0, err
+		}
+		a.next = first
+		a.last = last
+	}
+
+	id := a.next
+	a.next++
+
+	return id, nil
+}
+
+// Next returns an id that isn't used by any schema, tx is the transaction the schema is created in
+func (a *Allocator) Next(tx *gorm.DB) (int32, error) {
+	id, err := a.take(tx)
+	if err != nil {
+		return 0, fmt.Errorf("error reserving schema id: %w", err)
+	}
+
+	return int32(id), nil
+}
+
+// Release gives back an id from Next that wasn't used because its transaction was rolled back
+func (a *Allocator) Release(id int32) {
+	if a.db == nil {
+		// the reservation was rolled back with the transaction
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.released = append(a.released, int64(id))
+}
+
+// Ensure makes sure the sequence never reserves an imported id or anything below it, what's left of the
+// reserved block up to the id is dropped
+func (a *Allocator) Ensure(tx *gorm.DB, id int32) error {
+	if int64(id) < a.start || int64(id) > a.end {
+		return nil
+	}
+
+	if a.db == nil {
+		return dbModels.EnsureSequenceID(tx, a.sequence, int64(id), a.start, a.end)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.next <= int64(id) {
+		a.next = int64(id) + 1
+	}
+	released := a.released[:0]
+	for _, releasedID := range a.released {
+		if releasedID != int64(id) {
+			released = append(released, releasedID)
+		}
+	}
+	a.released = released
+
+	return dbModels.EnsureSequenceID(a.db, a.sequence, int64(id), a.start, a.end)
+}
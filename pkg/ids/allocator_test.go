@@ -0,0 +1,200 @@
+package ids
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/rmb938/franz-schema-registry/pkg/database/testdb"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func insertSchema(db *gorm.DB, globalID int32) error {
+	return db.Create(&dbModels.Schema{
+		ID:         uuid.New(),
+		GlobalID:   globalID,
+		Schema:     fmt.Sprintf(`{"type": "fixed", "name": "test", "size": %d}`, globalID),
+		Hash:       uuid.New().String(),
+		SchemaType: dbModels.SchemaTypeAvro,
+	}).Error
+}
+
+func TestAllocator(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// the sequence starts after schemas created before it
+	assert.NoError(t, insertSchema(db, 1))
+	assert.NoError(t, insertSchema(db, 3))
+
+	allocator := NewAllocator(db, dbModels.SequenceNameSchemaIDs, configuration.SchemaIDsConfiguration{BlockSize: 5, RangeStart: 1, RangeEnd: math.MaxInt32})
+
+	id, err := allocator.Next(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), id)
+
+	// the whole block is reserved at once
+	sequence := &dbModels.Sequence{}
+	assert.NoError(t, db.Where("name = ?", dbModels.SequenceNameSchemaIDs).First(sequence).Error)
+	assert.Equal(t, int64(8), sequence.NextValue)
+
+	// released ids are handed out again
+	allocator.Release(4)
+	id, err = allocator.Next(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), id)
+
+	// imported ids push the sequence past them and drop the reserved block up to them
+	assert.NoError(t, allocator.Ensure(db, 6))
+	id, err = allocator.Next(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), id)
+
+	assert.NoError(t, allocator.Ensure(db, 20))
+	for _, expected := range []int32{21, 22} {
+		id, err = allocator.Next(db)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, id)
+	}
+}
+
+func TestAllocatorRange(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// only schemas in the range of a sequence seed it
+	assert.NoError(t, insertSchema(db, 50))
+
+	region := NewAllocator(db, dbModels.SequenceNameSchemaIDs, configuration.SchemaIDsConfiguration{BlockSize: 2, RangeStart: 100, RangeEnd: 102})
+	global := NewAllocator(db, dbModels.SequenceNameSchemaIDs, configuration.SchemaIDsConfiguration{BlockSize: 2, RangeStart: 1, RangeEnd: math.MaxInt32})
+
+	for _, expected := range []int32{100, 101, 102} {
+		id, err := region.Next(db)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, id)
+	}
+
+	// the range has its own sequence
+	id, err := global.Next(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(51), id)
+
+	_, err = region.Next(db)
+	assert.ErrorContains(t, err, "run out of values")
+
+	// imported ids outside of the range don't touch its sequence
+	assert.NoError(t, region.Ensure(db, 1000))
+	sequence := &dbModels.Sequence{}
+	assert.NoError(t, db.Where("name = ?", "SCHEMA_IDS_100_102").First(sequence).Error)
+	assert.Equal(t, int64(102), sequence.NextValue)
+}
+
+func TestAllocatorTransaction(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	allocator := NewAllocator(nil, dbModels.SequenceNameSchemaIDs, configuration.SchemaIDsConfiguration{BlockSize: 5, RangeStart: 1, RangeEnd: math.MaxInt32})
+
+	// ids are reserved one at a time in the transaction so rolling back doesn't burn them
+	err := db.Transaction(func(tx *gorm.DB) error {
+		id, err := allocator.Next(tx)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), id)
+
+		return fmt.Errorf("rollback")
+	})
+	assert.Error(t, err)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		id, err := allocator.Next(tx)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), id)
+
+		return insertSchema(tx, id)
+	})
+	assert.NoError(t, err)
+
+	id, err := allocator.Next(db)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), id)
+}
+
+func TestAllocatorConcurrent(t *testing.T) {
+	db, dbFile := testdb.TempDatabase(t)
+	defer func() {
+		err := os.Remove(dbFile)
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	// sqlite only allows a single writer so everything waits for the one connection instead of failing
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	// every allocator is a replica, each with a few registrations at once
+	config := configuration.SchemaIDsConfiguration{BlockSize: 3, RangeStart: 1, RangeEnd: math.MaxInt32}
+	allocators := []*Allocator{
+		NewAllocator(db, dbModels.SequenceNameSchemaIDs, config),
+		NewAllocator(db, dbModels.SequenceNameSchemaIDs, config),
+		NewAllocator(db, dbModels.SequenceNameSchemaIDs, config),
+	}
+
+	var mu sync.Mutex
+	allocated := make(map[int32]struct{})
+
+	var wg sync.WaitGroup
+	for _, allocator := range allocators {
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func(allocator *Allocator) {
+				defer wg.Done()
+
+				for i := 0; i < 25; i++ {
+					// sqlite only allows a single writer so the schema can't be created in a transaction around Next
+					id, err := allocator.Next(db)
+					if !assert.NoError(t, err) {
+						return
+					}
+
+					mu.Lock()
+					_, exists := allocated[id]
+					allocated[id] = struct{}{}
+					mu.Unlock()
+					assert.False(t, exists, "id %d was allocated twice", id)
+
+					assert.NoError(t, insertSchema(db, id))
+				}
+			}(allocator)
+		}
+	}
+	wg.Wait()
+
+	assert.Len(t, allocated, 3*4*25)
+
+	var count int64
+	assert.NoError(t, db.Model(&dbModels.Schema{}).Count(&count).Error)
+	assert.Equal(t, int64(3*4*25), count)
+}
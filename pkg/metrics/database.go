@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// InstrumentDatabase records the duration and errors of database queries and transactions
+// and registers the subject and schema counts
+func InstrumentDatabase(db *gorm.DB) error {
+	if err := db.Use(&databasePlugin{}); err != nil {
+		return fmt.Errorf("error registering database metrics plugin: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("error getting database connection pool: %w", err)
+	}
+
+	// transactions don't go through callbacks so wrap the connection pool to time them
+	pool := &instrumentedConnPool{DB: sqlDB}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+
+	if err := prometheus.Register(&countsCollector{db: db}); err != nil {
+		return fmt.Errorf("error registering database counts collector: %w", err)
+	}
+
+	return nil
+}
+
+type databasePlugin struct{}
+
+func (p *databasePlugin) Name() string {
+	return "franz-schema-registry:metrics"
+}
+
+func (p *databasePlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	// gorm doesn't export its processor type so each operation is registered by hand
+	if err := callbacks.Create().Before("gorm:create").Register("metrics:before_create", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")); err != nil {
+		return err
+	}
+
+	if err := callbacks.Query().Before("gorm:query").Register("metrics:before_query", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")); err != nil {
+		return err
+	}
+
+	if err := callbacks.Update().Before("gorm:update").Register("metrics:before_update", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")); err != nil {
+		return err
+	}
+
+	if err := callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")); err != nil {
+		return err
+	}
+
+	if err := callbacks.Row().Before("gorm:row").Register("metrics:before_row", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")); err != nil {
+		return err
+	}
+
+	if err := callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(startTimeKey, time.Now())
+}
+
+func observeQuery(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		if start, ok := tx.InstanceGet(startTimeKey); ok {
+			databaseQueryDuration.WithLabelValues(operation).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+
+		// not found is expected and handled by the callers
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			databaseErrorsTotal.WithLabelValues(operation).Inc()
+		}
+	}
+}
+
+// instrumentedConnPool times the transactions started from the pool
+type instrumentedConnPool struct {
+	*sql.DB
+}
+
+func (p *instrumentedConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		databaseErrorsTotal.WithLabelValues("begin").Inc()
+		return nil, err
+	}
+
+	return &instrumentedTx{Tx: tx, start: time.Now()}, nil
+}
+
+// GetDBConn lets gorm return the underlying pool from db.DB()
+func (p *instrumentedConnPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+type instrumentedTx struct {
+	*sql.Tx
+	start time.Time
+}
+
+func (t *instrumentedTx) Commit() error {
+	err := t.Tx.Commit()
+	if err != nil {
+		databaseErrorsTotal.WithLabelValues("commit").Inc()
+	}
+	databaseTransactionDuration.WithLabelValues("commit").Observe(time.Since(t.start).Seconds())
+	return err
+}
+
+func (t *instrumentedTx) Rollback() error {
+	err := t.Tx.Rollback()
+	// gorm rolls back after a failed commit, which is already done so isn't an error
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		databaseErrorsTotal.WithLabelValues("rollback").Inc()
+	}
+	if !errors.Is(err, sql.ErrTxDone) {
+		databaseTransactionDuration.WithLabelValues("rollback").Observe(time.Since(t.start).Seconds())
+	}
+	return err
+}
+
+var (
+	subjectsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "subjects"),
+		"Number of subjects that aren't deleted.", nil, nil)
+	subjectVersionsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "subject_versions"),
+		"Number of subject versions that aren't deleted.", nil, nil)
+	schemasDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "schemas"),
+		"Number of schemas by schema type.", []string{"schema_type"}, nil)
+)
+
+// countsCollector counts the subjects and schemas when metrics are scraped
+type countsCollector struct {
+	db *gorm.DB
+}
+
+func (c *countsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- subjectsDesc
+	ch <- subjectVersionsDesc
+	ch <- schemasDesc
+}
+
+func (c *countsCollector) Collect(ch chan<- prometheus.Metric) {
+	var subjects int64
+	if err := c.db.Table("subjects").Where("deleted_at IS NULL").Count(&subjects).Error; err != nil {
+		ch <- prometheus.NewInvalidMetric(subjectsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(subjectsDesc, prometheus.GaugeValue, float64(subjects))
+	}
+
+	var subjectVersions int64
+	if err := c.db.Table("subject_versions").Where("deleted_at IS NULL").Count(&subjectVersions).Error; err != nil {
+		ch <- prometheus.NewInvalidMetric(subjectVersionsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(subjectVersionsDesc, prometheus.GaugeValue, float64(subjectVersions))
+	}
+
+	var schemaCounts []struct {
+		SchemaType string
+		Count      int64
+	}
+	err := c.db.Table("schemas").Select("schema_type, count(*) AS count").Where("deleted_at IS NULL").
+		Group("schema_type").Scan(&schemaCounts).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(schemasDesc, err)
+		return
+	}
+
+	for _, schemaCount := range schemaCounts {
+		ch <- prometheus.MustNewConstMetric(schemasDesc, prometheus.GaugeValue, float64(schemaCount.Count), schemaCount.SchemaType)
+	}
+}
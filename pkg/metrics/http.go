@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics in the prometheus format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware records the count and duration of requests by the chi route pattern they matched,
+// patterns are used instead of paths to keep the number of label values bounded
+func Middleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+			if pattern := routeContext.RoutePattern(); len(pattern) > 0 {
+				route = pattern
+			}
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+
+		httpRequestsTotal.WithLabelValues(r.Method, route, code).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	}
+
+	return http.HandlerFunc(fn)
+}
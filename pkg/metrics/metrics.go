@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "franz_schema_registry"
+
+type RegistrationOutcome string
+
+const (
+	RegistrationOutcomeNewSchema      RegistrationOutcome = "new_schema"
+	RegistrationOutcomeExistingSchema RegistrationOutcome = "existing_schema"
+	RegistrationOutcomeIncompatible   RegistrationOutcome = "incompatible"
+	RegistrationOutcomeParseError     RegistrationOutcome = "parse_error"
+)
+
+type CacheResult string
+
+const (
+	CacheResultHit  CacheResult = "hit"
+	CacheResultMiss CacheResult = "miss"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of http requests by route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of http requests by route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	schemaRegistrationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "schemas",
+		Name:      "registrations_total",
+		Help:      "Total number of schema registrations by schema type and outcome.",
+	}, []string{"schema_type", "outcome"})
+
+	compatibilityCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "schemas",
+		Name:      "compatibility_check_duration_seconds",
+		Help:      "Duration of checking a schema against the existing versions of a subject by schema type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"schema_type"})
+
+	databaseQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	databaseTransactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "transaction_duration_seconds",
+		Help:      "Duration of database transactions by how they finished, commit or rollback.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	databaseErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "errors_total",
+		Help:      "Total number of database errors by operation.",
+	}, []string{"operation"})
+
+	databaseTransactionRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "transaction_retries_total",
+		Help:      "Total number of database transactions run again because they conflicted with a concurrent transaction.",
+	})
+
+	cacheLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Total number of in memory cache lookups by cache and result, hit or miss.",
+	}, []string{"cache", "result"})
+
+	cacheEvictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "evictions_total",
+		Help:      "Total number of entries evicted from in memory caches because they were full or invalidated.",
+	}, []string{"cache"})
+)
+
+// ObserveSchemaRegistration counts the outcome of registering a schema
+func ObserveSchemaRegistration(schemaType string, outcome RegistrationOutcome) {
+	schemaRegistrationsTotal.WithLabelValues(schemaType, string(outcome)).Inc()
+}
+
+// NewCompatibilityCheckTimer starts timing a compatibility check, call ObserveDuration on the returned timer once it's done
+func NewCompatibilityCheckTimer(schemaType string) *prometheus.Timer {
+	return prometheus.NewTimer(compatibilityCheckDuration.WithLabelValues(schemaType))
+}
+
+// ObserveTransactionRetry counts a transaction being run again
+func ObserveTransactionRetry() {
+	databaseTransactionRetriesTotal.Inc()
+}
+
+// ObserveCacheLookup counts a lookup in the named cache
+func ObserveCacheLookup(cache string, result CacheResult) {
+	cacheLookupsTotal.WithLabelValues(cache, string(result)).Inc()
+}
+
+// ObserveCacheEviction counts an entry evicted from the named cache
+func ObserveCacheEviction(cache string) {
+	cacheEvictionsTotal.WithLabelValues(cache).Inc()
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rmb938/franz-schema-registry/pkg/configuration"
+	"github.com/rmb938/franz-schema-registry/pkg/database/migrations"
+	dbModels "github.com/rmb938/franz-schema-registry/pkg/database/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Route("/subjects", func(r chi.Router) {
+		r.Get("/{subject}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+	})
+
+	for _, subject := range []string{"one", "two"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/subjects/"+subject, nil))
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	// requests are grouped by route pattern instead of path
+	assert.Equal(t, float64(2), testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "/subjects/{subject}", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "unmatched", "404")))
+}
+
+func TestObserveSchemaRegistration(t *testing.T) {
+	ObserveSchemaRegistration("AVRO", RegistrationOutcomeNewSchema)
+	ObserveSchemaRegistration("AVRO", RegistrationOutcomeNewSchema)
+	ObserveSchemaRegistration("JSON", RegistrationOutcomeIncompatible)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(schemaRegistrationsTotal.WithLabelValues("AVRO", string(RegistrationOutcomeNewSchema))))
+	assert.Equal(t, float64(1), testutil.ToFloat64(schemaRegistrationsTotal.WithLabelValues("JSON", string(RegistrationOutcomeIncompatible))))
+}
+
+func TestInstrumentDatabase(t *testing.T) {
+	f, err := os.CreateTemp("", "franz-go-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer func() {
+		err := os.Remove(f.Name())
+		if err != nil {
+			t.Error("db file remove error:", err)
+		}
+	}()
+
+	db, err := gorm.Open(sqlite.Open(f.Name()), configuration.GormConfig())
+	assert.NoError(t, err)
+	assert.NoError(t, InstrumentDatabase(db))
+	assert.NoError(t, migrations.RunMigrations(db))
+
+	commits := histogramCount(t, databaseTransactionDuration.WithLabelValues("commit"))
+	rollbacks := histogramCount(t, databaseTransactionDuration.WithLabelValues("rollback"))
+
+	// committed transaction
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&dbModels.Subject{ID: uuid.New(), Name: "one"}).Error
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, commits+1, histogramCount(t, databaseTransactionDuration.WithLabelValues("commit")))
+
+	// rolled back transaction
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dbModels.Subject{ID: uuid.New(), Name: "two"}).Error; err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, rollbacks+1, histogramCount(t, databaseTransactionDuration.WithLabelValues("rollback")))
+
+	// failed query
+	before := testutil.ToFloat64(databaseErrorsTotal.WithLabelValues("raw"))
+	assert.Error(t, db.Exec("SELECT * FROM missing_table").Error)
+	assert.Equal(t, before+1, testutil.ToFloat64(databaseErrorsTotal.WithLabelValues("raw")))
+
+	// not found isn't an error
+	before = testutil.ToFloat64(databaseErrorsTotal.WithLabelValues("query"))
+	assert.ErrorIs(t, db.Where("name = ?", "missing").First(&dbModels.Subject{}).Error, gorm.ErrRecordNotFound)
+	assert.Equal(t, before, testutil.ToFloat64(databaseErrorsTotal.WithLabelValues("query")))
+
+	// pool settings can still be changed
+	_, err = db.DB()
+	assert.NoError(t, err)
+
+	// counts only include what was committed
+	expected := `
+# HELP franz_schema_registry_subjects Number of subjects that aren't deleted.
+# TYPE franz_schema_registry_subjects gauge
+franz_schema_registry_subjects 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(&countsCollector{db: db}, strings.NewReader(expected), "franz_schema_registry_subjects"))
+}
+
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	metric := &dto.Metric{}
+	assert.NoError(t, observer.(prometheus.Histogram).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
@@ -1,6 +1,7 @@
 package schemas
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/hamba/avro/v2"
@@ -10,10 +11,22 @@ type ParsedAvroSchema struct {
 	avroSchema avro.Schema
 }
 
-func (s *ParsedAvroSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (bool, error) {
+// CanonicalString returns the schema in Parsing Canonical Form but keeps the attributes that change how data is read
+// like logical types, defaults and aliases
+// https://avro.apache.org/docs/1.11.1/specification/#parsing-canonical-form-for-schemas
+func (s *ParsedAvroSchema) CanonicalString() (string, error) {
+	canonical, err := json.Marshal(s.avroSchema)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling avro schema: %w", err)
+	}
+
+	return string(canonical), nil
+}
+
+func (s *ParsedAvroSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (bool, []string, error) {
 	previousAvroSchema, ok := previousSchema.(*ParsedAvroSchema)
 	if !ok {
-		return false, fmt.Errorf("cannot check compatibility, previous schema isn't avro")
+		return false, nil, fmt.Errorf("cannot check compatibility, previous schema isn't avro")
 	}
 
 	schemaCompat := avro.NewSchemaCompatibility()
@@ -26,12 +39,22 @@ func (s *ParsedAvroSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (b
 	// so this is probably ok
 	compatibilityErr := schemaCompat.Compatible(previousAvroSchema.avroSchema, s.avroSchema)
 	if compatibilityErr != nil {
-		return false, nil
-	}
+		// the avro library only returns the first incompatibility it finds without saying where it is
+		// so walk the schemas again to build the detailed report
+		incompatibilities := checkAvroCompatibility(previousAvroSchema.avroSchema, s.avroSchema)
+		if len(incompatibilities) == 0 {
+			return false, []string{compatibilityErr.Error()}, nil
+		}
 
-	// TODO: eventually like json return the reasoning why, not just true/false
+		reasons := make([]string, 0, len(incompatibilities))
+		for _, incompatibility := range incompatibilities {
+			reasons = append(reasons, incompatibility.String())
+		}
 
-	return true, nil
+		return false, reasons, nil
+	}
+
+	return true, nil, nil
 }
 
 func isAvroOverrideReferenceName(references map[string]avro.Schema, schema avro.Schema, seenRecords map[string]avro.Schema) (string, bool) {
@@ -93,3 +116,46 @@ func isAvroOverrideReferenceName(references map[string]avro.Schema, schema avro.
 
 	return "", false
 }
+
+func (s *ParsedAvroSchema) Names() []string {
+	names := make([]string, 0)
+	collectAvroNames(s.avroSchema, "", make(map[string]struct{}), &names)
+
+	return names
+}
+
+// collectAvroNames adds the full names of named schemas and the paths of record fields, seen stops recursive records
+func collectAvroNames(schema avro.Schema, path string, seen map[string]struct{}, names *[]string) {
+	switch v := schema.(type) {
+	case *avro.RefSchema:
+		collectAvroNames(v.Schema(), path, seen, names)
+	case *avro.RecordSchema:
+		if _, ok := seen[v.FullName()]; ok {
+			return
+		}
+		seen[v.FullName()] = struct{}{}
+		defer delete(seen, v.FullName())
+
+		*names = append(*names, v.FullName())
+		for _, field := range v.Fields() {
+			fieldPath := field.Name()
+			if len(path) > 0 {
+				fieldPath = path + "." + field.Name()
+			}
+
+			*names = append(*names, fieldPath)
+			collectAvroNames(field.Type(), fieldPath, seen, names)
+		}
+	case avro.NamedSchema:
+		// enums and fixed
+		*names = append(*names, v.FullName())
+	case *avro.ArraySchema:
+		collectAvroNames(v.Items(), path, seen, names)
+	case *avro.MapSchema:
+		collectAvroNames(v.Values(), path, seen, names)
+	case *avro.UnionSchema:
+		for _, unionType := range v.Types() {
+			collectAvroNames(unionType, path, seen, names)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package schemas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hamba/avro/v2"
+)
+
+type AvroIncompatibilityType string
+
+// Incompatibility types match the ones used by the Java Avro library and Confluent SR
+// https://github.com/apache/avro/blob/916a09ce852769b9172882957e4b766b2970dd52/lang/java/avro/src/main/java/org/apache/avro/SchemaCompatibility.java#L1044
+const (
+	AvroIncompatibilityNameMismatch                   AvroIncompatibilityType = "NAME_MISMATCH"
+	AvroIncompatibilityFixedSizeMismatch              AvroIncompatibilityType = "FIXED_SIZE_MISMATCH"
+	AvroIncompatibilityMissingEnumSymbols             AvroIncompatibilityType = "MISSING_ENUM_SYMBOLS"
+	AvroIncompatibilityReaderFieldMissingDefaultValue AvroIncompatibilityType = "READER_FIELD_MISSING_DEFAULT_VALUE"
+	AvroIncompatibilityTypeMismatch                   AvroIncompatibilityType = "TYPE_MISMATCH"
+	AvroIncompatibilityMissingUnionBranch             AvroIncompatibilityType = "MISSING_UNION_BRANCH"
+)
+
+// AvroIncompatibility is a single reason why the reader schema can't read data written with the writer schema
+type AvroIncompatibility struct {
+	Type AvroIncompatibilityType
+	// Location is the json path to the offending part of the reader schema
+	Location string
+	Message  string
+}
+
+func (i AvroIncompatibility) Description() string {
+	switch i.Type {
+	case AvroIncompatibilityNameMismatch:
+		return fmt.Sprintf("The name of the schema has changed (path '%s')", i.Location)
+	case AvroIncompatibilityFixedSizeMismatch:
+		return fmt.Sprintf("The size of FIXED type field at path '%s' in the reader schema does not match with the writer schema", i.Location)
+	case AvroIncompatibilityMissingEnumSymbols:
+		return fmt.Sprintf("The reader schema is missing enum symbols '%s' at path '%s' in the writer schema", i.Message, i.Location)
+	case AvroIncompatibilityReaderFieldMissingDefaultValue:
+		return fmt.Sprintf("The field '%s' at path '%s' in the reader schema has no default value and is missing in the writer schema", i.Message, i.Location)
+	case AvroIncompatibilityTypeMismatch:
+		return fmt.Sprintf("The type (path '%s') of a field in the reader schema does not match with the writer schema", i.Location)
+	case AvroIncompatibilityMissingUnionBranch:
+		return fmt.Sprintf("The reader schema is missing a type inside a union field at path '%s' in the writer schema", i.Location)
+	}
+
+	return fmt.Sprintf("Unknown incompatibility at path '%s'", i.Location)
+}
+
+// String returns the incompatibility in the same format as Confluent SR
+func (i AvroIncompatibility) String() string {
+	return fmt.Sprintf("{errorType:'%s', description:'%s', additionalInfo:'%s'}", i.Type, i.Description(), i.Message)
+}
+
+type avroCompatibilityKey struct {
+	reader [32]byte
+	writer [32]byte
+}
+
+// avroCompatibilityChecker walks the reader and writer schemas the same way the hamba avro library does
+// but collects every incompatibility along with where it was found instead of stopping at the first one
+type avroCompatibilityChecker struct {
+	// results holds the incompatibilities of record pairs that were already checked,
+	// a nil entry means the pair is still being checked, so we break the recursion there
+	results map[avroCompatibilityKey][]AvroIncompatibility
+}
+
+func checkAvroCompatibility(reader, writer avro.Schema) []AvroIncompatibility {
+	checker := &avroCompatibilityChecker{
+		results: make(map[avroCompatibilityKey][]AvroIncompatibility),
+	}
+
+	incompatibilities := checker.compatible(reader, writer, nil)
+
+	// the same named schema can be reached more than once, so only report it once
+	seen := make(map[string]struct{})
+	deduped := make([]AvroIncompatibility, 0, len(incompatibilities))
+	for _, incompatibility := range incompatibilities {
+		key := incompatibility.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		deduped = append(deduped, incompatibility)
+	}
+
+	return deduped
+}
+
+func (c *avroCompatibilityChecker) compatible(reader, writer avro.Schema, location []string) []AvroIncompatibility {
+	// only records can recurse, everything else is cheap to check again and keeps the location of each incompatibility
+	if reader.Type() != avro.Record && reader.Type() != avro.Ref {
+		return c.match(reader, writer, location)
+	}
+
+	key := avroCompatibilityKey{reader: reader.Fingerprint(), writer: writer.Fingerprint()}
+	if incompatibilities, ok := c.results[key]; ok {
+		return incompatibilities
+	}
+
+	c.results[key] = nil
+	incompatibilities := c.match(reader, writer, location)
+	c.results[key] = incompatibilities
+
+	return incompatibilities
+}
+
+func (c *avroCompatibilityChecker) match(reader, writer avro.Schema, location []string) []AvroIncompatibility {
+	// If the schema is a reference, get the actual schema
+	if reader.Type() == avro.Ref {
+		reader = reader.(*avro.RefSchema).Schema()
+	}
+	if writer.Type() == avro.Ref {
+		writer = writer.(*avro.RefSchema).Schema()
+	}
+
+	if reader.Type() != writer.Type() {
+		if writer.Type() == avro.Union {
+			// Reader must be compatible with all types in writer
+			var incompatibilities []AvroIncompatibility
+			for index, schema := range writer.(*avro.UnionSchema).Types() {
+				incompatibilities = append(incompatibilities, c.compatible(reader, schema, appendLocation(location, strconv.Itoa(index)))...)
+			}
+
+			return incompatibilities
+		}
+
+		if reader.Type() == avro.Union {
+			// Writer must be compatible with at least one reader schema
+			for _, schema := range reader.(*avro.UnionSchema).Types() {
+				if len(c.compatible(schema, writer, location)) == 0 {
+					return nil
+				}
+			}
+
+			return []AvroIncompatibility{
+				newAvroIncompatibility(AvroIncompatibilityMissingUnionBranch, location,
+					fmt.Sprintf("reader union lacking writer type: %s", strings.ToUpper(string(writer.Type())))),
+			}
+		}
+
+		if isAvroPromotable(reader.Type(), writer.Type()) {
+			return nil
+		}
+
+		return []AvroIncompatibility{
+			newAvroIncompatibility(AvroIncompatibilityTypeMismatch, location,
+				fmt.Sprintf("reader type: %s not compatible with writer type: %s", strings.ToUpper(string(reader.Type())), strings.ToUpper(string(writer.Type())))),
+		}
+	}
+
+	switch reader.Type() {
+	case avro.Array:
+		return c.compatible(reader.(*avro.ArraySchema).Items(), writer.(*avro.ArraySchema).Items(), appendLocation(location, "items"))
+
+	case avro.Map:
+		return c.compatible(reader.(*avro.MapSchema).Values(), writer.(*avro.MapSchema).Values(), appendLocation(location, "values"))
+
+	case avro.Fixed:
+		r := reader.(*avro.FixedSchema)
+		w := writer.(*avro.FixedSchema)
+
+		if incompatibility := checkAvroSchemaName(r, w, location); incompatibility != nil {
+			return []AvroIncompatibility{*incompatibility}
+		}
+
+		if r.Size() != w.Size() {
+			return []AvroIncompatibility{
+				newAvroIncompatibility(AvroIncompatibilityFixedSizeMismatch, appendLocation(location, "size"),
+					fmt.Sprintf("expected: %d, found: %d", w.Size(), r.Size())),
+			}
+		}
+
+	case avro.Enum:
+		r := reader.(*avro.EnumSchema)
+		w := writer.(*avro.EnumSchema)
+
+		if incompatibility := checkAvroSchemaName(r, w, location); incompatibility != nil {
+			return []AvroIncompatibility{*incompatibility}
+		}
+
+		readerSymbols := make(map[string]struct{}, len(r.Symbols()))
+		for _, symbol := range r.Symbols() {
+			readerSymbols[symbol] = struct{}{}
+		}
+
+		var missingSymbols []string
+		for _, symbol := range w.Symbols() {
+			if _, ok := readerSymbols[symbol]; !ok {
+				missingSymbols = append(missingSymbols, symbol)
+			}
+		}
+
+		if len(missingSymbols) > 0 {
+			return []AvroIncompatibility{
+				newAvroIncompatibility(AvroIncompatibilityMissingEnumSymbols, appendLocation(location, "symbols"),
+					fmt.Sprintf("[%s]", strings.Join(missingSymbols, ", "))),
+			}
+		}
+
+	case avro.Record:
+		r := reader.(*avro.RecordSchema)
+		w := writer.(*avro.RecordSchema)
+
+		if incompatibility := checkAvroSchemaName(r, w, location); incompatibility != nil {
+			return []AvroIncompatibility{*incompatibility}
+		}
+
+		writerFields := make(map[string]*avro.Field, len(w.Fields()))
+		for _, field := range w.Fields() {
+			writerFields[field.Name()] = field
+		}
+
+		var incompatibilities []AvroIncompatibility
+		for index, field := range r.Fields() {
+			fieldLocation := appendLocation(location, "fields", strconv.Itoa(index))
+
+			writerField, ok := writerFields[field.Name()]
+			if !ok {
+				if field.HasDefault() {
+					continue
+				}
+
+				incompatibilities = append(incompatibilities,
+					newAvroIncompatibility(AvroIncompatibilityReaderFieldMissingDefaultValue, fieldLocation, field.Name()))
+				continue
+			}
+
+			incompatibilities = append(incompatibilities, c.compatible(field.Type(), writerField.Type(), appendLocation(fieldLocation, "type"))...)
+		}
+
+		return incompatibilities
+
+	case avro.Union:
+		var incompatibilities []AvroIncompatibility
+		for index, schema := range writer.(*avro.UnionSchema).Types() {
+			incompatibilities = append(incompatibilities, c.compatible(reader, schema, appendLocation(location, strconv.Itoa(index)))...)
+		}
+
+		return incompatibilities
+	}
+
+	return nil
+}
+
+func checkAvroSchemaName(reader, writer avro.NamedSchema, location []string) *AvroIncompatibility {
+	if reader.FullName() == writer.FullName() {
+		return nil
+	}
+
+	incompatibility := newAvroIncompatibility(AvroIncompatibilityNameMismatch, appendLocation(location, "name"),
+		fmt.Sprintf("expected: %s", writer.FullName()))
+	return &incompatibility
+}
+
+// isAvroPromotable returns if data written with the writer type can be read as the reader type
+// https://avro.apache.org/docs/1.11.1/specification/#schema-resolution
+func isAvroPromotable(reader, writer avro.Type) bool {
+	switch writer {
+	case avro.Int:
+		return reader == avro.Long || reader == avro.Float || reader == avro.Double
+	case avro.Long:
+		return reader == avro.Float || reader == avro.Double
+	case avro.Float:
+		return reader == avro.Double
+	case avro.String:
+		return reader == avro.Bytes
+	case avro.Bytes:
+		return reader == avro.String
+	}
+
+	return false
+}
+
+func newAvroIncompatibility(incompatibilityType AvroIncompatibilityType, location []string, message string) AvroIncompatibility {
+	return AvroIncompatibility{
+		Type:     incompatibilityType,
+		Location: "/" + strings.Join(location, "/"),
+		Message:  message,
+	}
+}
+
+// appendLocation copies the location so sibling paths don't share the same backing array
+func appendLocation(location []string, elements ...string) []string {
+	newLocation := make([]string, 0, len(location)+len(elements))
+	newLocation = append(newLocation, location...)
+	return append(newLocation, elements...)
+}
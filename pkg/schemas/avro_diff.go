@@ -0,0 +1,320 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hamba/avro/v2"
+)
+
+func (s *ParsedAvroSchema) Diff(previousSchema ParsedSchema) ([]Change, error) {
+	previousAvroSchema, ok := previousSchema.(*ParsedAvroSchema)
+	if !ok {
+		return nil, fmt.Errorf("cannot diff, previous schema isn't avro")
+	}
+
+	differ := &avroDiffer{
+		changes: make([]Change, 0),
+		seen:    make(map[[2]string]struct{}),
+	}
+	differ.diff(previousAvroSchema.avroSchema, s.avroSchema, "")
+
+	return differ.changes, nil
+}
+
+type avroDiffer struct {
+	changes []Change
+	// seen holds the pairs of records being compared on the current path, so recursive records stop
+	// while a record used by more than one field is still compared under each of them
+	seen map[[2]string]struct{}
+}
+
+// isAvroReadable returns if data written with the writer schema can be read with the reader schema
+func isAvroReadable(reader, writer avro.Schema) bool {
+	return len(checkAvroCompatibility(reader, writer)) == 0
+}
+
+func derefAvroSchema(schema avro.Schema) avro.Schema {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		return ref.Schema()
+	}
+
+	return schema
+}
+
+// avroTypeKey is the same for schemas of the same type, named schemas with different names have the same key
+// so they're compared as a name change
+func avroTypeKey(schema avro.Schema) string {
+	schema = derefAvroSchema(schema)
+
+	if logicalSchema, ok := schema.(avro.LogicalTypeSchema); ok && logicalSchema.Logical() != nil {
+		return fmt.Sprintf("%s:%s", schema.Type(), logicalSchema.Logical().Type())
+	}
+
+	return string(schema.Type())
+}
+
+// avroUnionBranchKey is what union branches are matched by, only one branch of each unnamed type is allowed
+func avroUnionBranchKey(schema avro.Schema) string {
+	schema = derefAvroSchema(schema)
+
+	if namedSchema, ok := schema.(avro.NamedSchema); ok {
+		return namedSchema.FullName()
+	}
+
+	return avroTypeKey(schema)
+}
+
+// avroTypeString describes the type for a change
+func avroTypeString(schema avro.Schema) string {
+	schema = derefAvroSchema(schema)
+
+	switch v := schema.(type) {
+	case avro.NamedSchema:
+		return v.FullName()
+	case *avro.ArraySchema:
+		return fmt.Sprintf("array<%s>", avroTypeString(v.Items()))
+	case *avro.MapSchema:
+		return fmt.Sprintf("map<%s>", avroTypeString(v.Values()))
+	case *avro.UnionSchema:
+		types := make([]string, 0, len(v.Types()))
+		for _, unionType := range v.Types() {
+			types = append(types, avroTypeString(unionType))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(types, ", "))
+	case *avro.PrimitiveSchema:
+		if v.Logical() != nil {
+			return fmt.Sprintf("%s(%s)", v.Type(), v.Logical().Type())
+		}
+	}
+
+	return string(schema.Type())
+}
+
+func avroDefaultString(field *avro.Field) string {
+	if !field.HasDefault() {
+		return ""
+	}
+
+	def, err := json.Marshal(field.Default())
+	if err != nil {
+		return fmt.Sprint(field.Default())
+	}
+
+	return string(def)
+}
+
+func (d *avroDiffer) diff(previous, current avro.Schema, path string) {
+	previous = derefAvroSchema(previous)
+	current = derefAvroSchema(current)
+
+	if avroTypeKey(previous) != avroTypeKey(current) {
+		d.changes = append(d.changes, Change{
+			Kind:     ChangeTypeChanged,
+			Path:     path,
+			Previous: avroTypeString(previous),
+			Current:  avroTypeString(current),
+			Backward: isAvroReadable(current, previous),
+			Forward:  isAvroReadable(previous, current),
+		})
+		return
+	}
+
+	switch p := previous.(type) {
+	case *avro.RecordSchema:
+		c := current.(*avro.RecordSchema)
+
+		key := [2]string{p.FullName(), c.FullName()}
+		if _, ok := d.seen[key]; ok {
+			return
+		}
+		d.seen[key] = struct{}{}
+		defer delete(d.seen, key)
+
+		d.diffName(p, c, path)
+		d.diffFields(p, c, path)
+
+	case *avro.EnumSchema:
+		c := current.(*avro.EnumSchema)
+
+		d.diffName(p, c, path)
+		d.diffSymbols(p, c, path)
+
+		if p.Default() != c.Default() {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeDefaultChanged,
+				Path:     path,
+				Previous: p.Default(),
+				Current:  c.Default(),
+				Backward: true,
+				Forward:  true,
+			})
+		}
+
+	case *avro.FixedSchema:
+		c := current.(*avro.FixedSchema)
+
+		d.diffName(p, c, path)
+
+		if p.Size() != c.Size() {
+			// the size is how many bytes are read so data of one size can't be read as another
+			d.changes = append(d.changes, Change{
+				Kind:       ChangeConstraintChanged,
+				Path:       path,
+				Constraint: "size",
+				Previous:   fmt.Sprint(p.Size()),
+				Current:    fmt.Sprint(c.Size()),
+			})
+		}
+
+	case *avro.ArraySchema:
+		d.diff(p.Items(), current.(*avro.ArraySchema).Items(), path+"[]")
+
+	case *avro.MapSchema:
+		d.diff(p.Values(), current.(*avro.MapSchema).Values(), path+"{}")
+
+	case *avro.UnionSchema:
+		d.diffUnion(p, current.(*avro.UnionSchema), path)
+	}
+}
+
+func (d *avroDiffer) diffName(previous, current avro.NamedSchema, path string) {
+	if previous.FullName() == current.FullName() {
+		return
+	}
+
+	d.changes = append(d.changes, Change{
+		Kind:     ChangeNameChanged,
+		Path:     path,
+		Previous: previous.FullName(),
+		Current:  current.FullName(),
+		Backward: isAvroReadable(current, previous),
+		Forward:  isAvroReadable(previous, current),
+	})
+}
+
+// diffFields matches fields by name, an added field can only read old data when it has a default
+// and a removed field can only be read by old readers when it had one
+func (d *avroDiffer) diffFields(previous, current *avro.RecordSchema, path string) {
+	previousFields := make(map[string]*avro.Field, len(previous.Fields()))
+	for _, field := range previous.Fields() {
+		previousFields[field.Name()] = field
+	}
+
+	currentFields := make(map[string]*avro.Field, len(current.Fields()))
+	for _, field := range current.Fields() {
+		currentFields[field.Name()] = field
+		fieldPath := joinPath(path, field.Name())
+
+		previousField, ok := previousFields[field.Name()]
+		if !ok {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeFieldAdded,
+				Path:     fieldPath,
+				Current:  avroTypeString(field.Type()),
+				Backward: field.HasDefault(),
+				Forward:  true,
+			})
+			continue
+		}
+
+		if previousDefault, currentDefault := avroDefaultString(previousField), avroDefaultString(field); previousDefault != currentDefault {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeDefaultChanged,
+				Path:     fieldPath,
+				Previous: previousDefault,
+				Current:  currentDefault,
+				Backward: true,
+				Forward:  true,
+			})
+		}
+
+		d.diff(previousField.Type(), field.Type(), fieldPath)
+	}
+
+	for _, field := range previous.Fields() {
+		if _, ok := currentFields[field.Name()]; ok {
+			continue
+		}
+
+		d.changes = append(d.changes, Change{
+			Kind:     ChangeFieldRemoved,
+			Path:     joinPath(path, field.Name()),
+			Previous: avroTypeString(field.Type()),
+			Backward: true,
+			Forward:  field.HasDefault(),
+		})
+	}
+}
+
+// diffSymbols reports each added and removed symbol, a reader can't read a symbol it doesn't have
+// unless the reader's enum has a default to read it as
+func (d *avroDiffer) diffSymbols(previous, current *avro.EnumSchema, path string) {
+	previousSymbols := make(map[string]struct{}, len(previous.Symbols()))
+	for _, symbol := range previous.Symbols() {
+		previousSymbols[symbol] = struct{}{}
+	}
+
+	currentSymbols := make(map[string]struct{}, len(current.Symbols()))
+	for _, symbol := range current.Symbols() {
+		currentSymbols[symbol] = struct{}{}
+
+		if _, ok := previousSymbols[symbol]; !ok {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeEnumSymbolAdded,
+				Path:     path,
+				Current:  symbol,
+				Backward: true,
+				Forward:  len(previous.Default()) > 0,
+			})
+		}
+	}
+
+	for _, symbol := range previous.Symbols() {
+		if _, ok := currentSymbols[symbol]; !ok {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeEnumSymbolRemoved,
+				Path:     path,
+				Previous: symbol,
+				Backward: len(current.Default()) > 0,
+				Forward:  true,
+			})
+		}
+	}
+}
+
+// diffUnion reports the union as retyped when its branches changed and diffs the branches in both
+func (d *avroDiffer) diffUnion(previous, current *avro.UnionSchema, path string) {
+	previousBranches := make(map[string]avro.Schema, len(previous.Types()))
+	for _, branch := range previous.Types() {
+		previousBranches[avroUnionBranchKey(branch)] = branch
+	}
+
+	branchesChanged := len(previous.Types()) != len(current.Types())
+	matched := make([][2]avro.Schema, 0)
+	for _, branch := range current.Types() {
+		previousBranch, ok := previousBranches[avroUnionBranchKey(branch)]
+		if !ok {
+			branchesChanged = true
+			continue
+		}
+
+		matched = append(matched, [2]avro.Schema{previousBranch, branch})
+	}
+
+	if branchesChanged {
+		d.changes = append(d.changes, Change{
+			Kind:     ChangeTypeChanged,
+			Path:     path,
+			Previous: avroTypeString(previous),
+			Current:  avroTypeString(current),
+			Backward: isAvroReadable(current, previous),
+			Forward:  isAvroReadable(previous, current),
+		})
+	}
+
+	for _, branches := range matched {
+		d.diff(branches[0], branches[1], path)
+	}
+}
@@ -1 +1,302 @@
 package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsedAvroSchemaCanonicalString(t *testing.T) {
+	schema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "one",
+  "namespace": "com.example",
+  "fields": [
+    {"type": {"type": "int", "logicalType": "date"}, "name": "field1", "default": 0}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	// logical types and defaults are kept
+	canonical, err := schema.CanonicalString()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"com.example.one","type":"record","fields":[{"name":"field1","type":{"type":"int","logicalType":"date"},"default":0}]}`, canonical)
+
+	// the canonical form parses to the same canonical form
+	canonicalSchema, err := ParseSchema(canonical, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+	canonicalAgain, err := canonicalSchema.CanonicalString()
+	assert.NoError(t, err)
+	assert.Equal(t, canonical, canonicalAgain)
+}
+
+func TestParsedAvroSchemaIsBackwardsCompatible(t *testing.T) {
+	tests := []struct {
+		name           string
+		previousSchema string
+		schema         string
+		reasons        []string
+	}{
+		{
+			name:           "same schema",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}]}`,
+		},
+		{
+			name:           "field removed without default",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}, {"name": "field2", "type": "string"}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}]}`,
+			reasons: []string{
+				"{errorType:'READER_FIELD_MISSING_DEFAULT_VALUE', description:'The field 'field2' at path '/fields/1' in the reader schema has no default value and is missing in the writer schema', additionalInfo:'field2'}",
+			},
+		},
+		{
+			name:           "field removed with default",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}, {"name": "field2", "type": "string", "default": ""}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}]}`,
+		},
+		{
+			name:           "type promoted",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "long"}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "int"}]}`,
+		},
+		{
+			name:           "type changed",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "int"}, {"name": "field2", "type": {"type": "array", "items": "int"}}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}, {"name": "field2", "type": {"type": "array", "items": "string"}}]}`,
+			reasons: []string{
+				"{errorType:'TYPE_MISMATCH', description:'The type (path '/fields/0/type') of a field in the reader schema does not match with the writer schema', additionalInfo:'reader type: INT not compatible with writer type: STRING'}",
+				"{errorType:'TYPE_MISMATCH', description:'The type (path '/fields/1/type/items') of a field in the reader schema does not match with the writer schema', additionalInfo:'reader type: INT not compatible with writer type: STRING'}",
+			},
+		},
+		{
+			name:           "record renamed",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": "string"}]}`,
+			schema:         `{"type": "record", "name": "two", "fields": [{"name": "field1", "type": "string"}]}`,
+			reasons: []string{
+				"{errorType:'NAME_MISMATCH', description:'The name of the schema has changed (path '/name')', additionalInfo:'expected: two'}",
+			},
+		},
+		{
+			name:           "fixed size changed",
+			previousSchema: `{"type": "fixed", "name": "one", "size": 4}`,
+			schema:         `{"type": "fixed", "name": "one", "size": 8}`,
+			reasons: []string{
+				"{errorType:'FIXED_SIZE_MISMATCH', description:'The size of FIXED type field at path '/size' in the reader schema does not match with the writer schema', additionalInfo:'expected: 8, found: 4'}",
+			},
+		},
+		{
+			name:           "enum symbol added",
+			previousSchema: `{"type": "enum", "name": "one", "symbols": ["A"]}`,
+			schema:         `{"type": "enum", "name": "one", "symbols": ["A", "B", "C"]}`,
+			reasons: []string{
+				"{errorType:'MISSING_ENUM_SYMBOLS', description:'The reader schema is missing enum symbols '[B, C]' at path '/symbols' in the writer schema', additionalInfo:'[B, C]'}",
+			},
+		},
+		{
+			name:           "union branch added",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": ["null", "string"]}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "field1", "type": ["null", "string", "boolean"]}]}`,
+			reasons: []string{
+				"{errorType:'MISSING_UNION_BRANCH', description:'The reader schema is missing a type inside a union field at path '/fields/0/type/2' in the writer schema', additionalInfo:'reader union lacking writer type: BOOLEAN'}",
+			},
+		},
+		{
+			name:           "recursive record",
+			previousSchema: `{"type": "record", "name": "one", "fields": [{"name": "next", "type": ["null", "one"]}]}`,
+			schema:         `{"type": "record", "name": "one", "fields": [{"name": "next", "type": ["null", "one"]}, {"name": "field1", "type": "string", "default": ""}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previousSchema, err := ParseSchema(test.previousSchema, SchemaTypeAvro, nil, nil)
+			assert.NoError(t, err)
+			schema, err := ParseSchema(test.schema, SchemaTypeAvro, nil, nil)
+			assert.NoError(t, err)
+
+			compatible, reasons, err := schema.IsBackwardsCompatible(previousSchema)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.reasons) == 0, compatible)
+			assert.Equal(t, test.reasons, reasons)
+		})
+	}
+}
+
+func TestParsedAvroSchemaNames(t *testing.T) {
+	schema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "Customer",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "customer_email", "type": "string"},
+    {"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [{"name": "street", "type": "string"}]}]},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE"]}},
+    {"name": "referrer", "type": ["null", "Customer"]}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	names := schema.Names()
+	assert.Equal(t, []string{
+		"com.example.Customer",
+		"customer_email",
+		"address",
+		"com.example.Address",
+		"address.street",
+		"status",
+		"com.example.Status",
+		"referrer",
+	}, names)
+
+	assert.True(t, HasName(names, "customer_email"))
+	assert.True(t, HasName(names, "street"))
+	assert.True(t, HasName(names, "address.street"))
+	assert.True(t, HasName(names, "Address"))
+	assert.False(t, HasName(names, "email"))
+}
+
+func TestParsedAvroSchemaDiff(t *testing.T) {
+	previousSchema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "customer",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "int"},
+    {"name": "name", "type": "string", "default": ""},
+    {"name": "phone", "type": "string"},
+    {"name": "status", "type": {"type": "enum", "name": "status", "symbols": ["ACTIVE", "DISABLED"]}},
+    {"name": "addresses", "type": {"type": "array", "items": {"type": "record", "name": "address", "fields": [
+      {"name": "street", "type": "string"}
+    ]}}},
+    {"name": "token", "type": {"type": "fixed", "name": "token", "size": 16}}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	schema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "customer",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": ["null", "string"], "default": null},
+    {"name": "email", "type": "string"},
+    {"name": "status", "type": {"type": "enum", "name": "status", "symbols": ["ACTIVE", "BANNED"]}},
+    {"name": "addresses", "type": {"type": "array", "items": {"type": "record", "name": "address", "fields": [
+      {"name": "street", "type": "string"},
+      {"name": "city", "type": "string", "default": "unknown"}
+    ]}}},
+    {"name": "token", "type": {"type": "fixed", "name": "token", "size": 32}}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	changes, err := schema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeTypeChanged, Path: "id", Previous: "int", Current: "long", Backward: true, Forward: false},
+		{Kind: ChangeDefaultChanged, Path: "name", Previous: `""`, Current: "null", Backward: true, Forward: true},
+		{Kind: ChangeTypeChanged, Path: "name", Previous: "string", Current: "[null, string]", Backward: true, Forward: false},
+		{Kind: ChangeFieldAdded, Path: "email", Current: "string", Backward: false, Forward: true},
+		{Kind: ChangeEnumSymbolAdded, Path: "status", Current: "BANNED", Backward: true, Forward: false},
+		{Kind: ChangeEnumSymbolRemoved, Path: "status", Previous: "DISABLED", Backward: false, Forward: true},
+		{Kind: ChangeFieldAdded, Path: "addresses[].city", Current: "string", Backward: true, Forward: true},
+		{Kind: ChangeConstraintChanged, Path: "token", Constraint: "size", Previous: "16", Current: "32"},
+		{Kind: ChangeFieldRemoved, Path: "phone", Previous: "string", Backward: true, Forward: false},
+	}, changes)
+
+	// nothing changed
+	changes, err = schema.Diff(schema)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// renaming the record can't be read either way
+	renamedSchema, err := ParseSchema(`{"type": "record", "name": "client", "namespace": "com.example", "fields": [{"name": "id", "type": "int"}]}`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+	changes, err = renamedSchema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Contains(t, changes, Change{Kind: ChangeNameChanged, Previous: "com.example.customer", Current: "com.example.client"})
+
+	jsonSchema, err := ParseSchema(`{"type": "string"}`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+	_, err = schema.Diff(jsonSchema)
+	assert.Error(t, err)
+}
+
+func TestParsedAvroSchemaDiffSharedRecord(t *testing.T) {
+	previousSchema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "order",
+  "fields": [
+    {"name": "billing", "type": {"type": "record", "name": "address", "fields": [{"name": "street", "type": "string"}]}},
+    {"name": "shipping", "type": "address"}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	schema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "order",
+  "fields": [
+    {"name": "billing", "type": {"type": "record", "name": "address", "fields": [
+      {"name": "street", "type": "string"},
+      {"name": "city", "type": "string"}
+    ]}},
+    {"name": "shipping", "type": "address"}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	// the record is compared under each field that uses it
+	changes, err := schema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeFieldAdded, Path: "billing.city", Current: "string", Backward: false, Forward: true},
+		{Kind: ChangeFieldAdded, Path: "shipping.city", Current: "string", Backward: false, Forward: true},
+	}, changes)
+
+	recursiveSchema, err := ParseSchema(`
+{
+  "type": "record",
+  "name": "node",
+  "fields": [
+    {"name": "value", "type": "int"},
+    {"name": "next", "type": ["null", "node"], "default": null}
+  ]
+}
+`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	changes, err = recursiveSchema.Diff(recursiveSchema)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestParsedAvroSchemaDiffEnumDefault(t *testing.T) {
+	previousSchema, err := ParseSchema(`{"type": "enum", "name": "status", "symbols": ["UNKNOWN", "ACTIVE", "DISABLED"], "default": "UNKNOWN"}`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	schema, err := ParseSchema(`{"type": "enum", "name": "status", "symbols": ["UNKNOWN", "ACTIVE", "BANNED"], "default": "UNKNOWN"}`, SchemaTypeAvro, nil, nil)
+	assert.NoError(t, err)
+
+	// readers with a default read symbols they don't have as the default
+	changes, err := schema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeEnumSymbolAdded, Path: "", Current: "BANNED", Backward: true, Forward: true},
+		{Kind: ChangeEnumSymbolRemoved, Path: "", Previous: "DISABLED", Backward: true, Forward: true},
+	}, changes)
+}
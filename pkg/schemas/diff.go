@@ -0,0 +1,45 @@
+package schemas
+
+type ChangeKind string
+
+const (
+	ChangeFieldAdded   ChangeKind = "FIELD_ADDED"
+	ChangeFieldRemoved ChangeKind = "FIELD_REMOVED"
+	// ChangeFieldNumberChanged is a protobuf field that kept its name but not its number
+	ChangeFieldNumberChanged ChangeKind = "FIELD_NUMBER_CHANGED"
+	ChangeTypeChanged        ChangeKind = "TYPE_CHANGED"
+	// ChangeTypeAdded and ChangeTypeRemoved are protobuf messages and enums
+	ChangeTypeAdded         ChangeKind = "TYPE_ADDED"
+	ChangeTypeRemoved       ChangeKind = "TYPE_REMOVED"
+	ChangeNameChanged       ChangeKind = "NAME_CHANGED"
+	ChangeDefaultChanged    ChangeKind = "DEFAULT_CHANGED"
+	ChangeEnumSymbolAdded   ChangeKind = "ENUM_SYMBOL_ADDED"
+	ChangeEnumSymbolRemoved ChangeKind = "ENUM_SYMBOL_REMOVED"
+	ChangeConstraintChanged ChangeKind = "CONSTRAINT_CHANGED"
+)
+
+// Change is a single semantic difference between two schemas
+type Change struct {
+	Kind ChangeKind
+	// Path is where the change is, fields are paths of the fields they're in joined by dots the same as Names,
+	// array items end in [] and map values in {}, it's empty for the root of the schema
+	Path string
+	// Constraint is the keyword of a constraint change, i.e. maxLength
+	Constraint string
+	// Previous and Current describe what changed, they're empty when there was nothing before or after
+	Previous string
+	Current  string
+	// Backward is if data written with the previous schema can be read with the current one
+	Backward bool
+	// Forward is if data written with the current schema can be read with the previous one
+	Forward bool
+}
+
+// joinPath adds the name to the dotted path
+func joinPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -1,6 +1,8 @@
 package schemas
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -10,35 +12,61 @@ import (
 )
 
 type ParsedJSONSchema struct {
+	rawSchema  string
 	jsonSchema *jsonschema.Schema
 }
 
-func (s *ParsedJSONSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (bool, error) {
+// CanonicalString returns the schema as compact json with sorted keys
+func (s *ParsedJSONSchema) CanonicalString() (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s.rawSchema)))
+	// keep numbers as they were written
+	decoder.UseNumber()
+
+	var schema interface{}
+	if err := decoder.Decode(&schema); err != nil {
+		return "", fmt.Errorf("error decoding json schema: %w", err)
+	}
+
+	// json.Marshal sorts map keys
+	canonical, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling json schema: %w", err)
+	}
+
+	return string(canonical), nil
+}
+
+func (s *ParsedJSONSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (bool, []string, error) {
 	previousJsonSchema, ok := previousSchema.(*ParsedJSONSchema)
 	if !ok {
-		return false, fmt.Errorf("cannot check compatibility, previous schema isn't json")
+		return false, nil, fmt.Errorf("cannot check compatibility, previous schema isn't json")
 	}
 
 	reader := previousJsonSchema.jsonSchema
 	writer := s.jsonSchema
 
-	return s.isBackwardsCompatible(reader, writer)
+	reasons, err := s.isBackwardsCompatible(reader, writer, "#")
+	if err != nil {
+		return false, nil, err
+	}
+
+	return len(reasons) == 0, reasons, nil
 }
 
 // Following rules here https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/json-schema-provider/src/main/java/io/confluent/kafka/schemaregistry/json/diff/SchemaDiff.java#L118
-func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Schema) (bool, error) {
-	// TODO: eventually we probably want to do something similar as confluent SR where it will return with the issues instead of just true/false
-	//  we don't want to return the recurse just keep adding to set of differences
+// returns the reasons why the writer is not compatible with the reader, no reasons means it is compatible
+func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Schema, path string) ([]string, error) {
+	// TODO: confluent SR keeps going and returns the full set of differences, we stop at the first one
 
 	// both schemas are nil so they are compatible
 	if reader == nil && writer == nil {
-		return true, nil
+		return nil, nil
 	} else if reader == nil {
 		// reader is nil; schema added, not compatible
-		return false, nil
+		return []string{fmt.Sprintf("%s: schema added", path)}, nil
 	} else if writer == nil {
 		// writer is nil; schema removed, compatible
-		return true, nil
+		return nil, nil
 	}
 
 	// normalize schema, if it points to a ref, get the ref instead
@@ -76,30 +104,30 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 	if writerCompareType != readerCompareType {
 		// reader is false schema, compatible
 		if reader.Always != nil && *reader.Always == false {
-			return true, nil
+			return nil, nil
 		}
 
 		// writer is true schema or empty, compatible
 		if (writer.Always != nil && *writer.Always == false) || len(writerCompareType) == 0 {
-			return true, nil
+			return nil, nil
 		}
 
 		// type changed, not compatible
-		return false, nil
+		return []string{fmt.Sprintf("%s: type changed", path)}, nil
 	}
 
 	// https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/json-schema-provider/src/main/java/io/confluent/kafka/schemaregistry/json/diff/EnumSchemaDiff.java#L25
 	writerContainsAllReaderEnums := true
 	readerContainsAllWriterEnums := true
 	for _, item := range writer.Enum {
-		contains := slices.Contains(reader.Enum, item)
+		contains := containsJSONValue(reader.Enum, item)
 		if !contains {
 			readerContainsAllWriterEnums = false
 			break
 		}
 	}
 	for _, item := range reader.Enum {
-		contains := slices.Contains(writer.Enum, item)
+		contains := containsJSONValue(writer.Enum, item)
 		if !contains {
 			writerContainsAllReaderEnums = false
 			break
@@ -109,22 +137,22 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 		// enum array extended, compatible
 	} else if readerContainsAllWriterEnums {
 		// enum array narrowed, not compatible
-		return false, nil
+		return []string{fmt.Sprintf("%s: enum array narrowed", path)}, nil
 	} else {
 		// enum array changed, not compatible
-		return false, nil
+		return []string{fmt.Sprintf("%s: enum array changed", path)}, nil
 	}
 
 	// https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/json-schema-provider/src/main/java/io/confluent/kafka/schemaregistry/json/diff/NotSchemaDiff.java#L24
-	notIsBackwardsCompatible, err := s.isBackwardsCompatible(reader.Not, reader.Not)
+	notReasons, err := s.isBackwardsCompatible(reader.Not, reader.Not, path+"/not")
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if notIsBackwardsCompatible {
+	if len(notReasons) == 0 {
 		// not type compatible; not type narrowed, compatible
 	} else {
 		// not type not compatible; not type extended, not compatible
-		return false, nil
+		return []string{fmt.Sprintf("%s: not type extended", path)}, nil
 	}
 
 	// TODO: allOf, anyOf, oneOf; https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/json-schema-provider/src/main/java/io/confluent/kafka/schemaregistry/json/diff/CombinedSchemaDiff.java#L38
@@ -133,36 +161,38 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 	case "string":
 		if reader.MaxLength == -1 && writer.MaxLength != -1 {
 			// max length added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max length added", path)}, nil
 		} else if reader.MaxLength != -1 && writer.MaxLength == -1 {
 			// max length removed, compatible
 		} else if reader.MaxLength < writer.MaxLength {
 			// max length increased, compatible
 		} else if reader.MaxLength > writer.MaxLength {
 			// max length decreases, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max length decreases", path)}, nil
 		}
 
 		if reader.MinLength == -1 && writer.MinLength != -1 {
 			// min length added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min length added", path)}, nil
 		} else if reader.MinLength != -1 && writer.MinLength == -1 {
 			// min length remove, compatible
 		} else if reader.MinLength < writer.MinLength {
 			// min length increased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min length increased", path)}, nil
 		} else if reader.MinLength > writer.MinLength {
 			// min length decreased, compatible
 		}
 
-		if reader.Pattern == nil && writer.Pattern != nil {
+		if reader.Pattern == nil && writer.Pattern == nil {
+			// pattern not set, compatible
+		} else if reader.Pattern == nil && writer.Pattern != nil {
 			// pattern added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: pattern added", path)}, nil
 		} else if reader.Pattern != nil && writer.Pattern == nil {
 			// pattern remove, compatible
 		} else if reader.Pattern.String() != writer.Pattern.String() {
 			// pattern changed, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: pattern changed", path)}, nil
 		}
 
 		break
@@ -170,96 +200,108 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 		if writerType != readerType {
 			if writerType == "integer" {
 				// writer is integer while reader isn't; type narrowed, not compatible
-				return false, nil
+				return []string{fmt.Sprintf("%s: writer is integer while reader isn't; type narrowed", path)}, nil
 			} else {
 				// writer is number and reader is int; type extended, compatbie
 			}
 		}
 
-		if reader.Maximum == nil && writer.Maximum != nil {
+		if reader.Maximum == nil && writer.Maximum == nil {
+			// maximum not set, compatible
+		} else if reader.Maximum == nil && writer.Maximum != nil {
 			// maximum added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: maximum added", path)}, nil
 		} else if reader.Maximum != nil && writer.Maximum == nil {
 			// maximum removed, compatible
 		} else if reader.Maximum.Cmp(writer.Maximum) == -1 {
 			// maximum increased, compatible
 		} else if reader.Maximum.Cmp(writer.Maximum) == 1 {
 			// maximum decreased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: maximum decreased", path)}, nil
 		}
 
-		if reader.Minimum == nil && writer.Minimum != nil {
+		if reader.Minimum == nil && writer.Minimum == nil {
+			// minimum not set, compatible
+		} else if reader.Minimum == nil && writer.Minimum != nil {
 			// minimum added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: minimum added", path)}, nil
 		} else if reader.Minimum != nil && writer.Minimum == nil {
 			// minimum removed, compatible
 		} else if reader.Minimum.Cmp(writer.Minimum) == -1 {
 			// minimum increased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: minimum increased", path)}, nil
 		} else if reader.Minimum.Cmp(writer.Minimum) == 1 {
 			// minimum decreased, compatible
 		}
 
-		if reader.ExclusiveMaximum == nil && writer.ExclusiveMaximum != nil {
+		if reader.ExclusiveMaximum == nil && writer.ExclusiveMaximum == nil {
+			// exclusive maximum not set, compatible
+		} else if reader.ExclusiveMaximum == nil && writer.ExclusiveMaximum != nil {
 			// exclusive maximum added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: exclusive maximum added", path)}, nil
 		} else if reader.ExclusiveMaximum != nil && writer.ExclusiveMaximum == nil {
 			// exclusive maximum removed, compatible
 		} else if reader.ExclusiveMaximum.Cmp(writer.ExclusiveMaximum) == -1 {
 			// exclusive maximum increased, compatible
 		} else if reader.ExclusiveMaximum.Cmp(writer.ExclusiveMaximum) == 1 {
 			// exclusive maximum decreased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: exclusive maximum decreased", path)}, nil
 		}
 
-		if reader.ExclusiveMinimum == nil && writer.ExclusiveMinimum != nil {
+		if reader.ExclusiveMinimum == nil && writer.ExclusiveMinimum == nil {
+			// exclusive minimum not set, compatible
+		} else if reader.ExclusiveMinimum == nil && writer.ExclusiveMinimum != nil {
 			// exclusive minimum added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: exclusive minimum added", path)}, nil
 		} else if reader.ExclusiveMinimum != nil && writer.ExclusiveMinimum == nil {
 			// exclusive minimum removed, compatible
 		} else if reader.ExclusiveMinimum.Cmp(writer.ExclusiveMinimum) == -1 {
 			// exclusive minimum increased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: exclusive minimum increased", path)}, nil
 		} else if reader.ExclusiveMinimum.Cmp(writer.ExclusiveMinimum) == 1 {
 			// exclusive minimum decreased, compatible
 		}
 
-		if reader.MultipleOf == nil && writer.MultipleOf != nil {
+		if reader.MultipleOf == nil && writer.MultipleOf == nil {
+			// multiple not set, compatible
+		} else if reader.MultipleOf == nil && writer.MultipleOf != nil {
 			// multiple added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: multiple added", path)}, nil
 		} else if reader.MultipleOf != nil && writer.MultipleOf == nil {
 			// multiple removed, compatible
+		} else if reader.MultipleOf.Cmp(writer.MultipleOf) == 0 {
+			// multiple unchanged, compatible
 		} else if new(big.Int).Mod(writer.MultipleOf.Num(), reader.MultipleOf.Num()).Cmp(big.NewInt(0)) == 0 {
 			// multiple expanded, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: multiple expanded", path)}, nil
 		} else if new(big.Int).Mod(reader.MultipleOf.Num(), writer.MultipleOf.Num()).Cmp(big.NewInt(0)) == 0 {
 			// multiple reduced, compatible
 		} else {
 			// multiple changed, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: multiple changed", path)}, nil
 		}
 		break
 	case "object":
 		if reader.MaxProperties == -1 && writer.MaxProperties != -1 {
 			// max properties added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max properties added", path)}, nil
 		} else if reader.MaxProperties != -1 && writer.MaxProperties == -1 {
 			// max properties removed, compatible
 		} else if reader.MaxProperties < writer.MaxProperties {
 			// max properties increased, compatible
 		} else if reader.MaxProperties > writer.MaxProperties {
 			// max properties decreased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max properties decreased", path)}, nil
 		}
 
 		if reader.MinProperties == -1 && writer.MinProperties != -1 {
 			// min properties added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min properties added", path)}, nil
 		} else if reader.MinProperties != -1 && writer.MinProperties == -1 {
 			// min properties removed, compatible
 		} else if reader.MinProperties < writer.MinProperties {
 			// min properties increased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min properties increased", path)}, nil
 		} else if reader.MinProperties > writer.MinProperties {
 			// min properties decreased, compatible
 		}
@@ -283,21 +325,21 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 				// additional properties added, compatible
 			} else {
 				// additional properties removed, not compatible
-				return false, nil
+				return []string{fmt.Sprintf("%s: additional properties removed", path)}, nil
 			}
 		} else if readerAdditionalPropsSchema == nil && writerAdditionalPropsSchema != nil {
 			// additional properties narrowed, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: additional properties narrowed", path)}, nil
 		} else if readerAdditionalPropsSchema != nil && writerAdditionalPropsSchema == nil {
 			// additional properties extended, compatible
 		} else {
-			additionalPropsBackwardCompatible, err := s.isBackwardsCompatible(readerAdditionalPropsSchema, writerAdditionalPropsSchema)
+			additionalPropsReasons, err := s.isBackwardsCompatible(readerAdditionalPropsSchema, writerAdditionalPropsSchema, path+"/additionalProperties")
 			if err != nil {
-				return false, err
+				return nil, err
 			}
-			if !additionalPropsBackwardCompatible {
+			if len(additionalPropsReasons) > 0 {
 				// additional props not compatible, not compatible
-				return false, nil
+				return additionalPropsReasons, nil
 			}
 		}
 
@@ -357,12 +399,12 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 
 				if writerContainsAllReader {
 					// dependency array extended, not compatible
-					return false, nil
+					return []string{fmt.Sprintf("%s: dependency array extended", path)}, nil
 				} else if readerContainsAllWriter {
 					// dependency array narrowed, compatible
 				} else {
 					// dependency array changed, not compatible
-					return false, nil
+					return []string{fmt.Sprintf("%s: dependency array changed", path)}, nil
 				}
 			}
 		}
@@ -373,88 +415,27 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 				// dependency schema removed, compatible
 			} else if readerSchemaDependency == nil {
 				// dependency schema added, not compatible
-				return false, nil
+				return []string{fmt.Sprintf("%s: dependency schema added", path)}, nil
 			} else {
-				compatible, err := s.isBackwardsCompatible(readerSchemaDependency, writerSchemaDependency)
+				dependencyReasons, err := s.isBackwardsCompatible(readerSchemaDependency, writerSchemaDependency, path+"/dependencies/"+key)
 				if err != nil {
-					return false, err
+					return nil, err
 				}
-				if !compatible {
+				if len(dependencyReasons) > 0 {
 					// dependency schema not compatible, not compatible
-					return false, nil
+					return dependencyReasons, nil
 				}
 			}
 		}
 
 		propertyKeys := append(maps.Keys(reader.Properties), maps.Keys(writer.Properties)...)
 		for _, propertyKey := range propertyKeys {
-			readerSchema := reader.Properties[propertyKey]
-			writerSchema := writer.Properties[propertyKey]
-			if writerSchema == nil {
-				if s.isObjectOpenContentModel(writer) {
-					// property removed from open content model, compatible
-				} else {
-					writerPartialSchema := s.schemaFromObjectPartiallyOpenContentModel(writer, propertyKey)
-					if writerPartialSchema != nil {
-						compatible, err := s.isBackwardsCompatible(readerSchema, writerPartialSchema)
-						if err != nil {
-							return false, err
-						}
-						if compatible {
-							// property removed is covered by partially open content model, compatible
-						} else {
-							// property removed is not covered by partially open content model, not compatible
-							return false, nil
-						}
-					} else {
-						if readerSchema.Always != nil && !*readerSchema.Always {
-							// property with false removed from closed content model, compatible
-						} else {
-							// property removed from closed content model, not compatible
-							return false, nil
-						}
-					}
-				}
-			} else if readerSchema == nil {
-				if s.isObjectOpenContentModel(reader) {
-					if len(writerSchema.Types) == 0 {
-						// property with empty schema added to open content model, compatible
-					} else {
-						// property added to open content model, not compatible
-					}
-				} else {
-					readerPartialSchema := s.schemaFromObjectPartiallyOpenContentModel(reader, propertyKey)
-					if readerPartialSchema != nil {
-						compatible, err := s.isBackwardsCompatible(readerPartialSchema, writerSchema)
-						if err != nil {
-							return false, err
-						}
-						if compatible {
-							// property added is covered by partially open content model, compatible
-						} else {
-							// property added not covered by partially open content model, not compatible
-							return false, nil
-						}
-					}
-					if slices.Contains(writer.Required, propertyKey) {
-						if writer.Properties[propertyKey].Default != nil {
-							// required property with default added to unopen content model, compatible
-						} else {
-							// required propetty added to unopen content model
-							return false, nil
-						}
-					} else {
-						// optional property added to unopen content model, compatible
-					}
-				}
-			} else {
-				compatible, err := s.isBackwardsCompatible(readerSchema, writerSchema)
-				if err != nil {
-					return false, err
-				}
-				if !compatible {
-					return false, nil
-				}
+			propertyReasons, err := s.isPropertyBackwardsCompatible(reader, writer, propertyKey, path+"/properties/"+propertyKey)
+			if err != nil {
+				return nil, err
+			}
+			if len(propertyReasons) > 0 {
+				return propertyReasons, nil
 			}
 		}
 
@@ -469,7 +450,7 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 						// required attribute with default added, compatible
 					} else {
 						// required attribute added, not compatible
-						return false, nil
+						return []string{fmt.Sprintf("%s/properties/%s: required attribute added", path, readerPropKey)}, nil
 					}
 				}
 			}
@@ -478,24 +459,24 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 	case "array":
 		if reader.MaxItems == -1 && writer.MaxItems != -1 {
 			// max items added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max items added", path)}, nil
 		} else if reader.MaxItems != -1 && writer.MaxItems == -1 {
 			// max items removed, compatible
 		} else if reader.MaxItems < writer.MaxItems {
 			// max items increased, compatible
 		} else if reader.MaxItems > writer.MaxItems {
 			// max items decreased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: max items decreased", path)}, nil
 		}
 
 		if reader.MinItems == -1 && writer.MinItems != -1 {
 			// min items added, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min items added", path)}, nil
 		} else if reader.MinItems != -1 && writer.MinItems == -1 {
 			// min items removed, compatible
 		} else if reader.MinItems < writer.MinItems {
 			// min items increased, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: min items increased", path)}, nil
 		} else if reader.MinItems > writer.MinItems {
 			// min items decreased, compatible
 		}
@@ -505,7 +486,7 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 				// unique items removed, compatible
 			} else {
 				// unique items added, not compatible
-				return false, nil
+				return []string{fmt.Sprintf("%s: unique items added", path)}, nil
 			}
 		}
 
@@ -526,34 +507,34 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 		if readerPermitsAdditionalItems != writerPermitsAdditionalItems {
 			if readerPermitsAdditionalItems {
 				// additional items removed, not compatible
-				return false, nil
+				return []string{fmt.Sprintf("%s: additional items removed", path)}, nil
 			} else {
 				// additional items added, compatible
 			}
 		} else if readerAdditionalItemsSchema == nil && writerAdditionalItemsSchema != nil {
 			// additional items narrowed, not compatible
-			return false, nil
+			return []string{fmt.Sprintf("%s: additional items narrowed", path)}, nil
 		} else if readerAdditionalItemsSchema != nil && writerAdditionalItemsSchema == nil {
 			// additional items extended, compatible
 		} else {
-			additionalPropsBackwardCompatible, err := s.isBackwardsCompatible(readerAdditionalItemsSchema, writerAdditionalItemsSchema)
+			additionalItemsReasons, err := s.isBackwardsCompatible(readerAdditionalItemsSchema, writerAdditionalItemsSchema, path+"/additionalItems")
 			if err != nil {
-				return false, err
+				return nil, err
 			}
-			if !additionalPropsBackwardCompatible {
+			if len(additionalItemsReasons) > 0 {
 				// additional items not compatible, not compatible
-				return false, nil
+				return additionalItemsReasons, nil
 			}
 		}
 
 		readerItemsSchema, _ := reader.Items.(*jsonschema.Schema)
 		writerItemsSchema, _ := writer.Items.(*jsonschema.Schema)
-		schemasCompatible, err := s.isBackwardsCompatible(readerItemsSchema, writerItemsSchema)
+		itemsReasons, err := s.isBackwardsCompatible(readerItemsSchema, writerItemsSchema, path+"/items")
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-		if !schemasCompatible {
-			return false, nil
+		if len(itemsReasons) > 0 {
+			return itemsReasons, nil
 		}
 
 		// readerItemsSchemaSlice, _ := reader.Items.([]*jsonschema.Schema)
@@ -571,10 +552,85 @@ func (s *ParsedJSONSchema) isBackwardsCompatible(reader, writer *jsonschema.Sche
 		//  it doesn't look like confluent sr compares these so I think so?
 		break
 	default:
-		return false, fmt.Errorf("unknown json schema type: %s", writerType)
+		return nil, fmt.Errorf("unknown json schema type: %s", writerType)
 	}
 
-	return true, nil
+	return nil, nil
+}
+
+// isPropertyBackwardsCompatible returns the reasons why the property of the writer object is not compatible with
+// the property of the reader object, the property can be missing from either of them
+func (s *ParsedJSONSchema) isPropertyBackwardsCompatible(reader, writer *jsonschema.Schema, propertyKey string, propertyPath string) ([]string, error) {
+	readerSchema := reader.Properties[propertyKey]
+	writerSchema := writer.Properties[propertyKey]
+	if writerSchema == nil {
+		if s.isObjectOpenContentModel(writer) {
+			// property removed from open content model, compatible
+		} else {
+			writerPartialSchema := s.schemaFromObjectPartiallyOpenContentModel(writer, propertyKey)
+			if writerPartialSchema != nil {
+				partialReasons, err := s.isBackwardsCompatible(readerSchema, writerPartialSchema, propertyPath)
+				if err != nil {
+					return nil, err
+				}
+				if len(partialReasons) == 0 {
+					// property removed is covered by partially open content model, compatible
+				} else {
+					// property removed is not covered by partially open content model, not compatible
+					return []string{fmt.Sprintf("%s: property removed is not covered by partially open content model", propertyPath)}, nil
+				}
+			} else {
+				if readerSchema.Always != nil && !*readerSchema.Always {
+					// property with false removed from closed content model, compatible
+				} else {
+					// property removed from closed content model, not compatible
+					return []string{fmt.Sprintf("%s: property removed from closed content model", propertyPath)}, nil
+				}
+			}
+		}
+	} else if readerSchema == nil {
+		if s.isObjectOpenContentModel(reader) {
+			if len(writerSchema.Types) == 0 {
+				// property with empty schema added to open content model, compatible
+			} else {
+				// property added to open content model, not compatible
+			}
+		} else {
+			readerPartialSchema := s.schemaFromObjectPartiallyOpenContentModel(reader, propertyKey)
+			if readerPartialSchema != nil {
+				partialReasons, err := s.isBackwardsCompatible(readerPartialSchema, writerSchema, propertyPath)
+				if err != nil {
+					return nil, err
+				}
+				if len(partialReasons) == 0 {
+					// property added is covered by partially open content model, compatible
+				} else {
+					// property added not covered by partially open content model, not compatible
+					return []string{fmt.Sprintf("%s: property added not covered by partially open content model", propertyPath)}, nil
+				}
+			}
+			if slices.Contains(writer.Required, propertyKey) {
+				if writer.Properties[propertyKey].Default != nil {
+					// required property with default added to unopen content model, compatible
+				} else {
+					// required property added to unopen content model, not compatible
+					return []string{fmt.Sprintf("%s: required property added to unopen content model", propertyPath)}, nil
+				}
+			} else {
+				// optional property added to unopen content model, compatible
+			}
+		}
+	} else {
+		propertyReasons, err := s.isBackwardsCompatible(readerSchema, writerSchema, propertyPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(propertyReasons) > 0 {
+			return propertyReasons, nil
+		}
+	}
+
+	return nil, nil
 }
 
 func (s *ParsedJSONSchema) isObjectOpenContentModel(schema *jsonschema.Schema) bool {
@@ -591,6 +647,13 @@ func (s *ParsedJSONSchema) isObjectOpenContentModel(schema *jsonschema.Schema) b
 		permitsAdditionalProps
 }
 
+// containsJSONValue is slices.Contains for decoded json values, which aren't comparable as a type parameter
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	return slices.ContainsFunc(values, func(candidate interface{}) bool {
+		return candidate == value
+	})
+}
+
 func (s *ParsedJSONSchema) schemaFromObjectPartiallyOpenContentModel(schema *jsonschema.Schema, propertyKey string) *jsonschema.Schema {
 	for regex, schema := range schema.PatternProperties {
 		if regex.MatchString(propertyKey) {
@@ -601,3 +664,58 @@ func (s *ParsedJSONSchema) schemaFromObjectPartiallyOpenContentModel(schema *jso
 	additionalPropsSchema, _ := schema.AdditionalProperties.(*jsonschema.Schema)
 	return additionalPropsSchema
 }
+
+func (s *ParsedJSONSchema) Names() []string {
+	names := make([]string, 0)
+	collectJSONNames(s.jsonSchema, "", make(map[*jsonschema.Schema]struct{}), &names)
+
+	return names
+}
+
+// collectJSONNames adds the paths of object properties, seen stops recursive $refs
+func collectJSONNames(schema *jsonschema.Schema, path string, seen map[*jsonschema.Schema]struct{}, names *[]string) {
+	if schema == nil {
+		return
+	}
+
+	if _, ok := seen[schema]; ok {
+		return
+	}
+	seen[schema] = struct{}{}
+	defer delete(seen, schema)
+
+	collectJSONNames(schema.Ref, path, seen, names)
+
+	for _, subSchemas := range [][]*jsonschema.Schema{schema.AllOf, schema.AnyOf, schema.OneOf, schema.PrefixItems} {
+		for _, subSchema := range subSchemas {
+			collectJSONNames(subSchema, path, seen, names)
+		}
+	}
+
+	switch items := schema.Items.(type) {
+	case *jsonschema.Schema:
+		collectJSONNames(items, path, seen, names)
+	case []*jsonschema.Schema:
+		for _, item := range items {
+			collectJSONNames(item, path, seen, names)
+		}
+	}
+	collectJSONNames(schema.Items2020, path, seen, names)
+
+	if additionalProperties, ok := schema.AdditionalProperties.(*jsonschema.Schema); ok {
+		collectJSONNames(additionalProperties, path, seen, names)
+	}
+
+	propertyNames := maps.Keys(schema.Properties)
+	slices.Sort(propertyNames)
+
+	for _, propertyName := range propertyNames {
+		propertyPath := propertyName
+		if len(path) > 0 {
+			propertyPath = path + "." + propertyName
+		}
+
+		*names = append(*names, propertyPath)
+		collectJSONNames(schema.Properties[propertyName], propertyPath, seen, names)
+	}
+}
@@ -0,0 +1,441 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+func (s *ParsedJSONSchema) Diff(previousSchema ParsedSchema) ([]Change, error) {
+	previousJsonSchema, ok := previousSchema.(*ParsedJSONSchema)
+	if !ok {
+		return nil, fmt.Errorf("cannot diff, previous schema isn't json")
+	}
+
+	differ := &jsonDiffer{
+		schema:  s,
+		changes: make([]Change, 0),
+		seen:    make(map[[2]*jsonschema.Schema]struct{}),
+	}
+	if err := differ.diff(previousJsonSchema.jsonSchema, s.jsonSchema, ""); err != nil {
+		return nil, err
+	}
+
+	return differ.changes, nil
+}
+
+// jsonDiffer follows the same rules as the compatibility check, loosening a constraint is backward compatible
+// as everything valid before is still valid and tightening it is forward compatible
+type jsonDiffer struct {
+	schema  *ParsedJSONSchema
+	changes []Change
+	// seen holds the pairs of schemas that were already compared, so recursive $refs stop
+	seen map[[2]*jsonschema.Schema]struct{}
+}
+
+// jsonTypeString describes the types of the schema, true and false schemas and schemas without types allow anything
+func jsonTypeString(schema *jsonschema.Schema) string {
+	if schema.Always != nil {
+		return fmt.Sprint(*schema.Always)
+	}
+
+	if len(schema.Types) == 0 {
+		return "any"
+	}
+
+	return strings.Join(schema.Types, ", ")
+}
+
+// jsonTypesInclude returns if every value of the inner schema's types is allowed by the outer schema's types
+func jsonTypesInclude(outer, inner *jsonschema.Schema) bool {
+	if inner.Always != nil && !*inner.Always {
+		return true
+	}
+	if outer.Always != nil {
+		return *outer.Always
+	}
+	if len(outer.Types) == 0 {
+		return true
+	}
+	if len(inner.Types) == 0 {
+		return false
+	}
+
+	for _, innerType := range inner.Types {
+		if slices.Contains(outer.Types, innerType) {
+			continue
+		}
+		if innerType == "integer" && slices.Contains(outer.Types, "number") {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+func jsonValueString(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
+
+func ratString(value *big.Rat) string {
+	if value == nil {
+		return ""
+	}
+	if value.IsInt() {
+		return value.Num().String()
+	}
+
+	return strings.TrimRight(value.FloatString(10), "0")
+}
+
+// intBound converts an int constraint to a rat, -1 is not set
+func intBound(value int) *big.Rat {
+	if value == -1 {
+		return nil
+	}
+
+	return new(big.Rat).SetInt64(int64(value))
+}
+
+func (d *jsonDiffer) constraintChange(path string, constraint string, previous string, current string, backward bool, forward bool) {
+	d.changes = append(d.changes, Change{
+		Kind:       ChangeConstraintChanged,
+		Path:       path,
+		Constraint: constraint,
+		Previous:   previous,
+		Current:    current,
+		Backward:   backward,
+		Forward:    forward,
+	})
+}
+
+// diffBound adds a change when a minimum or maximum changed, nil is no bound
+func (d *jsonDiffer) diffBound(path string, constraint string, previous, current *big.Rat, upper bool) {
+	if previous == nil && current == nil {
+		return
+	}
+	if previous != nil && current != nil && previous.Cmp(current) == 0 {
+		return
+	}
+
+	loosened := current == nil
+	if previous != nil && current != nil {
+		loosened = current.Cmp(previous) == 1
+		if !upper {
+			loosened = !loosened
+		}
+	}
+
+	d.constraintChange(path, constraint, ratString(previous), ratString(current), loosened, !loosened)
+}
+
+// diffRestriction adds a change when a constraint that can only be matched exactly changed,
+// adding it is forward compatible, removing it is backward compatible and changing it is neither
+func (d *jsonDiffer) diffRestriction(path string, constraint string, previous string, current string) {
+	if previous == current {
+		return
+	}
+
+	d.constraintChange(path, constraint, previous, current, len(current) == 0, len(previous) == 0)
+}
+
+func (d *jsonDiffer) diff(previous, current *jsonschema.Schema, path string) error {
+	// normalize schema, if it points to a ref, get the ref instead
+	if previous.Ref != nil {
+		previous = previous.Ref
+	}
+	if current.Ref != nil {
+		current = current.Ref
+	}
+
+	key := [2]*jsonschema.Schema{previous, current}
+	if _, ok := d.seen[key]; ok {
+		return nil
+	}
+	d.seen[key] = struct{}{}
+
+	if previousType, currentType := jsonTypeString(previous), jsonTypeString(current); previousType != currentType {
+		d.changes = append(d.changes, Change{
+			Kind:     ChangeTypeChanged,
+			Path:     path,
+			Previous: previousType,
+			Current:  currentType,
+			Backward: jsonTypesInclude(current, previous),
+			Forward:  jsonTypesInclude(previous, current),
+		})
+	}
+
+	d.diffEnum(previous, current, path)
+
+	previousConst, currentConst := "", ""
+	if len(previous.Constant) > 0 {
+		previousConst = jsonValueString(previous.Constant[0])
+	}
+	if len(current.Constant) > 0 {
+		currentConst = jsonValueString(current.Constant[0])
+	}
+	d.diffRestriction(path, "const", previousConst, currentConst)
+
+	previousDefault, currentDefault := "", ""
+	if previous.Default != nil {
+		previousDefault = jsonValueString(previous.Default)
+	}
+	if current.Default != nil {
+		currentDefault = jsonValueString(current.Default)
+	}
+	if previousDefault != currentDefault {
+		d.changes = append(d.changes, Change{
+			Kind:     ChangeDefaultChanged,
+			Path:     path,
+			Previous: previousDefault,
+			Current:  currentDefault,
+			Backward: true,
+			Forward:  true,
+		})
+	}
+
+	// strings
+	d.diffBound(path, "minLength", intBound(previous.MinLength), intBound(current.MinLength), false)
+	d.diffBound(path, "maxLength", intBound(previous.MaxLength), intBound(current.MaxLength), true)
+	previousPattern, currentPattern := "", ""
+	if previous.Pattern != nil {
+		previousPattern = previous.Pattern.String()
+	}
+	if current.Pattern != nil {
+		currentPattern = current.Pattern.String()
+	}
+	d.diffRestriction(path, "pattern", previousPattern, currentPattern)
+	d.diffRestriction(path, "format", previous.Format, current.Format)
+
+	// numbers
+	d.diffBound(path, "minimum", previous.Minimum, current.Minimum, false)
+	d.diffBound(path, "exclusiveMinimum", previous.ExclusiveMinimum, current.ExclusiveMinimum, false)
+	d.diffBound(path, "maximum", previous.Maximum, current.Maximum, true)
+	d.diffBound(path, "exclusiveMaximum", previous.ExclusiveMaximum, current.ExclusiveMaximum, true)
+	d.diffMultipleOf(previous, current, path)
+
+	// arrays
+	d.diffBound(path, "minItems", intBound(previous.MinItems), intBound(current.MinItems), false)
+	d.diffBound(path, "maxItems", intBound(previous.MaxItems), intBound(current.MaxItems), true)
+	if previous.UniqueItems != current.UniqueItems {
+		d.constraintChange(path, "uniqueItems", fmt.Sprint(previous.UniqueItems), fmt.Sprint(current.UniqueItems), !current.UniqueItems, !previous.UniqueItems)
+	}
+	if err := d.diffItems(previous, current, path); err != nil {
+		return err
+	}
+
+	// objects
+	d.diffBound(path, "minProperties", intBound(previous.MinProperties), intBound(current.MinProperties), false)
+	d.diffBound(path, "maxProperties", intBound(previous.MaxProperties), intBound(current.MaxProperties), true)
+	if err := d.diffAdditionalProperties(previous, current, path); err != nil {
+		return err
+	}
+	if err := d.diffProperties(previous, current, path); err != nil {
+		return err
+	}
+
+	// combined schemas are compared in order when there are as many of them
+	for _, combined := range []struct {
+		keyword  string
+		previous []*jsonschema.Schema
+		current  []*jsonschema.Schema
+		// widens is if more schemas allow more values
+		widens bool
+	}{
+		{keyword: "allOf", previous: previous.AllOf, current: current.AllOf, widens: false},
+		{keyword: "anyOf", previous: previous.AnyOf, current: current.AnyOf, widens: true},
+		{keyword: "oneOf", previous: previous.OneOf, current: current.OneOf, widens: true},
+	} {
+		if len(combined.previous) != len(combined.current) {
+			widened := (len(combined.current) > len(combined.previous)) == combined.widens
+			d.constraintChange(path, combined.keyword, fmt.Sprint(len(combined.previous)), fmt.Sprint(len(combined.current)), widened, !widened)
+			continue
+		}
+
+		for index := range combined.previous {
+			if err := d.diff(combined.previous[index], combined.current[index], path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// diffEnum reports each added and removed value, or the enum itself when it was added or removed
+func (d *jsonDiffer) diffEnum(previous, current *jsonschema.Schema, path string) {
+	if len(previous.Enum) == 0 || len(current.Enum) == 0 {
+		previousEnum, currentEnum := "", ""
+		if len(previous.Enum) > 0 {
+			previousEnum = jsonValueString(previous.Enum)
+		}
+		if len(current.Enum) > 0 {
+			currentEnum = jsonValueString(current.Enum)
+		}
+		d.diffRestriction(path, "enum", previousEnum, currentEnum)
+		return
+	}
+
+	for _, value := range current.Enum {
+		if !containsJSONValue(previous.Enum, value) {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeEnumSymbolAdded,
+				Path:     path,
+				Current:  jsonValueString(value),
+				Backward: true,
+			})
+		}
+	}
+
+	for _, value := range previous.Enum {
+		if !containsJSONValue(current.Enum, value) {
+			d.changes = append(d.changes, Change{
+				Kind:     ChangeEnumSymbolRemoved,
+				Path:     path,
+				Previous: jsonValueString(value),
+				Forward:  true,
+			})
+		}
+	}
+}
+
+// diffMultipleOf adds a change when multipleOf changed, a value is still valid when the new multiple divides the old one
+func (d *jsonDiffer) diffMultipleOf(previous, current *jsonschema.Schema, path string) {
+	if previous.MultipleOf == nil && current.MultipleOf == nil {
+		return
+	}
+	if previous.MultipleOf != nil && current.MultipleOf != nil && previous.MultipleOf.Cmp(current.MultipleOf) == 0 {
+		return
+	}
+
+	divides := func(divisor, value *big.Rat) bool {
+		if divisor == nil {
+			return true
+		}
+		if value == nil {
+			return false
+		}
+		return new(big.Rat).Quo(value, divisor).IsInt()
+	}
+
+	d.constraintChange(path, "multipleOf", ratString(previous.MultipleOf), ratString(current.MultipleOf),
+		divides(current.MultipleOf, previous.MultipleOf), divides(previous.MultipleOf, current.MultipleOf))
+}
+
+func (d *jsonDiffer) diffItems(previous, current *jsonschema.Schema, path string) error {
+	previousItems, _ := previous.Items.(*jsonschema.Schema)
+	currentItems, _ := current.Items.(*jsonschema.Schema)
+
+	if previousItems != nil && currentItems != nil {
+		return d.diff(previousItems, currentItems, path+"[]")
+	}
+
+	previousItemsType, currentItemsType := "", ""
+	if previousItems != nil {
+		previousItemsType = jsonTypeString(previousItems)
+	}
+	if currentItems != nil {
+		currentItemsType = jsonTypeString(currentItems)
+	}
+	d.diffRestriction(path, "items", previousItemsType, currentItemsType)
+
+	return nil
+}
+
+// jsonAdditionalPropertiesLevel orders how much additional properties allow, not set is the same as true
+func jsonAdditionalPropertiesLevel(additionalProperties interface{}) (int, string) {
+	switch v := additionalProperties.(type) {
+	case bool:
+		if !v {
+			return 0, "false"
+		}
+	case *jsonschema.Schema:
+		return 1, "schema"
+	}
+
+	return 2, "true"
+}
+
+func (d *jsonDiffer) diffAdditionalProperties(previous, current *jsonschema.Schema, path string) error {
+	previousSchema, previousIsSchema := previous.AdditionalProperties.(*jsonschema.Schema)
+	currentSchema, currentIsSchema := current.AdditionalProperties.(*jsonschema.Schema)
+	if previousIsSchema && currentIsSchema {
+		return d.diff(previousSchema, currentSchema, path+"{}")
+	}
+
+	previousLevel, previousString := jsonAdditionalPropertiesLevel(previous.AdditionalProperties)
+	currentLevel, currentString := jsonAdditionalPropertiesLevel(current.AdditionalProperties)
+	if previousLevel != currentLevel {
+		d.constraintChange(path, "additionalProperties", previousString, currentString, currentLevel > previousLevel, previousLevel > currentLevel)
+	}
+
+	return nil
+}
+
+// diffProperties uses the property rules of the compatibility check for added and removed properties
+func (d *jsonDiffer) diffProperties(previous, current *jsonschema.Schema, path string) error {
+	propertyKeys := maps.Keys(previous.Properties)
+	for propertyKey := range current.Properties {
+		if _, ok := previous.Properties[propertyKey]; !ok {
+			propertyKeys = append(propertyKeys, propertyKey)
+		}
+	}
+	slices.Sort(propertyKeys)
+
+	for _, propertyKey := range propertyKeys {
+		propertyPath := joinPath(path, propertyKey)
+		previousProperty := previous.Properties[propertyKey]
+		currentProperty := current.Properties[propertyKey]
+
+		if previousProperty == nil || currentProperty == nil {
+			backwardReasons, err := d.schema.isPropertyBackwardsCompatible(previous, current, propertyKey, propertyPath)
+			if err != nil {
+				return err
+			}
+			forwardReasons, err := d.schema.isPropertyBackwardsCompatible(current, previous, propertyKey, propertyPath)
+			if err != nil {
+				return err
+			}
+
+			change := Change{
+				Kind:     ChangeFieldAdded,
+				Path:     propertyPath,
+				Backward: len(backwardReasons) == 0,
+				Forward:  len(forwardReasons) == 0,
+			}
+			if currentProperty != nil {
+				change.Current = jsonTypeString(currentProperty)
+			} else {
+				change.Kind = ChangeFieldRemoved
+				change.Previous = jsonTypeString(previousProperty)
+			}
+			d.changes = append(d.changes, change)
+			continue
+		}
+
+		// a property that becomes required can only read old data when it has a default
+		previousRequired := slices.Contains(previous.Required, propertyKey)
+		currentRequired := slices.Contains(current.Required, propertyKey)
+		if previousRequired != currentRequired {
+			d.constraintChange(propertyPath, "required", fmt.Sprint(previousRequired), fmt.Sprint(currentRequired),
+				!currentRequired || currentProperty.Default != nil, !previousRequired || previousProperty.Default != nil)
+		}
+
+		if err := d.diff(previousProperty, currentProperty, propertyPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1 +1,214 @@
 package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsedJSONSchemaIsBackwardsCompatible(t *testing.T) {
+	tests := []struct {
+		name           string
+		previousSchema string
+		schema         string
+		compatible     bool
+	}{
+		{
+			name:           "same string",
+			previousSchema: `{"type": "string"}`,
+			schema:         `{"type": "string"}`,
+			compatible:     true,
+		},
+		{
+			name:           "same pattern",
+			previousSchema: `{"type": "string", "pattern": "^a"}`,
+			schema:         `{"type": "string", "pattern": "^a"}`,
+			compatible:     true,
+		},
+		{
+			name:           "pattern added",
+			previousSchema: `{"type": "string"}`,
+			schema:         `{"type": "string", "pattern": "^a"}`,
+			compatible:     false,
+		},
+		{
+			name:           "same number",
+			previousSchema: `{"type": "number"}`,
+			schema:         `{"type": "number"}`,
+			compatible:     true,
+		},
+		{
+			name:           "same number constraints",
+			previousSchema: `{"type": "number", "maximum": 10, "minimum": 1, "exclusiveMaximum": 11, "exclusiveMinimum": 0, "multipleOf": 2}`,
+			schema:         `{"type": "number", "maximum": 10, "minimum": 1, "exclusiveMaximum": 11, "exclusiveMinimum": 0, "multipleOf": 2}`,
+			compatible:     true,
+		},
+		{
+			name:           "maximum decreased",
+			previousSchema: `{"type": "number", "maximum": 10}`,
+			schema:         `{"type": "number", "maximum": 5}`,
+			compatible:     false,
+		},
+		{
+			name:           "type changed",
+			previousSchema: `{"type": "string"}`,
+			schema:         `{"type": "boolean"}`,
+			compatible:     false,
+		},
+		{
+			name:           "optional property added",
+			previousSchema: `{"type": "object", "properties": {"field1": {"type": "integer"}}}`,
+			schema:         `{"type": "object", "properties": {"field1": {"type": "integer"}, "field2": {"type": "string"}}}`,
+			compatible:     true,
+		},
+		{
+			name:           "property removed",
+			previousSchema: `{"type": "object", "properties": {"field1": {"type": "integer"}, "field2": {"type": "string"}}}`,
+			schema:         `{"type": "object", "properties": {"field1": {"type": "integer"}}}`,
+			compatible:     false,
+		},
+		{
+			name:           "array items changed",
+			previousSchema: `{"type": "array", "items": {"type": "string"}}`,
+			schema:         `{"type": "array", "items": {"type": "boolean"}}`,
+			compatible:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previousSchema, err := ParseSchema(test.previousSchema, SchemaTypeJSON, nil, nil)
+			assert.NoError(t, err)
+			schema, err := ParseSchema(test.schema, SchemaTypeJSON, nil, nil)
+			assert.NoError(t, err)
+
+			compatible, reasons, err := schema.IsBackwardsCompatible(previousSchema)
+			assert.NoError(t, err)
+			assert.Equal(t, test.compatible, compatible)
+			if test.compatible {
+				assert.Empty(t, reasons)
+			} else {
+				assert.NotEmpty(t, reasons)
+			}
+		})
+	}
+}
+
+func TestParsedJSONSchemaIsBackwardsCompatibleReasons(t *testing.T) {
+	previousSchema, err := ParseSchema(`{"type": "object", "properties": {"field1": {"type": "string"}}}`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+	schema, err := ParseSchema(`{"type": "object", "properties": {"field1": {"type": "string", "maxLength": 5}}}`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+
+	compatible, reasons, err := schema.IsBackwardsCompatible(previousSchema)
+	assert.NoError(t, err)
+	assert.False(t, compatible)
+	assert.Equal(t, []string{"#/properties/field1: max length added"}, reasons)
+}
+
+func TestParsedJSONSchemaCanonicalString(t *testing.T) {
+	schema, err := ParseSchema(`
+{
+  "type": "object",
+  "properties": {
+    "b": {"type": "number", "maximum": 1.50},
+    "a": {"type": "string"}
+  }
+}
+`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+
+	canonical, err := schema.CanonicalString()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"properties":{"a":{"type":"string"},"b":{"maximum":1.50,"type":"number"}},"type":"object"}`, canonical)
+}
+
+func TestParsedJSONSchemaNames(t *testing.T) {
+	schema, err := ParseSchema(`
+{
+  "type": "object",
+  "properties": {
+    "customer": {
+      "type": "object",
+      "properties": {
+        "email": {"type": "string"},
+        "addresses": {"type": "array", "items": {"$ref": "#/definitions/address"}}
+      }
+    }
+  },
+  "definitions": {
+    "address": {"type": "object", "properties": {"street": {"type": "string"}}}
+  }
+}
+`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+
+	names := schema.Names()
+	assert.Equal(t, []string{"customer", "customer.addresses", "customer.addresses.street", "customer.email"}, names)
+	assert.True(t, HasName(names, "customer.email"))
+	assert.True(t, HasName(names, "street"))
+	assert.False(t, HasName(names, "address"))
+}
+
+func TestParsedJSONSchemaDiff(t *testing.T) {
+	previousSchema, err := ParseSchema(`
+{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "maxLength": 10},
+    "age": {"type": "integer", "minimum": 0},
+    "color": {"enum": ["red", "green"]},
+    "nick": {"type": "string"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["name"]
+}
+`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+
+	schema, err := ParseSchema(`
+{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "name": {"type": "string", "maxLength": 20, "pattern": "^[a-z]+$"},
+    "age": {"type": "number", "minimum": 0},
+    "color": {"enum": ["red", "blue"]},
+    "email": {"type": "string"},
+    "tags": {"type": "array", "items": {"type": "string", "minLength": 1}}
+  },
+  "required": ["name", "email"]
+}
+`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+
+	changes, err := schema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeTypeChanged, Path: "age", Previous: "integer", Current: "number", Backward: true, Forward: false},
+		{Kind: ChangeEnumSymbolAdded, Path: "color", Current: `"blue"`, Backward: true, Forward: false},
+		{Kind: ChangeEnumSymbolRemoved, Path: "color", Previous: `"green"`, Backward: false, Forward: true},
+		{Kind: ChangeFieldAdded, Path: "email", Current: "string", Backward: false, Forward: false},
+		{Kind: ChangeConstraintChanged, Path: "name", Constraint: "maxLength", Previous: "10", Current: "20", Backward: true, Forward: false},
+		{Kind: ChangeConstraintChanged, Path: "name", Constraint: "pattern", Previous: "", Current: "^[a-z]+$", Backward: false, Forward: true},
+		{Kind: ChangeFieldRemoved, Path: "nick", Previous: "string", Backward: false, Forward: true},
+		{Kind: ChangeConstraintChanged, Path: "tags[]", Constraint: "minLength", Previous: "", Current: "1", Backward: false, Forward: true},
+	}, changes)
+
+	// nothing changed
+	changes, err = schema.Diff(schema)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// making a property required can only read old data when it has a default
+	optionalSchema, err := ParseSchema(`{"type": "object", "properties": {"a": {"type": "string"}}}`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+	requiredSchema, err := ParseSchema(`{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`, SchemaTypeJSON, nil, nil)
+	assert.NoError(t, err)
+	changes, err = requiredSchema.Diff(optionalSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeConstraintChanged, Path: "a", Constraint: "required", Previous: "false", Current: "true", Backward: false, Forward: true},
+	}, changes)
+}
@@ -0,0 +1,277 @@
+package schemas
+
+import (
+	"fmt"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type ParsedProtobufSchema struct {
+	fileDescriptor protoreflect.FileDescriptor
+}
+
+// CanonicalString returns the schema printed from its descriptor without comments and with consistent formatting
+func (s *ParsedProtobufSchema) CanonicalString() (string, error) {
+	fileDescriptor, err := desc.WrapFile(s.fileDescriptor)
+	if err != nil {
+		return "", fmt.Errorf("error wrapping protobuf file descriptor: %w", err)
+	}
+
+	printer := &protoprint.Printer{
+		OmitComments: protoprint.CommentsAll,
+		Compact:      true,
+	}
+
+	canonical, err := printer.PrintProtoToString(fileDescriptor)
+	if err != nil {
+		return "", fmt.Errorf("error printing protobuf schema: %w", err)
+	}
+
+	return canonical, nil
+}
+
+// Following rules here https://github.com/confluentinc/schema-registry/blob/9ef76b4a1373f50a505162e72cffcbfd3dd2fee3/protobuf-provider/src/main/java/io/confluent/kafka/schemaregistry/protobuf/diff/SchemaDiff.java#L36
+func (s *ParsedProtobufSchema) IsBackwardsCompatible(previousSchema ParsedSchema) (bool, []string, error) {
+	previousProtobufSchema, ok := previousSchema.(*ParsedProtobufSchema)
+	if !ok {
+		return false, nil, fmt.Errorf("cannot check compatibility, previous schema isn't protobuf")
+	}
+
+	reasons := s.compareFiles(previousProtobufSchema.fileDescriptor, s.fileDescriptor)
+
+	return len(reasons) == 0, reasons, nil
+}
+
+// returns the reasons why the update is not compatible with the original, no reasons means it is compatible
+func (s *ParsedProtobufSchema) compareFiles(original, update protoreflect.FileDescriptor) []string {
+	reasons := make([]string, 0)
+
+	if original.Package() != update.Package() {
+		// package changed, not compatible
+		reasons = append(reasons, fmt.Sprintf("package changed from %s to %s", original.Package(), update.Package()))
+	}
+
+	originalMessages := make(map[protoreflect.FullName]protoreflect.MessageDescriptor)
+	s.collectMessages(original.Messages(), originalMessages)
+	updateMessages := make(map[protoreflect.FullName]protoreflect.MessageDescriptor)
+	s.collectMessages(update.Messages(), updateMessages)
+
+	for fullName, originalMessage := range originalMessages {
+		updateMessage, ok := updateMessages[fullName]
+		if !ok {
+			// message removed, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: message removed", fullName))
+			continue
+		}
+
+		reasons = append(reasons, s.compareMessages(originalMessage, updateMessage)...)
+	}
+
+	// message added, compatible
+	// enums are not checked as adding, removing and changing enums or their constants are all compatible
+
+	return reasons
+}
+
+func (s *ParsedProtobufSchema) collectMessages(messages protoreflect.MessageDescriptors, collected map[protoreflect.FullName]protoreflect.MessageDescriptor) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		collected[message.FullName()] = message
+		s.collectMessages(message.Messages(), collected)
+	}
+}
+
+func (s *ParsedProtobufSchema) compareMessages(original, update protoreflect.MessageDescriptor) []string {
+	reasons := make([]string, 0)
+
+	originalFields := original.Fields()
+	updateFields := update.Fields()
+
+	for i := 0; i < originalFields.Len(); i++ {
+		originalField := originalFields.Get(i)
+		updateField := updateFields.ByNumber(originalField.Number())
+
+		if updateField == nil {
+			if renamedField := updateFields.ByName(originalField.Name()); renamedField != nil {
+				// field number changed, not compatible
+				reasons = append(reasons, fmt.Sprintf("%s: field number changed from %d to %d", originalField.FullName(), originalField.Number(), renamedField.Number()))
+			} else if originalField.ContainingOneof() != nil && !originalField.ContainingOneof().IsSynthetic() {
+				// oneof field removed, not compatible
+				reasons = append(reasons, fmt.Sprintf("%s: oneof field removed", originalField.FullName()))
+			} else if originalField.Cardinality() == protoreflect.Required {
+				// required field removed, not compatible
+				reasons = append(reasons, fmt.Sprintf("%s: required field removed", originalField.FullName()))
+			} else {
+				// field removed, compatible
+			}
+			continue
+		}
+
+		reasons = append(reasons, s.compareFields(originalField, updateField)...)
+	}
+
+	for i := 0; i < updateFields.Len(); i++ {
+		updateField := updateFields.Get(i)
+		if originalFields.ByNumber(updateField.Number()) != nil {
+			continue
+		}
+
+		if updateField.Cardinality() == protoreflect.Required {
+			// required field added, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: required field added", updateField.FullName()))
+		} else {
+			// field added, compatible
+		}
+	}
+
+	updateOneofs := update.Oneofs()
+	for i := 0; i < updateOneofs.Len(); i++ {
+		updateOneof := updateOneofs.Get(i)
+		if updateOneof.IsSynthetic() {
+			continue
+		}
+
+		// fields that existed before but were not in a oneof
+		movedFields := 0
+		oneofFields := updateOneof.Fields()
+		for j := 0; j < oneofFields.Len(); j++ {
+			originalField := originalFields.ByNumber(oneofFields.Get(j).Number())
+			if originalField == nil {
+				// oneof field added, compatible
+				continue
+			}
+
+			if originalField.ContainingOneof() == nil || originalField.ContainingOneof().IsSynthetic() {
+				movedFields++
+			}
+		}
+
+		originalOneof := original.Oneofs().ByName(updateOneof.Name())
+		if originalOneof != nil && !originalOneof.IsSynthetic() {
+			if movedFields > 0 {
+				// field moved to existing oneof, not compatible
+				reasons = append(reasons, fmt.Sprintf("%s: field moved to existing oneof", updateOneof.FullName()))
+			}
+		} else if movedFields > 1 {
+			// multiple fields moved to new oneof, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: multiple fields moved to new oneof", updateOneof.FullName()))
+		} else {
+			// oneof added or single field moved to new oneof, compatible
+		}
+	}
+
+	return reasons
+}
+
+func (s *ParsedProtobufSchema) compareFields(original, update protoreflect.FieldDescriptor) []string {
+	reasons := make([]string, 0)
+
+	originalKind := s.fieldKind(original)
+	updateKind := s.fieldKind(update)
+
+	if originalKind != updateKind {
+		// field kind changed, not compatible
+		reasons = append(reasons, fmt.Sprintf("%s: field kind changed from %s to %s", original.FullName(), originalKind, updateKind))
+		return reasons
+	}
+
+	switch originalKind {
+	case "scalar":
+		if s.scalarKindGroup(original.Kind()) != s.scalarKindGroup(update.Kind()) {
+			// field scalar kind changed to one with a different wire encoding, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: field scalar kind changed from %s to %s", original.FullName(), original.Kind(), update.Kind()))
+		}
+	case "message":
+		if original.Message().FullName() != update.Message().FullName() {
+			// field named type changed, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: field named type changed from %s to %s", original.FullName(), original.Message().FullName(), update.Message().FullName()))
+		}
+	case "enum":
+		if original.Enum().FullName() != update.Enum().FullName() {
+			// field named type changed, not compatible
+			reasons = append(reasons, fmt.Sprintf("%s: field named type changed from %s to %s", original.FullName(), original.Enum().FullName(), update.Enum().FullName()))
+		}
+	}
+
+	if original.IsList() != update.IsList() && !s.isPackableKind(original.Kind()) {
+		// only numeric types can switch between repeated and singular on the wire
+		// non-numeric field label changed, not compatible
+		reasons = append(reasons, fmt.Sprintf("%s: field label changed", original.FullName()))
+	}
+
+	return reasons
+}
+
+func (s *ParsedProtobufSchema) fieldKind(field protoreflect.FieldDescriptor) string {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "message"
+	case protoreflect.EnumKind:
+		return "enum"
+	default:
+		return "scalar"
+	}
+}
+
+// scalarKindGroup returns the group of scalar kinds that share a wire encoding, a field can change between kinds
+// of the same group the same as confluent sr allows
+func (s *ParsedProtobufSchema) scalarKindGroup(kind protoreflect.Kind) string {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Uint32Kind, protoreflect.Int64Kind, protoreflect.Uint64Kind, protoreflect.BoolKind:
+		return "varint"
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return "zigzag"
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		return "fixed32"
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return "fixed64"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "length-delimited"
+	default:
+		return kind.String()
+	}
+}
+
+func (s *ParsedProtobufSchema) isPackableKind(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *ParsedProtobufSchema) Names() []string {
+	names := make([]string, 0)
+
+	enums := s.fileDescriptor.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		names = append(names, string(enums.Get(i).FullName()))
+	}
+
+	s.collectNames(s.fileDescriptor.Messages(), &names)
+
+	return names
+}
+
+// collectNames adds the full names of the messages, their fields and enums
+func (s *ParsedProtobufSchema) collectNames(messages protoreflect.MessageDescriptors, names *[]string) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		*names = append(*names, string(message.FullName()))
+
+		fields := message.Fields()
+		for j := 0; j < fields.Len(); j++ {
+			*names = append(*names, string(fields.Get(j).FullName()))
+		}
+
+		enums := message.Enums()
+		for j := 0; j < enums.Len(); j++ {
+			*names = append(*names, string(enums.Get(j).FullName()))
+		}
+
+		s.collectNames(message.Messages(), names)
+	}
+}
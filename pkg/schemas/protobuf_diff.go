@@ -0,0 +1,258 @@
+package schemas
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Diff follows the same rules as the compatibility check, fields are matched by their number as that's
+// what's on the wire, so renaming a field is compatible
+func (s *ParsedProtobufSchema) Diff(previousSchema ParsedSchema) ([]Change, error) {
+	previousProtobufSchema, ok := previousSchema.(*ParsedProtobufSchema)
+	if !ok {
+		return nil, fmt.Errorf("cannot diff, previous schema isn't protobuf")
+	}
+
+	original := previousProtobufSchema.fileDescriptor
+	update := s.fileDescriptor
+	changes := make([]Change, 0)
+
+	if original.Package() != update.Package() {
+		changes = append(changes, Change{
+			Kind:     ChangeNameChanged,
+			Previous: string(original.Package()),
+			Current:  string(update.Package()),
+		})
+	}
+
+	originalMessages := make(map[protoreflect.FullName]protoreflect.MessageDescriptor)
+	s.collectMessages(original.Messages(), originalMessages)
+	updateMessages := make(map[protoreflect.FullName]protoreflect.MessageDescriptor)
+	s.collectMessages(update.Messages(), updateMessages)
+
+	// a message being removed is only as compatible as the compatibility check allows
+	for _, fullName := range sortedFullNames(originalMessages, updateMessages) {
+		originalMessage, inOriginal := originalMessages[fullName]
+		updateMessage, inUpdate := updateMessages[fullName]
+
+		switch {
+		case !inUpdate:
+			changes = append(changes, Change{Kind: ChangeTypeRemoved, Path: string(fullName), Previous: "message", Forward: true})
+		case !inOriginal:
+			changes = append(changes, Change{Kind: ChangeTypeAdded, Path: string(fullName), Current: "message", Backward: true})
+		default:
+			changes = append(changes, s.diffMessages(originalMessage, updateMessage)...)
+		}
+	}
+
+	// adding, removing and changing enums or their constants are all compatible
+	originalEnums := make(map[protoreflect.FullName]protoreflect.EnumDescriptor)
+	collectEnums(original.Enums(), original.Messages(), originalEnums)
+	updateEnums := make(map[protoreflect.FullName]protoreflect.EnumDescriptor)
+	collectEnums(update.Enums(), update.Messages(), updateEnums)
+
+	for _, fullName := range sortedFullNames(originalEnums, updateEnums) {
+		originalEnum, inOriginal := originalEnums[fullName]
+		updateEnum, inUpdate := updateEnums[fullName]
+
+		switch {
+		case !inUpdate:
+			changes = append(changes, Change{Kind: ChangeTypeRemoved, Path: string(fullName), Previous: "enum", Backward: true, Forward: true})
+		case !inOriginal:
+			changes = append(changes, Change{Kind: ChangeTypeAdded, Path: string(fullName), Current: "enum", Backward: true, Forward: true})
+		default:
+			changes = append(changes, diffEnumValues(originalEnum, updateEnum)...)
+		}
+	}
+
+	return changes, nil
+}
+
+func sortedFullNames[V any](original, update map[protoreflect.FullName]V) []protoreflect.FullName {
+	fullNames := maps.Keys(original)
+	for fullName := range update {
+		if _, ok := original[fullName]; !ok {
+			fullNames = append(fullNames, fullName)
+		}
+	}
+	slices.Sort(fullNames)
+
+	return fullNames
+}
+
+func collectEnums(enums protoreflect.EnumDescriptors, messages protoreflect.MessageDescriptors, collected map[protoreflect.FullName]protoreflect.EnumDescriptor) {
+	for i := 0; i < enums.Len(); i++ {
+		collected[enums.Get(i).FullName()] = enums.Get(i)
+	}
+
+	for i := 0; i < messages.Len(); i++ {
+		collectEnums(messages.Get(i).Enums(), messages.Get(i).Messages(), collected)
+	}
+}
+
+func diffEnumValues(original, update protoreflect.EnumDescriptor) []Change {
+	changes := make([]Change, 0)
+
+	for i := 0; i < update.Values().Len(); i++ {
+		value := update.Values().Get(i)
+		if original.Values().ByName(value.Name()) == nil {
+			changes = append(changes, Change{Kind: ChangeEnumSymbolAdded, Path: string(update.FullName()), Current: string(value.Name()), Backward: true, Forward: true})
+		}
+	}
+
+	for i := 0; i < original.Values().Len(); i++ {
+		value := original.Values().Get(i)
+		if update.Values().ByName(value.Name()) == nil {
+			changes = append(changes, Change{Kind: ChangeEnumSymbolRemoved, Path: string(original.FullName()), Previous: string(value.Name()), Backward: true, Forward: true})
+		}
+	}
+
+	return changes
+}
+
+// fieldType describes the type of the field without its label
+func (s *ParsedProtobufSchema) fieldType(field protoreflect.FieldDescriptor) string {
+	if field.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", s.fieldType(field.MapKey()), s.fieldType(field.MapValue()))
+	}
+
+	switch s.fieldKind(field) {
+	case "message":
+		return string(field.Message().FullName())
+	case "enum":
+		return string(field.Enum().FullName())
+	}
+
+	return field.Kind().String()
+}
+
+func (s *ParsedProtobufSchema) isInOneof(field protoreflect.FieldDescriptor) bool {
+	return field.ContainingOneof() != nil && !field.ContainingOneof().IsSynthetic()
+}
+
+func (s *ParsedProtobufSchema) diffMessages(original, update protoreflect.MessageDescriptor) []Change {
+	changes := make([]Change, 0)
+
+	originalFields := original.Fields()
+	updateFields := update.Fields()
+
+	for i := 0; i < originalFields.Len(); i++ {
+		originalField := originalFields.Get(i)
+		updateField := updateFields.ByNumber(originalField.Number())
+
+		if updateField == nil {
+			if renumberedField := updateFields.ByName(originalField.Name()); renumberedField != nil {
+				changes = append(changes, Change{
+					Kind:     ChangeFieldNumberChanged,
+					Path:     string(renumberedField.FullName()),
+					Previous: fmt.Sprint(originalField.Number()),
+					Current:  fmt.Sprint(renumberedField.Number()),
+				})
+				continue
+			}
+
+			changes = append(changes, Change{
+				Kind:     ChangeFieldRemoved,
+				Path:     string(originalField.FullName()),
+				Previous: s.fieldType(originalField),
+				Backward: !s.isInOneof(originalField) && originalField.Cardinality() != protoreflect.Required,
+				Forward:  originalField.Cardinality() != protoreflect.Required,
+			})
+			continue
+		}
+
+		changes = append(changes, s.diffFields(originalField, updateField)...)
+	}
+
+	for i := 0; i < updateFields.Len(); i++ {
+		updateField := updateFields.Get(i)
+		if originalFields.ByNumber(updateField.Number()) != nil {
+			continue
+		}
+		if originalField := originalFields.ByName(updateField.Name()); originalField != nil {
+			// the number change was already added
+			continue
+		}
+
+		changes = append(changes, Change{
+			Kind:     ChangeFieldAdded,
+			Path:     string(updateField.FullName()),
+			Current:  s.fieldType(updateField),
+			Backward: updateField.Cardinality() != protoreflect.Required,
+			Forward:  !s.isInOneof(updateField) && updateField.Cardinality() != protoreflect.Required,
+		})
+	}
+
+	return changes
+}
+
+func (s *ParsedProtobufSchema) diffFields(original, update protoreflect.FieldDescriptor) []Change {
+	changes := make([]Change, 0)
+	path := string(update.FullName())
+
+	if original.Name() != update.Name() {
+		changes = append(changes, Change{
+			Kind:     ChangeNameChanged,
+			Path:     path,
+			Previous: string(original.Name()),
+			Current:  string(update.Name()),
+			Backward: true,
+			Forward:  true,
+		})
+	}
+
+	if originalType, updateType := s.fieldType(original), s.fieldType(update); originalType != updateType {
+		// scalars can change between kinds that share a wire encoding the same as the compatibility check allows
+		compatible := s.fieldKind(original) == "scalar" && s.fieldKind(update) == "scalar" &&
+			s.scalarKindGroup(original.Kind()) == s.scalarKindGroup(update.Kind())
+		changes = append(changes, Change{
+			Kind:     ChangeTypeChanged,
+			Path:     path,
+			Previous: originalType,
+			Current:  updateType,
+			Backward: compatible,
+			Forward:  compatible,
+		})
+	}
+
+	if original.Cardinality() != update.Cardinality() {
+		change := Change{
+			Kind:       ChangeConstraintChanged,
+			Path:       path,
+			Constraint: "cardinality",
+			Previous:   original.Cardinality().String(),
+			Current:    update.Cardinality().String(),
+			Backward:   update.Cardinality() != protoreflect.Required,
+			Forward:    original.Cardinality() != protoreflect.Required,
+		}
+		if original.IsList() != update.IsList() {
+			// only numeric types can switch between repeated and singular on the wire
+			change.Backward = s.isPackableKind(original.Kind())
+			change.Forward = change.Backward
+		}
+		changes = append(changes, change)
+	}
+
+	originalDefault, updateDefault := "", ""
+	if original.HasDefault() {
+		originalDefault = fmt.Sprint(original.Default().Interface())
+	}
+	if update.HasDefault() {
+		updateDefault = fmt.Sprint(update.Default().Interface())
+	}
+	if originalDefault != updateDefault {
+		changes = append(changes, Change{
+			Kind:     ChangeDefaultChanged,
+			Path:     path,
+			Previous: originalDefault,
+			Current:  updateDefault,
+			Backward: true,
+			Forward:  true,
+		})
+	}
+
+	return changes
+}
@@ -0,0 +1,369 @@
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProtobufSchema(t *testing.T) {
+	// invalid schema
+	_, err := ParseSchema(`syntax = "proto3"; message One {`, SchemaTypeProtobuf, nil, nil)
+	assert.Error(t, err)
+
+	// well-known types don't need references
+	_, err = ParseSchema(`
+syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message One {
+  google.protobuf.Timestamp created_at = 1;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	// unknown imports aren't loaded from anywhere else
+	_, err = ParseSchema(`
+syntax = "proto3";
+import "other.proto";
+message One {
+  Other other = 1;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.Error(t, err)
+
+	// imports are resolved from references
+	_, err = ParseSchema(`
+syntax = "proto3";
+import "other.proto";
+message One {
+  Other other = 1;
+}
+`, SchemaTypeProtobuf, []string{`syntax = "proto3"; message Other { string name = 1; }`}, []string{"other.proto"})
+	assert.NoError(t, err)
+
+	// a reference can't replace the schema
+	_, err = ParseSchema(`
+syntax = "proto3";
+import "schema.proto";
+message One {
+  Other other = 1;
+}
+`, SchemaTypeProtobuf, []string{`syntax = "proto3"; message Other { string name = 1; }`}, []string{"schema.proto"})
+	assert.ErrorContains(t, err, "reserved")
+}
+
+func TestParsedProtobufSchemaIsBackwardsCompatible(t *testing.T) {
+	tests := []struct {
+		name           string
+		previousSchema string
+		schema         string
+		reason         string
+	}{
+		{
+			name:           "same",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string name = 1; }`,
+		},
+		{
+			name:           "field added",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string name = 1; int64 age = 2; }`,
+		},
+		{
+			name:           "field removed",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; int64 age = 2; }`,
+			schema:         `syntax = "proto3"; message One { string name = 1; }`,
+		},
+		{
+			name:           "field renamed",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string full_name = 1; }`,
+		},
+		{
+			name:           "message added",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string name = 1; } message Two { string name = 1; }`,
+		},
+		{
+			name:           "numeric label changed",
+			previousSchema: `syntax = "proto3"; message One { int32 count = 1; }`,
+			schema:         `syntax = "proto3"; message One { repeated int32 count = 1; }`,
+		},
+		{
+			name:           "varint scalar kind changed",
+			previousSchema: `syntax = "proto3"; message One { int32 count = 1; bool flag = 2; }`,
+			schema:         `syntax = "proto3"; message One { uint64 count = 1; int32 flag = 2; }`,
+		},
+		{
+			name:           "zigzag scalar kind changed",
+			previousSchema: `syntax = "proto3"; message One { sint32 count = 1; }`,
+			schema:         `syntax = "proto3"; message One { sint64 count = 1; }`,
+		},
+		{
+			name:           "fixed scalar kind changed",
+			previousSchema: `syntax = "proto3"; message One { fixed32 small = 1; sfixed64 big = 2; }`,
+			schema:         `syntax = "proto3"; message One { sfixed32 small = 1; fixed64 big = 2; }`,
+		},
+		{
+			name:           "string changed to bytes",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { bytes name = 1; }`,
+		},
+		{
+			name:           "single field moved to new oneof",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { oneof id { string name = 1; int64 number = 2; } }`,
+		},
+		{
+			name:           "package changed",
+			previousSchema: `syntax = "proto3"; package one; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; package two; message One { string name = 1; }`,
+			reason:         "package changed from one to two",
+		},
+		{
+			name:           "message removed",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; } message Two { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string name = 1; }`,
+			reason:         "Two: message removed",
+		},
+		{
+			name:           "field number changed",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { string name = 2; }`,
+			reason:         "One.name: field number changed from 1 to 2",
+		},
+		{
+			name:           "field scalar kind changed",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message One { int64 name = 1; }`,
+			reason:         "One.name: field scalar kind changed from string to int64",
+		},
+		{
+			name:           "varint changed to zigzag",
+			previousSchema: `syntax = "proto3"; message One { int32 count = 1; }`,
+			schema:         `syntax = "proto3"; message One { sint32 count = 1; }`,
+			reason:         "One.count: field scalar kind changed from int32 to sint32",
+		},
+		{
+			name:           "fixed32 changed to fixed64",
+			previousSchema: `syntax = "proto3"; message One { fixed32 count = 1; }`,
+			schema:         `syntax = "proto3"; message One { fixed64 count = 1; }`,
+			reason:         "One.count: field scalar kind changed from fixed32 to fixed64",
+		},
+		{
+			name:           "float changed to double",
+			previousSchema: `syntax = "proto3"; message One { float amount = 1; }`,
+			schema:         `syntax = "proto3"; message One { double amount = 1; }`,
+			reason:         "One.amount: field scalar kind changed from float to double",
+		},
+		{
+			name:           "field kind changed",
+			previousSchema: `syntax = "proto3"; message Two { string name = 1; } message One { string name = 1; }`,
+			schema:         `syntax = "proto3"; message Two { string name = 1; } message One { Two name = 1; }`,
+			reason:         "One.name: field kind changed from scalar to message",
+		},
+		{
+			name:           "field named type changed",
+			previousSchema: `syntax = "proto3"; message Two { string name = 1; } message Three { string name = 1; } message One { Two name = 1; }`,
+			schema:         `syntax = "proto3"; message Two { string name = 1; } message Three { string name = 1; } message One { Three name = 1; }`,
+			reason:         "One.name: field named type changed from Two to Three",
+		},
+		{
+			name:           "nested message field changed",
+			previousSchema: `syntax = "proto3"; message One { message Inner { string name = 1; } Inner inner = 1; }`,
+			schema:         `syntax = "proto3"; message One { message Inner { bool name = 1; } Inner inner = 1; }`,
+			reason:         "One.Inner.name: field scalar kind changed from string to bool",
+		},
+		{
+			name:           "oneof field removed",
+			previousSchema: `syntax = "proto3"; message One { oneof id { string name = 1; int64 number = 2; } }`,
+			schema:         `syntax = "proto3"; message One { oneof id { string name = 1; } }`,
+			reason:         "One.number: oneof field removed",
+		},
+		{
+			name:           "multiple fields moved to new oneof",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; int64 number = 2; }`,
+			schema:         `syntax = "proto3"; message One { oneof id { string name = 1; int64 number = 2; } }`,
+			reason:         "One.id: multiple fields moved to new oneof",
+		},
+		{
+			name:           "field moved to existing oneof",
+			previousSchema: `syntax = "proto3"; message One { string name = 1; oneof id { int64 number = 2; } }`,
+			schema:         `syntax = "proto3"; message One { oneof id { string name = 1; int64 number = 2; } }`,
+			reason:         "One.id: field moved to existing oneof",
+		},
+		{
+			name:           "required field added",
+			previousSchema: `syntax = "proto2"; message One { optional string name = 1; }`,
+			schema:         `syntax = "proto2"; message One { optional string name = 1; required int64 number = 2; }`,
+			reason:         "One.number: required field added",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previousSchema, err := ParseSchema(test.previousSchema, SchemaTypeProtobuf, nil, nil)
+			assert.NoError(t, err)
+			schema, err := ParseSchema(test.schema, SchemaTypeProtobuf, nil, nil)
+			assert.NoError(t, err)
+
+			compatible, reasons, err := schema.IsBackwardsCompatible(previousSchema)
+			assert.NoError(t, err)
+			if len(test.reason) == 0 {
+				assert.True(t, compatible)
+				assert.Empty(t, reasons)
+			} else {
+				assert.False(t, compatible)
+				assert.Contains(t, reasons, test.reason)
+			}
+		})
+	}
+}
+
+func TestParsedProtobufSchemaCanonicalString(t *testing.T) {
+	schema, err := ParseSchema(`
+syntax = "proto3";
+// comments are removed
+package one;
+
+message   One {
+  string name = 1; // so are trailing comments
+  int64   number = 2;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	canonical, err := schema.CanonicalString()
+	assert.NoError(t, err)
+	assert.Equal(t, `syntax = "proto3";
+package one;
+message One {
+  string name = 1;
+  int64 number = 2;
+}
+`, canonical)
+}
+
+func TestParsedProtobufSchemaNames(t *testing.T) {
+	schema, err := ParseSchema(`
+syntax = "proto3";
+package example;
+
+message Customer {
+  string customer_email = 1;
+  Address address = 2;
+
+  message Address {
+    string street = 1;
+  }
+}
+
+enum Status {
+  ACTIVE = 0;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	names := schema.Names()
+	assert.Equal(t, []string{
+		"example.Status",
+		"example.Customer",
+		"example.Customer.customer_email",
+		"example.Customer.address",
+		"example.Customer.Address",
+		"example.Customer.Address.street",
+	}, names)
+	assert.True(t, HasName(names, "customer_email"))
+	assert.True(t, HasName(names, "Customer.Address"))
+}
+
+func TestParsedProtobufSchemaDiff(t *testing.T) {
+	previousSchema, err := ParseSchema(`
+syntax = "proto3";
+package com.example;
+
+message Customer {
+  string name = 1;
+  int32 age = 2;
+  string phone = 3;
+  Status status = 4;
+  string email = 5;
+}
+
+enum Status {
+  ACTIVE = 0;
+  DISABLED = 1;
+}
+
+message Address {
+  string street = 1;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	schema, err := ParseSchema(`
+syntax = "proto3";
+package com.example;
+
+message Customer {
+  string full_name = 1;
+  int64 age = 2;
+  Status status = 4;
+  string email = 6;
+  repeated string tags = 7;
+}
+
+enum Status {
+  ACTIVE = 0;
+  BANNED = 2;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	changes, err := schema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeTypeRemoved, Path: "com.example.Address", Previous: "message", Backward: false, Forward: true},
+		{Kind: ChangeNameChanged, Path: "com.example.Customer.full_name", Previous: "name", Current: "full_name", Backward: true, Forward: true},
+		{Kind: ChangeTypeChanged, Path: "com.example.Customer.age", Previous: "int32", Current: "int64", Backward: true, Forward: true},
+		{Kind: ChangeFieldRemoved, Path: "com.example.Customer.phone", Previous: "string", Backward: true, Forward: true},
+		{Kind: ChangeFieldNumberChanged, Path: "com.example.Customer.email", Previous: "5", Current: "6"},
+		{Kind: ChangeFieldAdded, Path: "com.example.Customer.tags", Current: "string", Backward: true, Forward: true},
+		{Kind: ChangeEnumSymbolAdded, Path: "com.example.Status", Current: "BANNED", Backward: true, Forward: true},
+		{Kind: ChangeEnumSymbolRemoved, Path: "com.example.Status", Previous: "DISABLED", Backward: true, Forward: true},
+	}, changes)
+
+	// nothing changed
+	changes, err = schema.Diff(schema)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	// scalars with a different wire encoding can't be read either way
+	retypedSchema, err := ParseSchema(`
+syntax = "proto3";
+package com.example;
+
+message Customer {
+  string name = 1;
+  sint32 age = 2;
+  string phone = 3;
+  Status status = 4;
+  string email = 5;
+}
+
+enum Status {
+  ACTIVE = 0;
+  DISABLED = 1;
+}
+
+message Address {
+  string street = 1;
+}
+`, SchemaTypeProtobuf, nil, nil)
+	assert.NoError(t, err)
+
+	changes, err = retypedSchema.Diff(previousSchema)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeTypeChanged, Path: "com.example.Customer.age", Previous: "int32", Current: "sint32", Backward: false, Forward: false},
+	}, changes)
+}
@@ -1,10 +1,12 @@
 package schemas
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/bufbuild/protocompile"
 	"github.com/hamba/avro/v2"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
@@ -17,8 +19,37 @@ const (
 	SchemaTypeProtobuf SchemaType = "PROTOBUF"
 )
 
+// protobufSchemaFileName is the file name protobuf schemas are compiled as, references can't use it as their name
+const protobufSchemaFileName = "schema.proto"
+
 type ParsedSchema interface {
-	IsBackwardsCompatible(previousSchema ParsedSchema) (bool, error)
+	// IsBackwardsCompatible checks the compatibility of the schema against the previous schema
+	// along with human-readable reasons when it can't
+	IsBackwardsCompatible(previousSchema ParsedSchema) (bool, []string, error)
+
+	// CanonicalString returns the normalized form of the schema
+	// semantically identical schemas return the same canonical string
+	CanonicalString() (string, error)
+
+	// Names returns the names of the types and fields in the schema, fields are paths of the fields they're in
+	// joined by dots, i.e. customer.email
+	Names() []string
+
+	// Diff returns what changed from the previous schema, i.e. fields added or retyped and constraints tightened,
+	// along with whether each change is backward and forward compatible
+	Diff(previousSchema ParsedSchema) ([]Change, error)
+}
+
+// HasName returns if any of the names is the name or ends with it as a path, so customer_email matches the field
+// customer_email in any record and Customer matches the record com.acme.Customer
+func HasName(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name || strings.HasSuffix(candidate, "."+name) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func ParseSchema(rawSchema string, schemaType SchemaType, rawReferences []string, rawReferenceNames []string) (ParsedSchema, error) {
@@ -104,9 +135,40 @@ func ParseSchema(rawSchema string, schemaType SchemaType, rawReferences []string
 		//  maybe unique $id's?
 
 		parsedSchema = &ParsedJSONSchema{
+			rawSchema:  rawSchema,
 			jsonSchema: jsonSchema,
 		}
 
+		break
+	case SchemaTypeProtobuf:
+		sources := make(map[string]string)
+		for index, reference := range rawReferences {
+			// the schema itself is compiled from the same sources so a reference can't take its file name
+			if rawReferenceNames[index] == protobufSchemaFileName {
+				return nil, fmt.Errorf("protobuf schema reference name %s is reserved for the schema", protobufSchemaFileName)
+			}
+			sources[rawReferenceNames[index]] = reference
+		}
+
+		sources[protobufSchemaFileName] = rawSchema
+
+		// imports are only resolved from references and the well-known google types
+		// the same as json we never want to read imports from the filesystem or network
+		compiler := protocompile.Compiler{
+			Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(sources),
+			}),
+		}
+
+		files, err := compiler.Compile(context.Background(), protobufSchemaFileName)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling protobuf schema: %w", err)
+		}
+
+		parsedSchema = &ParsedProtobufSchema{
+			fileDescriptor: files[0],
+		}
+
 		break
 	default:
 		return nil, fmt.Errorf("unknown schema type: %s", schemaType)